@@ -0,0 +1,725 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+const (
+	// requestTimeout defines the maximum time a request to the REST API may take.
+	requestTimeout = 10 * time.Second
+
+	// minBackoff defines the time waited before the first attempt to send the queued scores.
+	minBackoff = time.Second
+
+	// maxBackoff defines the maximum time waited between the attempts to send the queued scores.
+	maxBackoff = 5 * time.Minute
+)
+
+// ErrUnavailable is returned when the REST API cannot be reached or fails to handle
+// the request because of a server error.
+var ErrUnavailable = errors.New("api: server unavailable")
+
+// errUnsupported is returned by the store operations the REST API does not expose.
+var errUnsupported = errors.New("api: operation not supported by the server")
+
+// StatusError is returned when the REST API responds with a status code other than
+// 200 OK. It wraps the datastore error matching the status code if any.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code and the message returned by the REST API.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("api: %d %s :: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap returns the error matching the status code so that it can be checked using errors.Is.
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return db.ErrNotFound
+
+	case e.StatusCode == http.StatusConflict:
+		return db.ErrDuplicate
+
+	case e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrRejected
+
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrUnavailable
+	}
+
+	return nil
+}
+
+// queuedScore defines the high scores that could not be sent because the REST API
+// was unavailable. The queued level attempts also hold their outcome and details, their
+// scores are held by HighScores.
+type queuedScore struct {
+	TapooID    string        `json:"id"`
+	Email      string        `json:"email,omitempty"`
+	Level      int           `json:"level"`
+	HighScores int           `json:"high_scores"`
+	Seed       int64         `json:"seed,omitempty"`
+	Outcome    string        `json:"outcome,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+	Moves      int           `json:"moves,omitempty"`
+	Replay     *db.Replay    `json:"replay,omitempty"`
+}
+
+// isAttempt checks if the queued item is a level attempt instead of high scores only.
+func (q queuedScore) isAttempt() bool {
+	return q.Outcome != ""
+}
+
+// Client implements db.Store using the tapoo REST API served by Server. The high scores
+// that cannot be sent because the server is unavailable are queued in a local file and
+// sent in the background once it is reachable again.
+type Client struct {
+	baseURL   string
+	client    *http.Client
+	queuePath string
+
+	// ctx is cancelled once the client is closed to stop the background sync.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// syncMu ensures only one sync of the queued scores runs at a time.
+	syncMu sync.Mutex
+
+	// mu protects the fields below.
+	mu         sync.Mutex
+	queue      []queuedScore
+	backoff    time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	retry      *time.Timer
+	closed     bool
+	wg         sync.WaitGroup
+}
+
+// NewClient creates a store that uses the REST API at the base URL provided. The high
+// scores that cannot be sent are queued in the file at queuePath. Scores queued by a
+// previous client are sent in the background once the server is reachable.
+func NewClient(baseURL, queuePath string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, &db.ErrInvalidInput{Field: "API URL", Value: baseURL}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		client:     &http.Client{Timeout: requestTimeout},
+		queuePath:  queuePath,
+		ctx:        ctx,
+		cancel:     cancel,
+		backoff:    minBackoff,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+	}
+
+	if c.queue, err = readQueue(queuePath); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.scheduleSync()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+// GetOrCreateUser creates the new user with tapoo ID provided if it does not exists.
+func (c *Client) GetOrCreateUser(ctx context.Context, u *db.UserInfor) (*db.UserInfoResponse, error) {
+	var data db.UserInfoResponse
+	if err := c.do(ctx, http.MethodPost, "/users", &UserRequest{TapooID: u.TapooID, Email: u.Email}, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetUser fetches the user with the tapoo ID provided.
+func (c *Client) GetUser(ctx context.Context, u *db.UserInfor) (*db.UserInfoResponse, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var data db.UserInfoResponse
+	if err = c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// UpdateUser updates the user email.
+func (c *Client) UpdateUser(ctx context.Context, u *db.UserInfor) error {
+	path, err := getUserPath(u)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPut, path, &UserRequest{Email: u.Email}, &db.UserInfoResponse{})
+}
+
+// GetOrCreateLevelScore fetches or creates the user level scores.
+func (c *Client) GetOrCreateLevelScore(ctx context.Context, u *db.UserInfor) (*db.LevelScoreResponse, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var data db.LevelScoreResponse
+	if err = c.do(ctx, http.MethodPost, path+"/levels/"+strconv.Itoa(u.Level), nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// UpdateLevelScore is not supported since the REST API only replaces the stored high
+// scores with higher ones. SaveHighScores should be used instead.
+func (c *Client) UpdateLevelScore(ctx context.Context, u *db.UserInfor, highScores int) error {
+	return errUnsupported
+}
+
+// SaveHighScores sends the high scores to the REST API. If the server is unavailable
+// the high scores are queued and an error wrapping ErrUnavailable is returned.
+func (c *Client) SaveHighScores(ctx context.Context, u *db.UserInfor, highScores int) (*db.LevelScoreResponse, error) {
+	data, err := c.postScores(ctx, u, highScores)
+	if !errors.Is(err, ErrUnavailable) {
+		return data, err
+	}
+
+	if qErr := c.enqueue(queuedScore{TapooID: u.TapooID, Email: u.Email, Level: u.Level,
+		HighScores: highScores}); qErr != nil {
+		return nil, qErr
+	}
+
+	return nil, fmt.Errorf("%w :: the scores are queued until the server is reachable", err)
+}
+
+// SaveAttempt sends the level attempt to the REST API. If the server is unavailable the
+// attempt is queued and an error wrapping ErrUnavailable is returned.
+func (c *Client) SaveAttempt(ctx context.Context, a *db.Attempt) (*db.LevelScoreResponse, error) {
+	data, err := c.postAttempt(ctx, a)
+	if !errors.Is(err, ErrUnavailable) {
+		return data, err
+	}
+
+	if qErr := c.enqueue(queuedScore{TapooID: a.TapooID, Level: a.Level, HighScores: a.Scores, Seed: a.Seed,
+		Outcome: a.Outcome, Duration: a.Duration, Moves: a.Moves, Replay: a.Replay}); qErr != nil {
+		return nil, qErr
+	}
+
+	return nil, fmt.Errorf("%w :: the attempt is queued until the server is reachable", err)
+}
+
+// GetAttempts fetches the page of the user attempts history requested, the latest first.
+func (c *Client) GetAttempts(ctx context.Context, q *db.AttemptsQuery) ([]*db.Attempt, error) {
+	path, err := getUserPath(&db.UserInfor{TapooID: q.TapooID})
+	if err != nil {
+		return nil, err
+	}
+
+	path = fmt.Sprintf("%s/attempts?limit=%d&offset=%d", path, q.Limit, q.Offset)
+	if q.Level != db.AllLevels {
+		path += "&level=" + strconv.Itoa(q.Level)
+	}
+
+	var data []*db.Attempt
+	if err = c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetBestAttempt fetches the best replayed attempt of the level provided. The attempts
+// of every user are considered if the tapoo ID is empty.
+func (c *Client) GetBestAttempt(ctx context.Context, u *db.UserInfor) (*db.Attempt, error) {
+	path := "/leaderboards/" + strconv.Itoa(u.Level) + "/best"
+
+	if len(u.TapooID) > 0 {
+		userPath, err := getUserPath(u)
+		if err != nil {
+			return nil, err
+		}
+
+		path = userPath + "/levels/" + strconv.Itoa(u.Level) + "/best"
+	}
+
+	var data db.Attempt
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetStats fetches the summary of the levels played by the user.
+func (c *Client) GetStats(ctx context.Context, u *db.UserInfor) (*db.Stats, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var data db.Stats
+	if err = c.do(ctx, http.MethodGet, path+"/stats", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetHighestClearedLevel fetches the highest level the user has completed successfully.
+func (c *Client) GetHighestClearedLevel(ctx context.Context, u *db.UserInfor) (int, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return -1, err
+	}
+
+	var data ClearedLevelResponse
+	if err = c.do(ctx, http.MethodGet, path+"/levels", nil, &data); err != nil {
+		return -1, err
+	}
+
+	return data.Level, nil
+}
+
+// GetTopScores fetches the top limit high scores for the provided level. The emails
+// and the creation times are not returned by the REST API.
+func (c *Client) GetTopScores(ctx context.Context, u *db.UserInfor, limit int) ([]*db.LevelScoreResponse, error) {
+	entries, err := c.GetLeaderboard(ctx, &db.LeaderboardQuery{Level: u.Level, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	topScores := make([]*db.LevelScoreResponse, 0, len(entries))
+
+	for _, entry := range entries {
+		topScores = append(topScores, &db.LevelScoreResponse{TapooID: entry.TapooID, Level: entry.Level,
+			HighScores: entry.HighScores, UpdateAt: entry.UpdateAt})
+	}
+
+	return topScores, nil
+}
+
+// GetLeaderboard fetches the leaderboard page requested.
+func (c *Client) GetLeaderboard(ctx context.Context, q *db.LeaderboardQuery) ([]*db.LeaderboardEntry, error) {
+	path := fmt.Sprintf("/leaderboards/%s?limit=%d&offset=%d%s", getLeaderboardPath(q.Level),
+		q.Limit, q.Offset, getPeriodParams(q))
+
+	var data []*db.LeaderboardEntry
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetUserRank fetches the entry of the user on the leaderboard together with at most
+// neighbors entries ranked above and below it.
+func (c *Client) GetUserRank(ctx context.Context, u *db.UserInfor, q *db.LeaderboardQuery,
+	neighbors int) ([]*db.LeaderboardEntry, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	path = fmt.Sprintf("/leaderboards/%s%s?neighbors=%d%s", getLeaderboardPath(q.Level), path,
+		neighbors, getPeriodParams(q))
+
+	var data []*db.LeaderboardEntry
+	if err = c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// CreateSeason is not supported since the seasons are only created by the server
+// administrators using the tapoo season command.
+func (c *Client) CreateSeason(ctx context.Context, s *db.Season) error {
+	return errUnsupported
+}
+
+// GetSeason fetches the season with the name provided.
+func (c *Client) GetSeason(ctx context.Context, name string) (*db.Season, error) {
+	if len(name) == 0 {
+		return nil, &db.ErrInvalidInput{Field: "season name", Value: "(empty)"}
+	}
+
+	var data db.Season
+	if err := c.do(ctx, http.MethodGet, "/seasons/"+url.PathEscape(name), nil, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// GetSeasons fetches all the seasons ordered by their start.
+func (c *Client) GetSeasons(ctx context.Context) ([]*db.Season, error) {
+	var data []*db.Season
+	if err := c.do(ctx, http.MethodGet, "/seasons", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Close stops the background sync of the queued scores. The scores still queued are
+// kept in the queue file.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+
+	if c.retry != nil && c.retry.Stop() {
+		c.retry = nil
+		c.wg.Done()
+	}
+
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+
+	return nil
+}
+
+// Queued returns the number of high scores waiting to be sent.
+func (c *Client) Queued() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.queue)
+}
+
+// Sync sends the queued high scores in the order they were queued. It stops at the
+// first one that cannot be sent because the server is unavailable. The queued high
+// scores rejected by the server are dropped since sending them again cannot succeed.
+func (c *Client) Sync(ctx context.Context) error {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.Lock()
+	pending := append([]queuedScore(nil), c.queue...)
+	c.mu.Unlock()
+
+	for _, item := range pending {
+		err := c.submit(ctx, item)
+		if errors.Is(err, ErrUnavailable) {
+			return err
+		}
+
+		if err != nil {
+			log.Printf("api: dropping the queued level %d scores of %s :: %v", item.Level, item.TapooID, err)
+		}
+
+		if err = c.dequeue(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// submit creates the user of the queued high scores if missing and sends the high scores
+// or the level attempt queued.
+func (c *Client) submit(ctx context.Context, item queuedScore) error {
+	u := &db.UserInfor{TapooID: item.TapooID, Email: item.Email, Level: item.Level}
+
+	if _, err := c.GetOrCreateUser(ctx, u); err != nil {
+		return err
+	}
+
+	var err error
+	if item.isAttempt() {
+		_, err = c.postAttempt(ctx, &db.Attempt{TapooID: item.TapooID, Level: item.Level, Seed: item.Seed,
+			Outcome: item.Outcome, Duration: item.Duration, Moves: item.Moves, Scores: item.HighScores,
+			Replay: item.Replay})
+	} else {
+		_, err = c.postScores(ctx, u, item.HighScores)
+	}
+
+	return err
+}
+
+// postScores sends the user level high scores to the REST API.
+func (c *Client) postScores(ctx context.Context, u *db.UserInfor, highScores int) (*db.LevelScoreResponse, error) {
+	path, err := getUserPath(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var data db.LevelScoreResponse
+	if err = c.do(ctx, http.MethodPost, fmt.Sprintf("%s/levels/%d/scores", path, u.Level),
+		&ScoreRequest{HighScores: highScores}, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// postAttempt sends the user level attempt to the REST API.
+func (c *Client) postAttempt(ctx context.Context, a *db.Attempt) (*db.LevelScoreResponse, error) {
+	path, err := getUserPath(&db.UserInfor{TapooID: a.TapooID})
+	if err != nil {
+		return nil, err
+	}
+
+	var data db.LevelScoreResponse
+	if err = c.do(ctx, http.MethodPost, fmt.Sprintf("%s/levels/%d/attempts", path, a.Level),
+		&AttemptRequest{Seed: a.Seed, Outcome: a.Outcome, Duration: a.Duration, Moves: a.Moves,
+			Scores: a.Scores, Replay: a.Replay}, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// do sends the request body provided as JSON and decodes the response body into v. The
+// errors preventing the request from reaching the server wrap ErrUnavailable.
+func (c *Client) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w :: %v", ErrUnavailable, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusInternalServerError {
+		c.setReachable()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure ErrorResponse
+
+		// the message is optional since the response may not come from the tapoo server.
+		json.NewDecoder(resp.Body).Decode(&failure)
+
+		return &StatusError{StatusCode: resp.StatusCode, Message: failure.Error}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// enqueue adds the high scores or the level attempt to the queue and saves it. The queued
+// high scores of the same user level are merged keeping the higher ones while every level
+// attempt is kept.
+func (c *Client) enqueue(item queuedScore) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := false
+
+	for i, queued := range c.queue {
+		if !item.isAttempt() && !queued.isAttempt() && queued.TapooID == item.TapooID &&
+			queued.Level == item.Level {
+			if item.HighScores > queued.HighScores {
+				c.queue[i].HighScores = item.HighScores
+			}
+
+			merged = true
+			break
+		}
+	}
+
+	if !merged {
+		c.queue = append(c.queue, item)
+	}
+
+	if err := writeQueue(c.queuePath, c.queue); err != nil {
+		return err
+	}
+
+	c.scheduleSync()
+
+	return nil
+}
+
+// dequeue removes the high scores sent from the queue and saves it. Higher scores of
+// the same user level queued while sending are kept.
+func (c *Client) dequeue(item queuedScore) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, queued := range c.queue {
+		if queued == item {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			break
+		}
+	}
+
+	return writeQueue(c.queuePath, c.queue)
+}
+
+// setReachable resets the backoff once the server responds and schedules the sync of
+// the queued high scores if any.
+func (c *Client) setReachable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.backoff = c.minBackoff
+	c.scheduleSync()
+}
+
+// scheduleSync schedules the sync of the queued high scores after the current backoff
+// unless the queue is empty or a sync is already scheduled. c.mu must be held.
+func (c *Client) scheduleSync() {
+	if c.closed || c.retry != nil || len(c.queue) == 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	c.retry = time.AfterFunc(c.backoff, c.retrySync)
+}
+
+// retrySync syncs the queued high scores. If the sync fails the backoff is doubled up
+// to the maximum backoff before the next sync is scheduled.
+func (c *Client) retrySync() {
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithTimeout(c.ctx, requestTimeout)
+	defer cancel()
+
+	err := c.Sync(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.retry = nil
+
+	if err != nil {
+		if c.backoff *= 2; c.backoff > c.maxBackoff {
+			c.backoff = c.maxBackoff
+		}
+	}
+
+	c.scheduleSync()
+}
+
+// getUserPath returns the REST API path of the user provided.
+func getUserPath(u *db.UserInfor) (string, error) {
+	if len(u.TapooID) == 0 {
+		return "", &db.ErrInvalidInput{Field: "Tapoo ID", Value: "(empty)"}
+	}
+
+	return "/users/" + url.PathEscape(u.TapooID), nil
+}
+
+// getLeaderboardPath returns the REST API path segment of the leaderboard level provided.
+func getLeaderboardPath(level int) string {
+	if level == db.GlobalLeaderboard {
+		return globalLevel
+	}
+
+	return strconv.Itoa(level)
+}
+
+// getPeriodParams returns the query parameters that limit the leaderboard to its period.
+func getPeriodParams(q *db.LeaderboardQuery) string {
+	params := url.Values{}
+
+	if !q.From.IsZero() {
+		params.Set("from", q.From.Format(time.RFC3339))
+	}
+
+	if !q.To.IsZero() {
+		params.Set("to", q.To.Format(time.RFC3339))
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+
+	return "&" + params.Encode()
+}
+
+// readQueue reads the queued high scores from the file provided. A missing file holds
+// no queued high scores.
+func readQueue(path string) ([]queuedScore, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var queue []queuedScore
+	if err = json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("api: invalid queue file %s :: %v", path, err)
+	}
+
+	return queue, nil
+}
+
+// writeQueue replaces the file provided with the queued high scores. The file is removed
+// once the queue is empty.
+func writeQueue(path string, queue []queuedScore) error {
+	if len(queue) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// the queue is written to a temporary file first so that a crash cannot leave a
+	// partially written queue file.
+	tmpPath := path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
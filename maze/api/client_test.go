@@ -0,0 +1,386 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// getQueuePath returns the path of a queue file in a new temporary directory
+// that is deleted using the function returned.
+func getQueuePath() (string, func()) {
+	dir, err := ioutil.TempDir("", "tapoo_api")
+	if err != nil {
+		panic(err)
+	}
+
+	return filepath.Join(dir, "queue.json"), func() { os.RemoveAll(dir) }
+}
+
+// TestNewClient tests the functionality of NewClient
+func TestNewClient(t *testing.T) {
+	Convey("TestNewClient: Given the REST API base URL and the queue file path", t, func() {
+		queuePath, cleanUp := getQueuePath()
+		defer cleanUp()
+
+		Convey("an invalid base URL should return an error", func() {
+			for _, baseURL := range []string{"", "localhost:8080", "ftp://localhost"} {
+				client, err := NewClient(baseURL, queuePath)
+
+				So(client, ShouldBeNil)
+				So(err, ShouldHaveSameTypeAs, &db.ErrInvalidInput{})
+			}
+		})
+
+		Convey("an invalid queue file should return an error", func() {
+			So(ioutil.WriteFile(queuePath, []byte("{"), 0600), ShouldBeNil)
+
+			client, err := NewClient("http://localhost:8080", queuePath)
+
+			So(client, ShouldBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid queue file")
+		})
+
+		Convey("a valid base URL should return the client with the scores queued previously", func() {
+			So(writeQueue(queuePath, []queuedScore{{TapooID: "Vf2TqN5MB", Level: 2, HighScores: 10}}), ShouldBeNil)
+
+			client, err := NewClient("http://localhost:8080/", queuePath)
+			So(err, ShouldBeNil)
+
+			defer client.Close()
+
+			So(client.baseURL, ShouldEqual, "http://localhost:8080")
+			So(client.Queued(), ShouldEqual, 1)
+		})
+	})
+}
+
+// TestClient tests the functionality of Client
+func TestClient(t *testing.T) {
+	Convey("TestClient: Given the client of the REST API served using an in-memory store", t, func() {
+		store := db.NewMemoryStore()
+		defer store.Close()
+
+		ts := httptest.NewServer(NewServer(store, nil))
+		defer ts.Close()
+
+		queuePath, cleanUp := getQueuePath()
+		defer cleanUp()
+
+		client, err := NewClient(ts.URL, queuePath)
+		So(err, ShouldBeNil)
+
+		defer client.Close()
+
+		ctx := context.Background()
+		user := &db.UserInfor{TapooID: "Vf2TqN5MB", Email: "sgravell1@europa.eu", Level: 2}
+
+		data, err := client.GetOrCreateUser(ctx, user)
+
+		So(err, ShouldBeNil)
+		So(data.TapooID, ShouldEqual, user.TapooID)
+		So(data.Email, ShouldEqual, user.Email)
+
+		Convey("the users should be fetched and updated", func() {
+			So(client.UpdateUser(ctx, &db.UserInfor{TapooID: user.TapooID, Email: "test@naihub.com"}), ShouldBeNil)
+
+			data, err := client.GetUser(ctx, user)
+
+			So(err, ShouldBeNil)
+			So(data.Email, ShouldEqual, "test@naihub.com")
+
+			_, err = client.GetUser(ctx, &db.UserInfor{TapooID: "fake_tapoo_id"})
+
+			So(errors.Is(err, db.ErrNotFound), ShouldBeTrue)
+
+			var statusErr *StatusError
+			So(errors.As(err, &statusErr), ShouldBeTrue)
+			So(statusErr.StatusCode, ShouldEqual, http.StatusNotFound)
+
+			_, err = client.GetUser(ctx, &db.UserInfor{})
+
+			So(err, ShouldHaveSameTypeAs, &db.ErrInvalidInput{})
+		})
+
+		Convey("the level scores, the highest cleared level and the leaderboard should be fetched", func() {
+			score, err := client.GetOrCreateLevelScore(ctx, user)
+
+			So(err, ShouldBeNil)
+			So(score.Level, ShouldEqual, 2)
+			So(score.HighScores, ShouldEqual, 0)
+
+			score, err = client.SaveHighScores(ctx, user, 1653)
+
+			So(err, ShouldBeNil)
+			So(score.HighScores, ShouldEqual, 1653)
+
+			level, err := client.GetHighestClearedLevel(ctx, user)
+
+			So(err, ShouldBeNil)
+			So(level, ShouldEqual, 2)
+
+			scores, err := client.GetTopScores(ctx, user, 5)
+
+			So(err, ShouldBeNil)
+			So(scores, ShouldHaveLength, 1)
+			So(scores[0].TapooID, ShouldEqual, user.TapooID)
+
+			_, err = client.GetTopScores(ctx, user, 0)
+
+			So(err.Error(), ShouldContainSubstring, "invalid limit found : '0'")
+
+			entries, err := client.GetLeaderboard(ctx, &db.LeaderboardQuery{Level: db.GlobalLeaderboard, Limit: 5})
+
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].Rank, ShouldEqual, 1)
+			So(entries[0].HighScores, ShouldEqual, 1653)
+
+			entries, err = client.GetUserRank(ctx, user, &db.LeaderboardQuery{Level: 2}, 3)
+
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].TapooID, ShouldEqual, user.TapooID)
+
+			_, err = client.GetUserRank(ctx, user, &db.LeaderboardQuery{Level: 5}, 3)
+
+			So(errors.Is(err, db.ErrNotFound), ShouldBeTrue)
+
+			q := &db.LeaderboardQuery{Level: 2, Limit: 5}
+			q.SetSeason(&db.Season{StartsAt: time.Now().AddDate(0, 0, -2), EndsAt: time.Now().AddDate(0, 0, -1)})
+
+			entries, err = client.GetLeaderboard(ctx, q)
+
+			So(err, ShouldBeNil)
+			So(entries, ShouldBeEmpty)
+
+			So(q.SetPeriod(db.Daily, time.Now()), ShouldBeNil)
+
+			entries, err = client.GetUserRank(ctx, user, q, 0)
+
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 1)
+			So(entries[0].HighScores, ShouldEqual, 1653)
+		})
+
+		Convey("the level attempts should be recorded and summarized", func() {
+			score, err := client.SaveAttempt(ctx, &db.Attempt{TapooID: user.TapooID, Level: 4, Seed: 2018,
+				Outcome: db.Quit, Duration: 30 * time.Second, Moves: 18, Scores: 900})
+
+			So(err, ShouldBeNil)
+			So(score.HighScores, ShouldEqual, 0)
+
+			attempts, err := client.GetAttempts(ctx, &db.AttemptsQuery{TapooID: user.TapooID, Level: db.AllLevels, Limit: 5})
+
+			So(err, ShouldBeNil)
+			So(attempts, ShouldHaveLength, 1)
+			So(attempts[0].Outcome, ShouldEqual, db.Quit)
+			So(attempts[0].Moves, ShouldEqual, 18)
+
+			attempts, err = client.GetAttempts(ctx, &db.AttemptsQuery{TapooID: user.TapooID, Level: 3, Limit: 5})
+
+			So(err, ShouldBeNil)
+			So(attempts, ShouldBeEmpty)
+
+			stats, err := client.GetStats(ctx, user)
+
+			So(err, ShouldBeNil)
+			So(stats.Attempts, ShouldEqual, 1)
+			So(stats.Levels[0].Quit, ShouldEqual, 1)
+
+			_, err = client.GetStats(ctx, &db.UserInfor{TapooID: "fake_tapoo_id"})
+
+			So(errors.Is(err, db.ErrNotFound), ShouldBeTrue)
+		})
+
+		Convey("the best replayed attempts should be fetched", func() {
+			replay := &db.Replay{Length: 40, Width: 20, Events: []db.ReplayEvent{{Time: 400, Kind: "move",
+				Direction: "UP"}, {Time: 400, Kind: "succeeded"}}}
+
+			_, err := client.SaveAttempt(ctx, &db.Attempt{TapooID: user.TapooID, Level: 4, Seed: 2018,
+				Outcome: db.Succeeded, Duration: 400 * time.Millisecond, Moves: 1, Scores: 900, Replay: replay})
+			So(err, ShouldBeNil)
+
+			for _, u := range []*db.UserInfor{{TapooID: user.TapooID, Level: 4}, {Level: 4}} {
+				attempt, err := client.GetBestAttempt(ctx, u)
+
+				So(err, ShouldBeNil)
+				So(attempt.TapooID, ShouldEqual, user.TapooID)
+				So(attempt.Seed, ShouldEqual, 2018)
+				So(attempt.Replay, ShouldResemble, replay)
+			}
+
+			_, err = client.GetBestAttempt(ctx, &db.UserInfor{Level: 5})
+
+			So(errors.Is(err, db.ErrNotFound), ShouldBeTrue)
+		})
+
+		Convey("the seasons should be fetched but not created", func() {
+			start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+			So(store.CreateSeason(ctx, &db.Season{Name: "Spring", StartsAt: start, EndsAt: start.AddDate(0, 3, 0)}), ShouldBeNil)
+
+			season, err := client.GetSeason(ctx, "Spring")
+
+			So(err, ShouldBeNil)
+			So(season.StartsAt.Equal(start), ShouldBeTrue)
+
+			_, err = client.GetSeason(ctx, "Winter")
+
+			So(errors.Is(err, db.ErrNotFound), ShouldBeTrue)
+
+			seasons, err := client.GetSeasons(ctx)
+
+			So(err, ShouldBeNil)
+			So(seasons, ShouldHaveLength, 1)
+
+			So(client.CreateSeason(ctx, &db.Season{Name: "Summer"}), ShouldEqual, errUnsupported)
+		})
+
+		Convey("updating the level scores directly should not be supported", func() {
+			So(client.UpdateLevelScore(ctx, user, 100), ShouldEqual, errUnsupported)
+		})
+
+		Convey("the scores saved while the server is unavailable should be queued", func() {
+			ts.Close()
+
+			_, err := client.SaveHighScores(ctx, user, 1200)
+
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "the scores are queued")
+
+			_, err = client.SaveHighScores(ctx, user, 1500)
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+
+			_, err = client.SaveHighScores(ctx, user, 900)
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+
+			_, err = client.SaveHighScores(ctx, &db.UserInfor{TapooID: "GzlWAL0mP", Level: 1}, 300)
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+
+			replay := &db.Replay{Length: 40, Width: 20, Events: []db.ReplayEvent{{Time: 600, Kind: "move", Direction: "UP"}}}
+
+			_, err = client.SaveAttempt(ctx, &db.Attempt{TapooID: user.TapooID, Level: 2, Outcome: db.Failed,
+				Duration: time.Minute, Moves: 30, Replay: replay})
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+
+			So(client.Queued(), ShouldEqual, 3)
+
+			queue, err := readQueue(queuePath)
+
+			So(err, ShouldBeNil)
+			So(queue, ShouldResemble, []queuedScore{
+				{TapooID: user.TapooID, Email: user.Email, Level: 2, HighScores: 1500},
+				{TapooID: "GzlWAL0mP", Level: 1, HighScores: 300},
+				{TapooID: user.TapooID, Level: 2, Outcome: db.Failed, Duration: time.Minute, Moves: 30, Replay: replay},
+			})
+
+			So(client.Sync(ctx), ShouldNotBeNil)
+			So(client.Queued(), ShouldEqual, 3)
+
+			Convey("and sent by a new client once the server is reachable", func() {
+				ts := httptest.NewServer(NewServer(store, nil))
+				defer ts.Close()
+
+				client, err := NewClient(ts.URL, queuePath)
+				So(err, ShouldBeNil)
+
+				defer client.Close()
+
+				So(client.Queued(), ShouldEqual, 3)
+				So(client.Sync(ctx), ShouldBeNil)
+				So(client.Queued(), ShouldEqual, 0)
+
+				_, err = os.Stat(queuePath)
+				So(os.IsNotExist(err), ShouldBeTrue)
+
+				score, err := store.GetOrCreateLevelScore(ctx, user)
+
+				So(err, ShouldBeNil)
+				So(score.HighScores, ShouldEqual, 1500)
+
+				score, err = store.GetOrCreateLevelScore(ctx, &db.UserInfor{TapooID: "GzlWAL0mP", Level: 1})
+
+				So(err, ShouldBeNil)
+				So(score.HighScores, ShouldEqual, 300)
+
+				attempts, err := store.GetAttempts(ctx, &db.AttemptsQuery{TapooID: user.TapooID, Level: 2, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(attempts, ShouldHaveLength, 2)
+				So(attempts[0].Outcome, ShouldEqual, db.Failed)
+			})
+		})
+	})
+}
+
+// TestClientRetrySync tests the functionality of the queued scores sync retried in the background
+func TestClientRetrySync(t *testing.T) {
+	Convey("TestClientRetrySync: Given a server that is unavailable for the first requests", t, func() {
+		store := db.NewMemoryStore()
+		defer store.Close()
+
+		var (
+			handler  = NewServer(store, nil)
+			failures int32
+		)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				writeError(w, http.StatusServiceUnavailable, errors.New("server is restarting"))
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}))
+		defer ts.Close()
+
+		queuePath, cleanUp := getQueuePath()
+		defer cleanUp()
+
+		client, err := NewClient(ts.URL, queuePath)
+		So(err, ShouldBeNil)
+
+		defer client.Close()
+
+		client.mu.Lock()
+		client.backoff, client.minBackoff = 10*time.Millisecond, 10*time.Millisecond
+		client.mu.Unlock()
+
+		atomic.StoreInt32(&failures, 3)
+
+		user := &db.UserInfor{TapooID: "Vf2TqN5MB", Level: 3}
+
+		Convey("the scores queued should be sent once the server is available", func() {
+			_, err := client.SaveHighScores(context.Background(), user, 840)
+
+			So(errors.Is(err, ErrUnavailable), ShouldBeTrue)
+			So(client.Queued(), ShouldEqual, 1)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for client.Queued() > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			So(client.Queued(), ShouldEqual, 0)
+
+			score, err := store.GetOrCreateLevelScore(context.Background(), user)
+
+			So(err, ShouldBeNil)
+			So(score.HighScores, ShouldEqual, 840)
+
+			client.mu.Lock()
+			So(client.backoff, ShouldEqual, client.minBackoff)
+			client.mu.Unlock()
+		})
+	})
+}
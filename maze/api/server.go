@@ -0,0 +1,669 @@
+// Package api exposes the tapoo users, their level scores and the level leaderboards
+// stored by the db package over an HTTP REST API.
+//
+// Authentication is out of the scope of this package. The Tapoo ID in the request path is
+// the only identity of the caller, so anyone that knows a Tapoo ID can update that user
+// and submit its scores and attempts. The API should only be served to trusted players or
+// behind a proxy that authenticates them.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+const (
+	// defaultLimit defines the number of high scores returned by a leaderboard
+	// if the limit query parameter is not set.
+	defaultLimit = 5
+
+	// maxLimit defines the maximum number of high scores returned by a leaderboard.
+	maxLimit = 100
+
+	// defaultNeighbors defines the number of entries returned above and below the user
+	// rank if the neighbors query parameter is not set.
+	defaultNeighbors = 2
+
+	// maxNeighbors defines the maximum number of entries returned above and below the user rank.
+	maxNeighbors = 50
+
+	// globalLevel defines the leaderboard level used for the global leaderboard.
+	globalLevel = "global"
+
+	// maxBodySize defines the maximum size in bytes of a request body.
+	maxBodySize = 1 << 20
+)
+
+// ErrRejected is returned when the level attempt submitted does not match its replay.
+var ErrRejected = errors.New("api: attempt rejected")
+
+// Verifier checks that the level attempt provided matches how its replay was played.
+// An error describing the mismatch is returned otherwise.
+type Verifier func(a *db.Attempt) error
+
+// UserRequest defines the body used to create or update a user.
+type UserRequest struct {
+	TapooID string `json:"id"`
+	Email   string `json:"email"`
+}
+
+// ScoreRequest defines the body used to submit the scores of a level.
+type ScoreRequest struct {
+	HighScores int `json:"high_scores"`
+}
+
+// AttemptRequest defines the body used to record a level attempt. Duration holds the
+// time played in nanoseconds while Replay holds how the level was played.
+type AttemptRequest struct {
+	Seed     int64         `json:"seed"`
+	Outcome  string        `json:"outcome"`
+	Duration time.Duration `json:"duration"`
+	Moves    int           `json:"moves"`
+	Scores   int           `json:"scores"`
+	Replay   *db.Replay    `json:"replay,omitempty"`
+}
+
+// ClearedLevelResponse defines the body returned with the highest level cleared by a user.
+type ClearedLevelResponse struct {
+	Level int `json:"highest_cleared_level"`
+}
+
+// ErrorResponse defines the body returned when a request fails.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server handles the following requests using the store provided:
+//
+//	POST /users                                   get or create a user
+//	GET  /users/{id}                              get a user
+//	PUT  /users/{id}                              update a user email
+//	GET  /users/{id}/levels                       get the highest level cleared by a user
+//	POST /users/{id}/levels/{level}               get or create the user level scores
+//	POST /users/{id}/levels/{level}/scores        submit the user level scores
+//	POST /users/{id}/levels/{level}/attempts      record a user level attempt
+//	GET  /users/{id}/levels/{level}/best          get the best replayed attempt of a user level
+//	GET  /users/{id}/attempts?level=&limit=&offset=
+//	                                              get a page of the user attempts, the latest first
+//	GET  /users/{id}/stats                        get the summary of the levels played by a user
+//	GET  /leaderboards/{level}?limit=&offset=     get a page of the level leaderboard
+//	GET  /leaderboards/{level}/users/{id}?neighbors=
+//	                                              get the user rank and the entries around it
+//	GET  /leaderboards/{level}/best               get the best replayed attempt of a level
+//	GET  /seasons                                 get all the seasons
+//	GET  /seasons/{name}                          get a season
+//
+// The level "global" refers to the leaderboard aggregating the high scores of every level.
+// The leaderboards are limited to a period using either the period query parameter (daily,
+// weekly or all-time), the season query parameter or the from and to query parameters
+// holding RFC 3339 times.
+//
+// If a verifier is set, every level attempt should be submitted with its replay and the
+// attempts that do not match their replays are rejected. The scores can then only be
+// submitted as level attempts.
+type Server struct {
+	store  db.Store
+	verify Verifier
+}
+
+// NewServer creates the HTTP handler of the REST API using the store provided. The level
+// attempts are verified using the verifier provided if it is not nil.
+func NewServer(store db.Store, verify Verifier) *Server {
+	return &Server{store: store, verify: verify}
+}
+
+// ServeHTTP routes the request to its handler depending on the path and the method used.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, err := getPathSegments(r.URL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch {
+	case len(path) == 1 && path[0] == "users":
+		s.route(w, r, map[string]func(){
+			http.MethodPost: func() { s.createUser(w, r) },
+		})
+
+	case len(path) == 2 && path[0] == "users":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getUser(w, r, path[1]) },
+			http.MethodPut: func() { s.updateUser(w, r, path[1]) },
+		})
+
+	case len(path) == 3 && path[0] == "users" && path[2] == "levels":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getClearedLevel(w, r, path[1]) },
+		})
+
+	case len(path) == 3 && path[0] == "users" && path[2] == "attempts":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getAttempts(w, r, path[1]) },
+		})
+
+	case len(path) == 3 && path[0] == "users" && path[2] == "stats":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getStats(w, r, path[1]) },
+		})
+
+	case len(path) == 4 && path[0] == "users" && path[2] == "levels":
+		s.route(w, r, map[string]func(){
+			http.MethodPost: func() { s.getLevelScore(w, r, path[1], path[3]) },
+		})
+
+	case len(path) == 5 && path[0] == "users" && path[2] == "levels" && path[4] == "scores":
+		s.route(w, r, map[string]func(){
+			http.MethodPost: func() { s.submitScores(w, r, path[1], path[3]) },
+		})
+
+	case len(path) == 5 && path[0] == "users" && path[2] == "levels" && path[4] == "attempts":
+		s.route(w, r, map[string]func(){
+			http.MethodPost: func() { s.submitAttempt(w, r, path[1], path[3]) },
+		})
+
+	case len(path) == 5 && path[0] == "users" && path[2] == "levels" && path[4] == "best":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getBestAttempt(w, r, path[1], path[3]) },
+		})
+
+	case len(path) == 2 && path[0] == "leaderboards":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getLeaderboard(w, r, path[1]) },
+		})
+
+	case len(path) == 4 && path[0] == "leaderboards" && path[2] == "users":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getUserRank(w, r, path[1], path[3]) },
+		})
+
+	case len(path) == 3 && path[0] == "leaderboards" && path[2] == "best":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getBestAttempt(w, r, "", path[1]) },
+		})
+
+	case len(path) == 1 && path[0] == "seasons":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getSeasons(w, r) },
+		})
+
+	case len(path) == 2 && path[0] == "seasons":
+		s.route(w, r, map[string]func(){
+			http.MethodGet: func() { s.getSeason(w, r, path[1]) },
+		})
+
+	default:
+		writeError(w, http.StatusNotFound, errors.New("resource not found"))
+	}
+}
+
+// route invokes the handler of the request method. If the method is not supported
+// the methods allowed are returned.
+func (s *Server) route(w http.ResponseWriter, r *http.Request, handlers map[string]func()) {
+	if handler, ok := handlers[r.Method]; ok {
+		handler()
+		return
+	}
+
+	allowed := make([]string, 0, len(handlers))
+	for method := range handlers {
+		allowed = append(allowed, method)
+	}
+
+	sort.Strings(allowed)
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// createUser handles the requests that get or create a user.
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var req UserRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	data, err := s.store.GetOrCreateUser(r.Context(), &db.UserInfor{TapooID: req.TapooID, Email: req.Email})
+	writeResult(w, data, err)
+}
+
+// getUser handles the requests that fetch a user.
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, tapooID string) {
+	data, err := s.store.GetUser(r.Context(), &db.UserInfor{TapooID: tapooID})
+	writeResult(w, data, err)
+}
+
+// updateUser handles the requests that update a user email.
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, tapooID string) {
+	var req UserRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	user := &db.UserInfor{TapooID: tapooID, Email: req.Email}
+
+	// the user is fetched first since updating a missing user has no effect.
+	if _, err := s.store.GetUser(r.Context(), user); err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	if err := s.store.UpdateUser(r.Context(), user); err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetUser(r.Context(), user)
+	writeResult(w, data, err)
+}
+
+// getClearedLevel handles the requests that fetch the highest level cleared by a user.
+func (s *Server) getClearedLevel(w http.ResponseWriter, r *http.Request, tapooID string) {
+	level, err := s.store.GetHighestClearedLevel(r.Context(), &db.UserInfor{TapooID: tapooID})
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	writeResult(w, &ClearedLevelResponse{Level: level}, nil)
+}
+
+// getLevelScore handles the requests that get or create the user level scores.
+func (s *Server) getLevelScore(w http.ResponseWriter, r *http.Request, tapooID, level string) {
+	user, err := getUserLevel(tapooID, level)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetOrCreateLevelScore(r.Context(), user)
+	writeResult(w, data, err)
+}
+
+// submitScores handles the requests that submit the user level scores. The stored
+// high scores are only replaced if the scores submitted are higher.
+func (s *Server) submitScores(w http.ResponseWriter, r *http.Request, tapooID, level string) {
+	user, err := getUserLevel(tapooID, level)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	var req ScoreRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	// the scores submitted on their own cannot be verified.
+	if s.verify != nil {
+		writeResult(w, nil, fmt.Errorf("%w :: the scores should be submitted as a level attempt with its replay",
+			ErrRejected))
+		return
+	}
+
+	data, err := s.store.SaveHighScores(r.Context(), user, req.HighScores)
+	writeResult(w, data, err)
+}
+
+// submitAttempt handles the requests that record a user level attempt. The stored high
+// scores are only replaced by the scores of a succeeded attempt that are higher. The
+// attempt is verified against its replay first if a verifier is set.
+func (s *Server) submitAttempt(w http.ResponseWriter, r *http.Request, tapooID, level string) {
+	user, err := getUserLevel(tapooID, level)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	var req AttemptRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	a := &db.Attempt{TapooID: user.TapooID, Level: user.Level, Seed: req.Seed, Outcome: req.Outcome,
+		Duration: req.Duration, Moves: req.Moves, Scores: req.Scores, Replay: req.Replay}
+
+	if s.verify != nil {
+		if err = s.verify(a); err != nil {
+			writeResult(w, nil, fmt.Errorf("%w :: %v", ErrRejected, err))
+			return
+		}
+	}
+
+	data, err := s.store.SaveAttempt(r.Context(), a)
+	writeResult(w, data, err)
+}
+
+// getAttempts handles the requests that fetch a page of the user attempts history.
+func (s *Server) getAttempts(w http.ResponseWriter, r *http.Request, tapooID string) {
+	var (
+		q   = &db.AttemptsQuery{TapooID: tapooID, Level: db.AllLevels}
+		err error
+	)
+
+	if level := r.URL.Query().Get("level"); level != "" {
+		var user *db.UserInfor
+		if user, err = getUserLevel(tapooID, level); err == nil {
+			q.Level = user.Level
+		}
+	}
+
+	if err == nil {
+		if q.Limit, err = getLimit(r.URL.Query().Get("limit")); err == nil {
+			q.Offset, err = getOffset(r.URL.Query().Get("offset"))
+		}
+	}
+
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetAttempts(r.Context(), q)
+	writeResult(w, data, err)
+}
+
+// getStats handles the requests that fetch the summary of the levels played by a user.
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request, tapooID string) {
+	data, err := s.store.GetStats(r.Context(), &db.UserInfor{TapooID: tapooID})
+	writeResult(w, data, err)
+}
+
+// getBestAttempt handles the requests that fetch the best replayed attempt of a level.
+// The attempts of every user are considered if the tapoo ID is empty.
+func (s *Server) getBestAttempt(w http.ResponseWriter, r *http.Request, tapooID, level string) {
+	user, err := getUserLevel(tapooID, level)
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetBestAttempt(r.Context(), user)
+	writeResult(w, data, err)
+}
+
+// getLeaderboard handles the requests that fetch a page of the level leaderboard.
+func (s *Server) getLeaderboard(w http.ResponseWriter, r *http.Request, level string) {
+	var (
+		q   = new(db.LeaderboardQuery)
+		err error
+	)
+
+	if q.Level, err = getLeaderboardLevel(level); err == nil {
+		if q.Limit, err = getLimit(r.URL.Query().Get("limit")); err == nil {
+			if q.Offset, err = getOffset(r.URL.Query().Get("offset")); err == nil {
+				err = s.setPeriod(r, q)
+			}
+		}
+	}
+
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetLeaderboard(r.Context(), q)
+	writeResult(w, data, err)
+}
+
+// getUserRank handles the requests that fetch the user rank on the level leaderboard and
+// the entries ranked around it.
+func (s *Server) getUserRank(w http.ResponseWriter, r *http.Request, level, tapooID string) {
+	var (
+		q   = new(db.LeaderboardQuery)
+		err error
+	)
+
+	if q.Level, err = getLeaderboardLevel(level); err == nil {
+		err = s.setPeriod(r, q)
+	}
+
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	neighbors, err := getNeighbors(r.URL.Query().Get("neighbors"))
+	if err != nil {
+		writeResult(w, nil, err)
+		return
+	}
+
+	data, err := s.store.GetUserRank(r.Context(), &db.UserInfor{TapooID: tapooID}, q, neighbors)
+	writeResult(w, data, err)
+}
+
+// getSeasons handles the requests that fetch all the seasons.
+func (s *Server) getSeasons(w http.ResponseWriter, r *http.Request) {
+	data, err := s.store.GetSeasons(r.Context())
+	writeResult(w, data, err)
+}
+
+// getSeason handles the requests that fetch a season.
+func (s *Server) getSeason(w http.ResponseWriter, r *http.Request, name string) {
+	data, err := s.store.GetSeason(r.Context(), name)
+	writeResult(w, data, err)
+}
+
+// setPeriod limits the leaderboard to the period, the season or the times requested.
+// Only one of them can be requested.
+func (s *Server) setPeriod(r *http.Request, q *db.LeaderboardQuery) error {
+	var (
+		values  = r.URL.Query()
+		period  = values.Get("period")
+		season  = values.Get("season")
+		from    = values.Get("from")
+		to      = values.Get("to")
+		options = 0
+	)
+
+	for _, isSet := range []bool{period != "", season != "", from != "" || to != ""} {
+		if isSet {
+			options++
+		}
+	}
+
+	if options > 1 {
+		return &db.ErrInvalidInput{Field: "period", Value: "only one of period, season or from and to can be used"}
+	}
+
+	switch {
+	case period != "":
+		return q.SetPeriod(period, time.Now())
+
+	case season != "":
+		d, err := s.store.GetSeason(r.Context(), season)
+		if err != nil {
+			return err
+		}
+
+		q.SetSeason(d)
+		return nil
+	}
+
+	var err error
+	if q.From, err = getTime("from", from); err != nil {
+		return err
+	}
+
+	q.To, err = getTime("to", to)
+	return err
+}
+
+// getPathSegments splits the unescaped URL path into its non-empty segments.
+func getPathSegments(u *url.URL) ([]string, error) {
+	var path []string
+
+	for _, segment := range strings.Split(u.EscapedPath(), "/") {
+		if segment == "" {
+			continue
+		}
+
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+
+		path = append(path, value)
+	}
+
+	return path, nil
+}
+
+// getUserLevel returns the user information of the tapoo ID and the level provided.
+func getUserLevel(tapooID, level string) (*db.UserInfor, error) {
+	l, err := strconv.Atoi(level)
+	if err != nil {
+		return nil, &db.ErrInvalidInput{Field: "game level", Value: level}
+	}
+
+	return &db.UserInfor{TapooID: tapooID, Level: l}, nil
+}
+
+// getLeaderboardLevel returns the leaderboard level provided.
+func getLeaderboardLevel(level string) (int, error) {
+	if level == globalLevel {
+		return db.GlobalLeaderboard, nil
+	}
+
+	user, err := getUserLevel("", level)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.Level, nil
+}
+
+// getLimit returns the number of high scores requested. The default limit is returned
+// if none is provided and the maximum limit is returned if a larger one is provided.
+func getLimit(value string) (int, error) {
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, &db.ErrInvalidInput{Field: "limit", Value: value}
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return limit, nil
+}
+
+// getOffset returns the number of leaderboard entries skipped, zero if none is provided.
+func getOffset(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, &db.ErrInvalidInput{Field: "offset", Value: value}
+	}
+
+	return offset, nil
+}
+
+// getNeighbors returns the number of entries requested above and below the user rank. The
+// default number is returned if none is provided and the maximum number is returned if a
+// larger one is provided.
+func getNeighbors(value string) (int, error) {
+	if value == "" {
+		return defaultNeighbors, nil
+	}
+
+	neighbors, err := strconv.Atoi(value)
+	if err != nil || neighbors < 0 {
+		return 0, &db.ErrInvalidInput{Field: "neighbors", Value: value}
+	}
+
+	if neighbors > maxNeighbors {
+		neighbors = maxNeighbors
+	}
+
+	return neighbors, nil
+}
+
+// getTime returns the RFC 3339 time provided, the zero time if none is provided.
+func getTime(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &db.ErrInvalidInput{Field: field, Value: value}
+	}
+
+	return t, nil
+}
+
+// readJSON decodes the request body into v. If the body is invalid a bad request
+// response is written and false is returned.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request body: "+err.Error()))
+		return false
+	}
+
+	return true
+}
+
+// writeResult writes the data provided if no error is found. Otherwise the status code
+// matching the error is written.
+func writeResult(w http.ResponseWriter, data interface{}, err error) {
+	var invalid *db.ErrInvalidInput
+
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, data)
+
+	case errors.As(err, &invalid):
+		writeError(w, http.StatusBadRequest, err)
+
+	case errors.Is(err, db.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+
+	case errors.Is(err, db.ErrDuplicate):
+		writeError(w, http.StatusConflict, err)
+
+	case errors.Is(err, ErrRejected):
+		writeError(w, http.StatusUnprocessableEntity, err)
+
+	default:
+		// the internal errors are logged instead of being exposed.
+		log.Printf("api: %v", err)
+		writeError(w, http.StatusInternalServerError, errors.New("internal server error"))
+	}
+}
+
+// writeError writes the error message with the status code provided.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// writeJSON writes the data provided as JSON with the status code provided.
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("api: writing the response failed :: %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestServer tests the functionality of Server
+func TestServer(t *testing.T) {
+	Convey("TestServer: Given the REST API served using an in-memory store", t, func() {
+		store := db.NewMemoryStore()
+		defer store.Close()
+
+		ts := httptest.NewServer(NewServer(store, nil))
+		defer ts.Close()
+
+		// request sends the request and decodes the JSON response into v.
+		request := func(method, path, body string, v interface{}) *http.Response {
+			req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+			So(err, ShouldBeNil)
+
+			resp, err := http.DefaultClient.Do(req)
+			So(err, ShouldBeNil)
+
+			defer resp.Body.Close()
+
+			So(resp.Header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(json.NewDecoder(resp.Body).Decode(v), ShouldBeNil)
+
+			return resp
+		}
+
+		var (
+			user    db.UserInfoResponse
+			score   db.LevelScoreResponse
+			scores  []db.LeaderboardEntry
+			failure ErrorResponse
+		)
+
+		resp := request(http.MethodPost, "/users", `{"id": "Vf2TqN5MB", "email": "sgravell1@europa.eu"}`, &user)
+
+		So(resp.StatusCode, ShouldEqual, http.StatusOK)
+		So(user.TapooID, ShouldEqual, "Vf2TqN5MB")
+		So(user.Email, ShouldEqual, "sgravell1@europa.eu")
+
+		Convey("an existing user should be fetched by the tapoo ID", func() {
+			resp := request(http.MethodGet, "/users/Vf2TqN5MB", "", &user)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(user.Email, ShouldEqual, "sgravell1@europa.eu")
+		})
+
+		Convey("a tapoo ID that contains escaped characters should be unescaped", func() {
+			resp := request(http.MethodPost, "/users", `{"id": "VZWe Oq2p"}`, &user)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+
+			resp = request(http.MethodGet, "/users/VZWe%20Oq2p", "", &user)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(user.TapooID, ShouldEqual, "VZWe Oq2p")
+		})
+
+		Convey("a user that does not exist should not be found", func() {
+			resp := request(http.MethodGet, "/users/fake_tapoo_id", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+			So(failure.Error, ShouldContainSubstring, "record not found")
+
+			resp = request(http.MethodPut, "/users/fake_tapoo_id", `{"email": "test@naihub.com"}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("the user email should be updated", func() {
+			resp := request(http.MethodPut, "/users/Vf2TqN5MB", `{"email": "test@naihub.com"}`, &user)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(user.Email, ShouldEqual, "test@naihub.com")
+
+			resp = request(http.MethodPut, "/users/Vf2TqN5MB", `{"email": ""}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid Email found : '(empty)'")
+		})
+
+		Convey("invalid request bodies should be rejected", func() {
+			resp := request(http.MethodPost, "/users", `{"id": `, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid request body")
+
+			resp = request(http.MethodPost, "/users", `{"id": "Vf2TqN5MB", "level": 3}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "unknown field")
+
+			resp = request(http.MethodPost, "/users", `{"id": ""}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid Tapoo ID found : '(empty)'")
+		})
+
+		Convey("the level scores should be created and the higher scores submitted saved", func() {
+			resp := request(http.MethodPost, "/users/Vf2TqN5MB/levels/2", "", &score)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(score.Level, ShouldEqual, 2)
+			So(score.HighScores, ShouldEqual, 0)
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/2/scores", `{"high_scores": 1653}`, &score)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(score.HighScores, ShouldEqual, 1653)
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/2/scores", `{"high_scores": 1200}`, &score)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(score.HighScores, ShouldEqual, 1653)
+
+			var cleared ClearedLevelResponse
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/levels", "", &cleared)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(cleared.Level, ShouldEqual, 2)
+
+			Convey("and displayed on the level leaderboard", func() {
+				request(http.MethodPost, "/users", `{"id": "GzlWAL0mP"}`, &user)
+				request(http.MethodPost, "/users/GzlWAL0mP/levels/2/scores", `{"high_scores": 1948}`, &score)
+
+				resp := request(http.MethodGet, "/leaderboards/2", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 2)
+				So(scores[0].TapooID, ShouldEqual, "GzlWAL0mP")
+				So(scores[0].Rank, ShouldEqual, 1)
+				So(scores[1].TapooID, ShouldEqual, "Vf2TqN5MB")
+				So(scores[1].Rank, ShouldEqual, 2)
+
+				resp = request(http.MethodGet, "/leaderboards/2?limit=1&offset=1", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 1)
+				So(scores[0].TapooID, ShouldEqual, "Vf2TqN5MB")
+				So(scores[0].Rank, ShouldEqual, 2)
+
+				request(http.MethodPost, "/users/Vf2TqN5MB/levels/1/scores", `{"high_scores": 400}`, &score)
+
+				resp = request(http.MethodGet, "/leaderboards/global", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 2)
+				So(scores[0].TapooID, ShouldEqual, "Vf2TqN5MB")
+				So(scores[0].HighScores, ShouldEqual, 2053)
+
+				resp = request(http.MethodGet, "/leaderboards/2/users/Vf2TqN5MB?neighbors=0", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 1)
+				So(scores[0].Rank, ShouldEqual, 2)
+
+				resp = request(http.MethodGet, "/leaderboards/1/users/GzlWAL0mP", "", &failure)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+				resp = request(http.MethodGet, "/leaderboards/3", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldBeEmpty)
+
+				resp = request(http.MethodGet, "/leaderboards/2?period=daily", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 2)
+				So(scores[1].TapooID, ShouldEqual, "Vf2TqN5MB")
+				So(scores[1].HighScores, ShouldEqual, 1653)
+
+				from := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+				resp = request(http.MethodGet, "/leaderboards/global/users/GzlWAL0mP?from="+from, "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldHaveLength, 2)
+				So(scores[1].TapooID, ShouldEqual, "GzlWAL0mP")
+				So(scores[1].Rank, ShouldEqual, 2)
+			})
+
+			Convey("and limited to the seasons", func() {
+				now := time.Now()
+
+				So(store.CreateSeason(context.Background(), &db.Season{Name: "Spring 2020",
+					StartsAt: now.AddDate(-1, 0, 0), EndsAt: now.AddDate(0, 0, -1)}), ShouldBeNil)
+
+				var seasons []db.Season
+				resp := request(http.MethodGet, "/seasons", "", &seasons)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(seasons, ShouldHaveLength, 1)
+				So(seasons[0].Name, ShouldEqual, "Spring 2020")
+
+				var season db.Season
+				resp = request(http.MethodGet, "/seasons/Spring%202020", "", &season)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(season.Name, ShouldEqual, "Spring 2020")
+
+				resp = request(http.MethodGet, "/leaderboards/2?season=Spring%202020", "", &scores)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(scores, ShouldBeEmpty)
+
+				resp = request(http.MethodGet, "/leaderboards/2?season=Winter", "", &failure)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+				resp = request(http.MethodGet, "/seasons/Winter", "", &failure)
+
+				So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("the level attempts should be recorded and summarized", func() {
+			resp := request(http.MethodPost, "/users/Vf2TqN5MB/levels/3/attempts",
+				`{"seed": 2018, "outcome": "failed", "duration": 90000000000, "moves": 54, "scores": 0}`, &score)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(score.Level, ShouldEqual, 3)
+			So(score.HighScores, ShouldEqual, 0)
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/3/attempts",
+				`{"seed": 2018, "outcome": "succeeded", "duration": 60000000000, "moves": 40, "scores": 1100}`, &score)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(score.HighScores, ShouldEqual, 1100)
+
+			var attempts []db.Attempt
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/attempts?level=3&limit=1", "", &attempts)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(attempts, ShouldHaveLength, 1)
+			So(attempts[0].Outcome, ShouldEqual, db.Succeeded)
+			So(attempts[0].Duration, ShouldEqual, time.Minute)
+
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/attempts?offset=1", "", &attempts)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(attempts, ShouldHaveLength, 1)
+			So(attempts[0].Outcome, ShouldEqual, db.Failed)
+
+			var stats db.Stats
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/stats", "", &stats)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			So(stats.Attempts, ShouldEqual, 2)
+			So(stats.WinRate, ShouldEqual, 0.5)
+			So(stats.Levels, ShouldHaveLength, 1)
+			So(stats.Levels[0].BestScores, ShouldEqual, 1100)
+
+			resp = request(http.MethodGet, "/users/fake_tapoo_id/stats", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/levels/3/best", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+			resp = request(http.MethodGet, "/leaderboards/x/best", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/3/attempts", `{"outcome": "won"}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid outcome found : 'won'")
+
+			resp = request(http.MethodGet, "/users/Vf2TqN5MB/attempts?level=three", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid game level found : 'three'")
+		})
+
+		Convey("invalid levels, scores and limits should be rejected", func() {
+			resp := request(http.MethodPost, "/users/Vf2TqN5MB/levels/two", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid game level found : 'two'")
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/-1", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+			resp = request(http.MethodPost, "/users/Vf2TqN5MB/levels/2/scores", `{"high_scores": -5}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid high scores found : '-5'")
+
+			resp = request(http.MethodGet, "/leaderboards/2?limit=0", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid limit found : '0'")
+
+			resp = request(http.MethodGet, "/leaderboards/2?offset=-3", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid offset found : '-3'")
+
+			resp = request(http.MethodGet, "/leaderboards/2/users/Vf2TqN5MB?neighbors=many", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid neighbors found : 'many'")
+
+			resp = request(http.MethodGet, "/leaderboards/2?period=monthly", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid period found : 'monthly'")
+
+			resp = request(http.MethodGet, "/leaderboards/2?period=daily&season=Spring", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "only one of period, season or from and to can be used")
+
+			resp = request(http.MethodGet, "/leaderboards/global?from=yesterday", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(failure.Error, ShouldContainSubstring, "invalid from found : 'yesterday'")
+		})
+
+		Convey("scores submitted for a user that does not exist should not be found", func() {
+			resp := request(http.MethodPost, "/users/fake_tapoo_id/levels/2/scores", `{"high_scores": 5}`, &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("unknown paths and methods should be rejected", func() {
+			resp := request(http.MethodGet, "/scores", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+
+			resp = request(http.MethodDelete, "/users/Vf2TqN5MB", "", &failure)
+
+			So(resp.StatusCode, ShouldEqual, http.StatusMethodNotAllowed)
+			So(resp.Header.Get("Allow"), ShouldEqual, "GET, PUT")
+		})
+	})
+}
+
+// TestServerVerifier tests the functionality of Server with a verifier set
+func TestServerVerifier(t *testing.T) {
+	Convey("TestServerVerifier: Given the REST API verifying the level attempts", t, func() {
+		var (
+			ctx   = context.Background()
+			user  = &db.UserInfor{TapooID: "Vf2TqN5MB", Level: 3}
+			store = db.NewMemoryStore()
+		)
+
+		defer store.Close()
+
+		// verify accepts the attempts whose scores do not exceed a hundred per move replayed.
+		verify := func(a *db.Attempt) error {
+			if a.Replay == nil {
+				return errors.New("the attempt replay was not found")
+			}
+
+			if a.Scores > len(a.Replay.Events)*100 {
+				return fmt.Errorf("invalid scores found: %d submitted", a.Scores)
+			}
+
+			return nil
+		}
+
+		ts := httptest.NewServer(NewServer(store, verify))
+		defer ts.Close()
+
+		queuePath, cleanUp := getQueuePath()
+		defer cleanUp()
+
+		client, err := NewClient(ts.URL, queuePath)
+		So(err, ShouldBeNil)
+
+		defer client.Close()
+
+		_, err = client.GetOrCreateUser(ctx, user)
+		So(err, ShouldBeNil)
+
+		replay := &db.Replay{Length: 40, Width: 20, Events: []db.ReplayEvent{
+			{Time: 400, Kind: "move", Direction: "UP"}, {Time: 900, Kind: "move", Direction: "LEFT"}}}
+
+		Convey("an attempt matching its replay should be recorded", func() {
+			score, err := client.SaveAttempt(ctx, &db.Attempt{TapooID: user.TapooID, Level: 3,
+				Outcome: db.Succeeded, Moves: 2, Scores: 200, Replay: replay})
+
+			So(err, ShouldBeNil)
+			So(score.HighScores, ShouldEqual, 200)
+		})
+
+		Convey("an attempt that does not match its replay should be rejected and not queued", func() {
+			for _, item := range []struct {
+				attempt *db.Attempt
+				msg     string
+			}{
+				{&db.Attempt{TapooID: user.TapooID, Level: 3, Outcome: db.Succeeded, Scores: 300, Replay: replay},
+					"invalid scores found: 300 submitted"},
+				{&db.Attempt{TapooID: user.TapooID, Level: 3, Outcome: db.Succeeded, Scores: 100},
+					"the attempt replay was not found"},
+			} {
+				_, err := client.SaveAttempt(ctx, item.attempt)
+
+				So(errors.Is(err, ErrRejected), ShouldBeTrue)
+				So(err.Error(), ShouldContainSubstring, "422")
+				So(err.Error(), ShouldContainSubstring, item.msg)
+			}
+
+			So(client.Queued(), ShouldEqual, 0)
+
+			score, err := store.GetOrCreateLevelScore(ctx, user)
+
+			So(err, ShouldBeNil)
+			So(score.HighScores, ShouldEqual, 0)
+		})
+
+		Convey("the scores submitted without an attempt should be rejected", func() {
+			_, err := client.SaveHighScores(ctx, user, 100)
+
+			So(errors.Is(err, ErrRejected), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "should be submitted as a level attempt")
+		})
+	})
+}
+
+// TestGetLimit tests the functionality of getLimit
+func TestGetLimit(t *testing.T) {
+	Convey("TestGetLimit: Given the limit query parameter", t, func() {
+		Convey("the default limit should be used if it is empty", func() {
+			limit, err := getLimit("")
+
+			So(err, ShouldBeNil)
+			So(limit, ShouldEqual, defaultLimit)
+		})
+
+		Convey("the maximum limit should be used if it is too large", func() {
+			limit, err := getLimit("5000")
+
+			So(err, ShouldBeNil)
+			So(limit, ShouldEqual, maxLimit)
+		})
+
+		Convey("an error should be returned if it is not a positive number", func() {
+			for _, value := range []string{"ten", "-1", "0"} {
+				_, err := getLimit(value)
+
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -0,0 +1,255 @@
+package maze
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+const (
+	waitingMsg     = "          Joined as Player %d. Waiting for the other player...           "
+	remoteHiding   = "          Round %d of %d: Player %d is hiding. Please wait...            "
+	remoteSeeking  = "          Player %d: Seek Player %d!     Time Left: %3ds               "
+	remoteWatching = "         Player %d is seeking you.     Time Left: %3ds                 "
+	remoteQuit     = "              Player %d has left the game. Press ESC to quit.            "
+	remoteError    = "  Error: %s  "
+)
+
+// remoteView defines the game state as seen by a player joined to a hide and seek server.
+// It is built from the messages received from the server.
+type remoteView struct {
+	config   Dimensions
+	player   int
+	role     int
+	maze     []string
+	position int
+	target   int
+	timeLeft int
+	round    int
+	rounds   int
+	scores   []int
+	status   string
+	err      string
+	over     bool
+}
+
+// apply updates the view with the changes carried by the message provided.
+func (v *remoteView) apply(m Message) {
+	v.err = ""
+
+	switch m.Type {
+	case msgWelcome:
+		v.player, v.rounds = m.Player, m.Rounds
+		v.status = fmt.Sprintf(waitingMsg, m.Player+1)
+
+	case msgHide, msgSeek:
+		v.round, v.rounds = m.Round, m.Rounds
+		v.maze, v.position, v.target = m.Maze, m.Position, m.Target
+		v.config = Dimensions{Length: m.Length, Width: m.Width}
+
+		// only the hider receives the maze while hiding and the hiding spot while seeking.
+		v.role = seeker
+		if (m.Type == msgHide && len(m.Maze) > 0) || m.Target > 0 {
+			v.role = hider
+		}
+
+		v.status = fmt.Sprintf(remoteHiding, m.Round, m.Rounds, (v.player+1)%2+1)
+
+	case msgPosition:
+		v.position = m.Position
+
+	case msgTime:
+		v.timeLeft = m.TimeLeft
+
+	case msgResult:
+		// the scores of both players are expected from the server.
+		if len(m.Scores) != 2 {
+			v.err = fmt.Sprintf(remoteError, "invalid round result received")
+			return
+		}
+
+		v.scores, v.maze = m.Scores, nil
+		v.status = fmt.Sprintf(hotseatScores, m.Scores[0], m.Scores[1])
+
+	case msgOver:
+		v.scores, v.maze, v.over = m.Scores, nil, true
+
+		v.status = hotseatDraw
+		if m.Winner >= 0 {
+			v.status = fmt.Sprintf(hotseatWinner, m.Winner+1)
+		}
+
+	case msgQuit:
+		v.maze, v.over = nil, true
+		v.status = fmt.Sprintf(remoteQuit, m.Player+1)
+
+	case msgError:
+		v.err = fmt.Sprintf(remoteError, m.Error)
+	}
+}
+
+// getStatusMsg returns the message displayed under the maze.
+func (v *remoteView) getStatusMsg(hidden bool) string {
+	switch {
+	case v.maze == nil:
+		return v.status
+
+	case hidden:
+		return fmt.Sprintf(hidingMsg, v.player+1, v.timeLeft)
+
+	case v.role == hider:
+		return fmt.Sprintf(remoteWatching, (v.player+1)%2+1, v.timeLeft)
+	}
+
+	return fmt.Sprintf(remoteSeeking, v.player+1, (v.player+1)%2+1, v.timeLeft)
+}
+
+// draw displays the view on the termbox view.
+func (v *remoteView) draw() {
+	if err := termbox.Clear(coldef, coldef); err != nil {
+		panic(err)
+	}
+
+	for loc, msg := range map[int]string{1: intro, 3: website, 5: playerNavigation} {
+		fill(3, loc, msg, coldef)
+	}
+
+	// hidden is true while the player is choosing a hiding spot.
+	hidden := v.role == hider && v.target == 0
+
+	for k, row := range v.maze {
+		fill(3, 7+k, row, coldef)
+	}
+
+	if v.maze != nil {
+		if v.role == hider && v.target > 0 {
+			targetPos := v.config.getCellAddress(v.target).MiddleCenter
+			termbox.SetCell((targetPos[1]*2)+3, targetPos[0]+7, '#', termbox.ColorRed, termbox.ColorRed)
+		}
+
+		startPos := v.config.getCellAddress(v.position).MiddleCenter
+		termbox.SetCell((startPos[1]*2)+3, startPos[0]+7, '@', termbox.ColorGreen, termbox.ColorGreen)
+	}
+
+	fill(3, len(v.maze)+8, v.getStatusMsg(hidden), coldef)
+	fill(3, len(v.maze)+10, v.err, termbox.ColorRed)
+
+	termbox.Flush()
+}
+
+// getRemoteMessage returns the message to be sent to the server for the key event
+// provided. False is returned if the key is not used by the remote game.
+func getRemoteMessage(event termbox.Event) (Message, bool) {
+	switch event.Key {
+	case termbox.KeyEsc, termbox.KeyCtrlC:
+		return Message{Type: msgQuit}, true
+
+	case termbox.KeyEnter:
+		return Message{Type: msgReady}, true
+
+	case termbox.KeyArrowLeft:
+		return Message{Type: msgMove, Direction: "LEFT"}, true
+
+	case termbox.KeyArrowRight:
+		return Message{Type: msgMove, Direction: "RIGHT"}, true
+
+	case termbox.KeyArrowUp:
+		return Message{Type: msgMove, Direction: "UP"}, true
+
+	case termbox.KeyArrowDown:
+		return Message{Type: msgMove, Direction: "DOWN"}, true
+	}
+
+	return Message{}, false
+}
+
+// Join connects to the hide and seek server on the address provided and plays the game
+// on the current terminal until the game is over or the player quits. The error that ended
+// the game is returned once the terminal is restored and the connection is closed.
+func Join(addr string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if err = termbox.Init(); err != nil {
+		return err
+	}
+
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	encoder := json.NewEncoder(conn)
+	terminalSize := getTerminalSize(termbox.Size())
+
+	if err = encoder.Encode(Message{Type: msgJoin, Length: terminalSize.Length, Width: terminalSize.Width}); err != nil {
+		return err
+	}
+
+	var (
+		view     = &remoteView{}
+		messages = make(chan Message)
+		events   = make(chan termbox.Event)
+	)
+
+	go func() {
+		defer close(messages)
+
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			var m Message
+			if json.Unmarshal(scanner.Bytes(), &m) == nil {
+				messages <- m
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			events <- termbox.PollEvent()
+		}
+	}()
+
+	for {
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				// the server closed the connection without the game being over.
+				messages = nil
+				if !view.over {
+					view.apply(Message{Type: msgQuit, Player: (view.player + 1) % 2})
+				}
+			} else {
+				view.apply(m)
+			}
+
+			view.draw()
+
+		case ev := <-events:
+			if ev.Type == termbox.EventError {
+				panic(ev.Err)
+			}
+
+			m, ok := getRemoteMessage(ev)
+			if !ok {
+				continue
+			}
+
+			if m.Type == msgQuit {
+				encoder.Encode(m)
+				return nil
+			}
+
+			if !view.over {
+				encoder.Encode(m)
+			}
+		}
+	}
+}
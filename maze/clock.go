@@ -0,0 +1,90 @@
+package maze
+
+import (
+	"sync"
+	"time"
+)
+
+// gameClock tracks the time a level is played against its time limit. The time the level
+// is paused is not counted thus the clock drives both the level timeout and its scores.
+// now returns the current time so that the time can be faked in tests.
+type gameClock struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	limit time.Duration
+
+	// played holds the time played before the clock was last resumed while resumed holds
+	// the time it was resumed at if it is running.
+	played  time.Duration
+	resumed time.Time
+	running bool
+}
+
+// newGameClock returns a stopped clock that reads the current time using the function provided.
+func newGameClock(now func() time.Time) *gameClock {
+	return &gameClock{now: now}
+}
+
+// reset stops the clock and sets the time limit and the time played of the level provided.
+func (c *gameClock) reset(limit, played time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limit, c.played, c.running = limit, played, false
+}
+
+// resume starts counting the time played if the clock is stopped.
+func (c *gameClock) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		c.resumed, c.running = c.now(), true
+	}
+}
+
+// pause stops counting the time played if the clock is running.
+func (c *gameClock) pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		c.played += c.now().Sub(c.resumed)
+		c.running = false
+	}
+}
+
+// getPlayed returns the time played so far which never exceeds the time limit.
+func (c *gameClock) getPlayed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	played := c.played
+	if c.running {
+		played += c.now().Sub(c.resumed)
+	}
+
+	if played > c.limit {
+		return c.limit
+	}
+
+	return played
+}
+
+// isTimedOut checks if the time limit has been played.
+func (c *gameClock) isTimedOut() bool {
+	return c.getPlayed() == c.getLimit()
+}
+
+// getLimit returns the time limit of the level.
+func (c *gameClock) getLimit() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.limit
+}
+
+// getScores returns the scores of the time left before the time limit is reached.
+func (c *gameClock) getScores() int {
+	return getLevelScores(c.getLimit(), c.getPlayed())
+}
@@ -0,0 +1,62 @@
+package maze
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestGameClock tests the functionality of gameClock
+func TestGameClock(t *testing.T) {
+	Convey("TestGameClock: Given a clock reading a fake time", t, func() {
+		var (
+			now   = time.Date(2018, time.March, 1, 10, 0, 0, 0, time.UTC)
+			clock = newGameClock(func() time.Time { return now })
+			limit = 20 * time.Second
+		)
+
+		clock.reset(limit, 0)
+
+		Convey("the time should only be counted once the clock is resumed", func() {
+			now = now.Add(5 * time.Second)
+			So(clock.getPlayed(), ShouldEqual, 0)
+
+			clock.resume()
+			now = now.Add(4 * time.Second)
+
+			So(clock.getPlayed(), ShouldEqual, 4*time.Second)
+			So(clock.getScores(), ShouldEqual, getLevelScores(limit, 4*time.Second))
+			So(clock.isTimedOut(), ShouldBeFalse)
+
+			Convey("and the time the clock is paused should not be counted", func() {
+				clock.pause()
+				now = now.Add(time.Minute)
+
+				So(clock.getPlayed(), ShouldEqual, 4*time.Second)
+
+				clock.resume()
+				clock.resume()
+				now = now.Add(3 * time.Second)
+
+				So(clock.getPlayed(), ShouldEqual, 7*time.Second)
+			})
+
+			Convey("and the time played should never exceed the time limit", func() {
+				now = now.Add(time.Minute)
+
+				So(clock.getPlayed(), ShouldEqual, limit)
+				So(clock.getScores(), ShouldEqual, 0)
+				So(clock.isTimedOut(), ShouldBeTrue)
+			})
+
+			Convey("and a reset should stop the clock with the time played provided", func() {
+				clock.reset(limit, 6*time.Second)
+				now = now.Add(time.Minute)
+
+				So(clock.getPlayed(), ShouldEqual, 6*time.Second)
+				So(clock.getLimit(), ShouldEqual, limit)
+			})
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// The following drivers define the storage backends that can be selected using
+// Config.Driver or the TAPOO_DB_DRIVER environment variable.
+const (
+	// mysqlDriver stores the users and their scores in a MySQL database server.
+	mysqlDriver = "mysql"
+
+	// sqliteDriver stores the users and their scores in a local SQLite database file
+	// so that the game can be played offline.
+	sqliteDriver = "sqlite3"
+
+	// memoryDriver stores the users and their scores in memory until the game exits.
+	memoryDriver = "memory"
+)
+
+// defaultSQLitePath defines the SQLite database file used if TAPOO_DB_PATH is not set.
+const defaultSQLitePath = "tapoo.db"
+
+const errMsg = "envVars: %s environment variable is not set"
+
+// Config defines the database configuration used to open a store. Driver should be one
+// of mysql, sqlite3 or memory. DbPath is only used by the sqlite3 driver while the
+// remaining fields are only used by the mysql driver. SkipMigrations leaves the schema
+// as it is when the store is opened.
+type Config struct {
+	DbHost         string
+	DbName         string
+	DbPath         string
+	DbUserName     string
+	DbUserPassword string
+	Driver         string
+	SkipMigrations bool
+}
+
+// Open creates the store of the driver selected in the configuration provided and
+// applies the pending schema migrations unless they should be skipped. The context
+// provided limits the time taken to connect to the database and migrate it. The store
+// should be closed once it is no longer needed.
+func Open(ctx context.Context, c Config) (Store, error) {
+	var dataSource string
+
+	switch c.Driver {
+	case mysqlDriver:
+		dataSource = fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true&loc=Local",
+			c.DbUserName, c.DbUserPassword, c.DbHost, c.DbName)
+
+	case sqliteDriver:
+		// the transactions lock the database file as they start and wait for the
+		// concurrent transactions instead of failing immediately.
+		dataSource = fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000&_txlock=immediate", c.DbPath)
+
+	case memoryDriver:
+		return NewMemoryStore(), nil
+
+	default:
+		return nil, &ErrInvalidInput{Field: "database driver", Value: c.Driver}
+	}
+
+	// a nil *sqlStore should not be returned as a non-nil Store.
+	s, err := newSQLStore(ctx, c.Driver, dataSource, !c.SkipMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ConfigFromEnv fetches the database configuration from the TAPOO_DB_DRIVER, TAPOO_DB_NAME,
+// TAPOO_DB_USER_NAME, TAPOO_DB_USER_PASSWORD, TAPOO_DB_HOST and TAPOO_DB_PATH environment
+// variables. MySQL is used if TAPOO_DB_DRIVER is not set. An error message is returned
+// if any of the environment variables required by the driver is found missing.
+func ConfigFromEnv() (Config, error) {
+	var (
+		c  Config
+		ok bool
+	)
+
+	if c.Driver, ok = os.LookupEnv("TAPOO_DB_DRIVER"); !ok {
+		c.Driver = mysqlDriver
+	}
+
+	switch c.Driver {
+	case sqliteDriver:
+		if c.DbPath, ok = os.LookupEnv("TAPOO_DB_PATH"); !ok {
+			c.DbPath = defaultSQLitePath
+		}
+
+	case mysqlDriver:
+		if c.DbName, ok = os.LookupEnv("TAPOO_DB_NAME"); !ok {
+			return Config{}, fmt.Errorf(errMsg, "TAPOO_DB_NAME")
+		}
+
+		// getUserEnvVars checks if both Db username and password are set
+		if err := getUserEnvVars(&c); err != nil {
+			return Config{}, err
+		}
+
+		if c.DbHost, ok = os.LookupEnv("TAPOO_DB_HOST"); !ok {
+			return Config{}, fmt.Errorf(errMsg, "TAPOO_DB_HOST")
+		}
+	}
+
+	return c, nil
+}
+
+// getUserEnvVars is a part of ConfigFromEnv function and is spit so as to
+// reduce the congnitive complexity of the function ConfigFromEnv.
+func getUserEnvVars(c *Config) error {
+	ok := false
+
+	if c.DbUserName, ok = os.LookupEnv("TAPOO_DB_USER_NAME"); !ok {
+		return fmt.Errorf(errMsg, "TAPOO_DB_USER_NAME")
+	}
+
+	if c.DbUserPassword, ok = os.LookupEnv("TAPOO_DB_USER_PASSWORD"); !ok {
+		return fmt.Errorf(errMsg, "TAPOO_DB_USER_PASSWORD")
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestOpen tests the functionality of Open
+func TestOpen(t *testing.T) {
+	Convey("TestOpen: Given the database configuration", t, func() {
+		Convey("with an unknown driver, a value that implements an error interface should be returned", func() {
+			store, err := Open(context.Background(), Config{Driver: "postgres"})
+
+			So(store, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid database driver found : 'postgres'")
+		})
+
+		Convey("with the MySQL values that are not empty but are incorrect, a value that implements"+
+			" an error interface should be returned when a ping is made", func() {
+			store, err := Open(context.Background(), Config{
+				DbHost:         "127.0.0.1:1",
+				DbName:         "test_db",
+				DbUserName:     "test_user_tapoo",
+				DbUserPassword: "fake_password",
+				Driver:         mysqlDriver,
+			})
+
+			So(store, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "db connection: incorrect database configurations used")
+		})
+
+		Convey("with the memory driver, an empty memory store should be returned", func() {
+			store, err := Open(context.Background(), Config{Driver: memoryDriver})
+
+			So(err, ShouldBeNil)
+			So(store, ShouldHaveSameTypeAs, &memoryStore{})
+		})
+
+		Convey("with the SQLite driver, the database file should be created with its tables", func() {
+			dir, cleanUp := getTempDir()
+			defer cleanUp()
+
+			path := filepath.Join(dir, "tapoo.db")
+
+			store, err := Open(context.Background(), Config{Driver: sqliteDriver, DbPath: path})
+
+			So(err, ShouldBeNil)
+			So(store, ShouldHaveSameTypeAs, &sqlStore{})
+
+			_, err = os.Stat(path)
+			So(err, ShouldBeNil)
+
+			data, err := store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "VZWeOq2p"})
+
+			So(err, ShouldBeNil)
+			So(data.TapooID, ShouldEqual, "VZWeOq2p")
+			So(store.Close(), ShouldBeNil)
+
+			Convey("and the data saved should be found once the file is opened again", func() {
+				store, err = Open(context.Background(), Config{Driver: sqliteDriver, DbPath: path})
+				So(err, ShouldBeNil)
+
+				defer store.Close()
+
+				data, err = store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "VZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(data.TapooID, ShouldEqual, "VZWeOq2p")
+			})
+		})
+	})
+}
+
+// TestConfigFromEnv tests the functionality of ConfigFromEnv
+func TestConfigFromEnv(t *testing.T) {
+	var (
+		envVars = []string{"TAPOO_DB_DRIVER", "TAPOO_DB_NAME", "TAPOO_DB_USER_NAME",
+			"TAPOO_DB_USER_PASSWORD", "TAPOO_DB_HOST", "TAPOO_DB_PATH"}
+		copyOfEnvVars = make(map[string]string)
+	)
+
+	for _, key := range envVars {
+		if value, ok := os.LookupEnv(key); ok {
+			copyOfEnvVars[key] = value
+		}
+	}
+
+	// unsetEnvVars removes the db configurations environment variables
+	unsetEnvVars := func() {
+		for _, key := range envVars {
+			os.Unsetenv(key)
+		}
+	}
+
+	defer func() {
+		unsetEnvVars()
+
+		for key, value := range copyOfEnvVars {
+			os.Setenv(key, value)
+		}
+	}()
+
+	unsetEnvVars()
+
+	Convey("TestConfigFromEnv: Given the db configuration environment variables", t, func() {
+		Convey("with no variables set, a value that implements an error interface should be returned", func() {
+			c, err := ConfigFromEnv()
+
+			So(err, ShouldNotBeNil)
+			So(err, ShouldImplement, (*error)(nil))
+			So(err.Error(), ShouldContainSubstring, "envVars: TAPOO_DB_NAME")
+			So(c, ShouldResemble, Config{})
+		})
+
+		Convey("with TAPOO_DB_NAME added, a value that implements an error interface should be returned", func() {
+			os.Setenv("TAPOO_DB_NAME", "test_db")
+
+			c, err := ConfigFromEnv()
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "envVars: TAPOO_DB_USER_NAME")
+			So(c, ShouldResemble, Config{})
+		})
+
+		Convey("with TAPOO_DB_USER_NAME added, a value that implements an error interface should be returned", func() {
+			os.Setenv("TAPOO_DB_USER_NAME", "test_user_tapoo")
+
+			_, err := ConfigFromEnv()
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "envVars: TAPOO_DB_USER_PASSWORD")
+		})
+
+		Convey("with TAPOO_DB_USER_PASSWORD added, a value that implements an error interface should be returned", func() {
+			os.Setenv("TAPOO_DB_USER_PASSWORD", "fake_password")
+
+			_, err := ConfigFromEnv()
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "envVars: TAPOO_DB_HOST")
+		})
+
+		Convey("with all the MySQL environment variables set, no error that should be thrown", func() {
+			os.Setenv("TAPOO_DB_HOST", "localhost:3306")
+
+			c, err := ConfigFromEnv()
+
+			So(err, ShouldBeNil)
+			So(c, ShouldResemble, Config{DbHost: "localhost:3306", DbName: "test_db",
+				DbUserName: "test_user_tapoo", DbUserPassword: "fake_password", Driver: mysqlDriver})
+		})
+
+		Convey("with the SQLite driver set, the default database file should be used if none is set", func() {
+			os.Setenv("TAPOO_DB_DRIVER", sqliteDriver)
+
+			c, err := ConfigFromEnv()
+
+			So(err, ShouldBeNil)
+			So(c, ShouldResemble, Config{DbPath: defaultSQLitePath, Driver: sqliteDriver})
+
+			os.Setenv("TAPOO_DB_PATH", "/tmp/tapoo_test.db")
+
+			c, err = ConfigFromEnv()
+
+			So(err, ShouldBeNil)
+			So(c.DbPath, ShouldEqual, "/tmp/tapoo_test.db")
+		})
+	})
+}
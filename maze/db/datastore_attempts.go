@@ -0,0 +1,416 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// The outcomes of a played level.
+const (
+	// Succeeded is the outcome of a level whose target was located on time.
+	Succeeded = "succeeded"
+
+	// Failed is the outcome of a level whose target was not located on time.
+	Failed = "failed"
+
+	// Quit is the outcome of a level the player quit before it was over.
+	Quit = "quit"
+)
+
+// AllLevels is the level of the attempts history that holds the attempts of every level.
+const AllLevels = -1
+
+// Attempt defines a level played by the user. Seed holds the seed of the maze played,
+// Duration holds the time played and Moves holds the number of cells the player moved
+// through. The attempts recorded before the history was kept only hold the scores.
+// Replay holds how the level was played so that the attempt can be verified and raced
+// against, it is only fetched together with the best attempt of a level.
+type Attempt struct {
+	TapooID   string        `json:"user_id"`
+	Level     int           `json:"game_level"`
+	Seed      int64         `json:"seed"`
+	Outcome   string        `json:"outcome"`
+	Duration  time.Duration `json:"duration"`
+	Moves     int           `json:"moves"`
+	Scores    int           `json:"scores"`
+	CreatedAt time.Time     `json:"created_at"`
+	Replay    *Replay       `json:"replay,omitempty"`
+}
+
+// Replay defines how a level attempt was played. Length and Width hold the terminal
+// size the maze was generated for while Events hold the moves, the hints and the
+// statuses of the level in the order they happened.
+type Replay struct {
+	Length int           `json:"length"`
+	Width  int           `json:"width"`
+	Events []ReplayEvent `json:"events"`
+}
+
+// ReplayEvent defines a single event of a level attempt. Time holds the milliseconds
+// elapsed since the level started while Direction is only set for the moves.
+type ReplayEvent struct {
+	Time      int64  `json:"t"`
+	Kind      string `json:"k"`
+	Direction string `json:"d,omitempty"`
+}
+
+// AttemptsQuery defines the page of the user attempts history requested.
+type AttemptsQuery struct {
+	// TapooID holds the tapoo ID of the user.
+	TapooID string
+
+	// Level holds the game level of the attempts or AllLevels.
+	Level int
+
+	// Limit holds the maximum number of attempts returned.
+	Limit int
+
+	// Offset holds the number of latest attempts skipped.
+	Offset int
+}
+
+// Stats defines the summary of the levels played by a user. WinRate holds the fraction
+// of the attempts that succeeded while AverageDuration holds the average time taken by the
+// succeeded attempts whose duration was recorded.
+type Stats struct {
+	TapooID         string        `json:"user_id"`
+	Attempts        int           `json:"attempts"`
+	Succeeded       int           `json:"succeeded"`
+	WinRate         float64       `json:"win_rate"`
+	AverageDuration time.Duration `json:"average_duration"`
+	Levels          []*LevelStats `json:"levels"`
+}
+
+// LevelStats defines the summary of the attempts of a level. PersonalBests holds the
+// succeeded attempts that beat the best scores before them, the oldest first.
+type LevelStats struct {
+	Level           int           `json:"game_level"`
+	Attempts        int           `json:"attempts"`
+	Succeeded       int           `json:"succeeded"`
+	Failed          int           `json:"failed"`
+	Quit            int           `json:"quit"`
+	WinRate         float64       `json:"win_rate"`
+	AverageDuration time.Duration `json:"average_duration"`
+	BestScores      int           `json:"best_scores"`
+	PersonalBests   []*Attempt    `json:"personal_bests"`
+}
+
+// checkAttempt checks if the attempt provided is valid.
+func (a *Attempt) checkAttempt() error {
+	u := &UserInfor{TapooID: a.TapooID, Level: a.Level}
+
+	if err := u.checkLevel(); err != nil {
+		return err
+	}
+
+	if err := checkHighScores(a.Scores); err != nil {
+		return err
+	}
+
+	if err := u.checkTapooID(); err != nil {
+		return err
+	}
+
+	switch {
+	case a.Outcome != Succeeded && a.Outcome != Failed && a.Outcome != Quit:
+		return &ErrInvalidInput{Field: "outcome", Value: a.Outcome}
+
+	case a.Duration < 0:
+		return &ErrInvalidInput{Field: "duration", Value: a.Duration}
+
+	case a.Moves < 0:
+		return &ErrInvalidInput{Field: "moves", Value: a.Moves}
+	}
+
+	return nil
+}
+
+// checkBestAttempt checks if the level of the best attempt requested is valid. The
+// tapoo ID can be empty or not.
+func (u *UserInfor) checkBestAttempt() error {
+	if len(u.TapooID) > 0 {
+		if err := u.checkTapooID(); err != nil {
+			return err
+		}
+	}
+
+	return u.checkLevel()
+}
+
+// checkQuery checks if the page of the attempts history requested is valid.
+func (q *AttemptsQuery) checkQuery() error {
+	if err := (&UserInfor{TapooID: q.TapooID}).checkTapooID(); err != nil {
+		return err
+	}
+
+	switch {
+	case q.Level < AllLevels:
+		return &ErrInvalidInput{Field: "game level", Value: q.Level}
+
+	case q.Offset < 0:
+		return &ErrInvalidInput{Field: "offset", Value: q.Offset}
+	}
+
+	return checkLimit(q.Limit)
+}
+
+// newStats summarizes the attempts provided, which should be ordered by their time.
+func newStats(tapooID string, attempts []*Attempt) *Stats {
+	var (
+		stats  = &Stats{TapooID: tapooID, Levels: make([]*LevelStats, 0)}
+		levels = make(map[int]*LevelStats)
+
+		// durations and timed hold the total duration and the number of the succeeded
+		// attempts whose duration was recorded, mapped by the level.
+		durations = make(map[int]time.Duration)
+		timed     = make(map[int]int)
+	)
+
+	for _, a := range attempts {
+		l, ok := levels[a.Level]
+		if !ok {
+			l = &LevelStats{Level: a.Level, PersonalBests: make([]*Attempt, 0)}
+			levels[a.Level] = l
+			stats.Levels = append(stats.Levels, l)
+		}
+
+		l.Attempts++
+		stats.Attempts++
+
+		switch a.Outcome {
+		case Failed:
+			l.Failed++
+			continue
+
+		case Quit:
+			l.Quit++
+			continue
+		}
+
+		l.Succeeded++
+		stats.Succeeded++
+
+		if a.Duration > 0 {
+			durations[a.Level] += a.Duration
+			timed[a.Level]++
+		}
+
+		if len(l.PersonalBests) == 0 || a.Scores > l.BestScores {
+			l.BestScores = a.Scores
+			l.PersonalBests = append(l.PersonalBests, a)
+		}
+	}
+
+	var (
+		total time.Duration
+		count int
+	)
+
+	for _, l := range stats.Levels {
+		l.WinRate = float64(l.Succeeded) / float64(l.Attempts)
+
+		if timed[l.Level] > 0 {
+			l.AverageDuration = durations[l.Level] / time.Duration(timed[l.Level])
+		}
+
+		total, count = total+durations[l.Level], count+timed[l.Level]
+	}
+
+	if stats.Attempts > 0 {
+		stats.WinRate = float64(stats.Succeeded) / float64(stats.Attempts)
+	}
+
+	if count > 0 {
+		stats.AverageDuration = total / time.Duration(count)
+	}
+
+	sort.Slice(stats.Levels, func(i, j int) bool { return stats.Levels[i].Level < stats.Levels[j].Level })
+
+	return stats
+}
+
+// recordAttempt records the level attempt provided as completed at the time provided.
+// Nothing is recorded if the user level scores do not exist.
+func (s *sqlStore) recordAttempt(ctx context.Context, a *Attempt, at time.Time) error {
+	u2, err := uuid.NewV4()
+	if err != nil {
+		return errGenUUID
+	}
+
+	var replay sql.NullString
+
+	if a.Replay != nil {
+		data, err := json.Marshal(a.Replay)
+		if err != nil {
+			return err
+		}
+
+		replay = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := `INSERT INTO attempts (uuid, user_id, game_level, seed, outcome, duration, moves, scores, created_at, replay) ` +
+		`SELECT ?, user_id, game_level, ?, ?, ?, ?, ?, ?, ? FROM scores WHERE user_id = ? and game_level = ?;`
+
+	// the times are stored in UTC with a precision of a second so that they can be
+	// compared as text by SQLite while the durations are stored in milliseconds.
+	_, _, err = s.execPrepStmts(ctx, noReturnVal, query, u2.String(), a.Seed, a.Outcome,
+		a.Duration.Milliseconds(), a.Moves, a.Scores, at.UTC().Truncate(time.Second), replay, a.TapooID, a.Level)
+	return err
+}
+
+// SaveAttempt records the level attempt provided as completed now, creating the user
+// level scores first if they don't exist. The scores of a succeeded attempt replace the
+// stored high scores only if they are higher. The level scores stored afterwards are returned.
+func (s *sqlStore) SaveAttempt(ctx context.Context, a *Attempt) (*LevelScoreResponse, error) {
+	if err := a.checkAttempt(); err != nil {
+		return nil, err
+	}
+
+	var (
+		d *LevelScoreResponse
+		u = &UserInfor{TapooID: a.TapooID, Level: a.Level}
+	)
+
+	err := s.withTx(ctx, func(t *sqlStore) (err error) {
+		if _, err = t.getOrCreateLevelScore(ctx, u); err != nil {
+			return err
+		}
+
+		if a.Outcome == Succeeded {
+			if err = t.saveHighScores(ctx, u, a.Scores); err != nil {
+				return err
+			}
+		}
+
+		if err = t.recordAttempt(ctx, a, time.Now()); err != nil {
+			return err
+		}
+
+		d, err = t.getLevelScore(ctx, u)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// GetAttempts fetches the page of the user attempts history requested, the latest first.
+func (s *sqlStore) GetAttempts(ctx context.Context, q *AttemptsQuery) ([]*Attempt, error) {
+	if err := q.checkQuery(); err != nil {
+		return []*Attempt{}, err
+	}
+
+	query, args := `WHERE user_id = ?`, []interface{}{q.TapooID}
+	if q.Level != AllLevels {
+		query, args = query+` and game_level = ?`, append(args, q.Level)
+	}
+
+	query += ` ORDER BY created_at DESC, uuid DESC LIMIT ? OFFSET ?;`
+
+	return s.getAttempts(ctx, query, append(args, q.Limit, q.Offset)...)
+}
+
+// GetStats fetches the summary of the levels played by the user. ErrNotFound is returned
+// if the user does not exist.
+func (s *sqlStore) GetStats(ctx context.Context, u *UserInfor) (*Stats, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
+
+	var attempts []*Attempt
+
+	err := s.withTx(ctx, func(t *sqlStore) (err error) {
+		if _, err = t.getUser(ctx, u); err != nil {
+			return err
+		}
+
+		attempts, err = t.getAttempts(ctx, `WHERE user_id = ? ORDER BY created_at, uuid;`, u.TapooID)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newStats(u.TapooID, attempts), nil
+}
+
+// GetBestAttempt fetches the succeeded attempt with the highest scores on the level
+// provided among the attempts whose replay was recorded. The attempts of every user are
+// considered if the tapoo ID is empty. ErrNotFound is returned if no such attempt exists.
+func (s *sqlStore) GetBestAttempt(ctx context.Context, u *UserInfor) (*Attempt, error) {
+	if err := u.checkBestAttempt(); err != nil {
+		return nil, err
+	}
+
+	query, args := `WHERE game_level = ? and outcome = ? and replay IS NOT NULL`, []interface{}{u.Level, Succeeded}
+	if len(u.TapooID) > 0 {
+		query, args = query+` and user_id = ?`, append(args, u.TapooID)
+	}
+
+	query = `SELECT user_id, game_level, seed, outcome, duration, moves, scores, created_at, replay FROM attempts ` +
+		query + ` ORDER BY scores DESC, created_at, uuid LIMIT 1;`
+
+	_, row, err := s.execPrepStmts(ctx, singleRow, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		d        = new(Attempt)
+		duration int64
+		replay   string
+	)
+
+	err = row.Scan(&d.TapooID, &d.Level, &d.Seed, &d.Outcome, &duration, &d.Moves, &d.Scores, &d.CreatedAt, &replay)
+	if err != nil {
+		return nil, mapDriverError(err)
+	}
+
+	d.Duration = time.Duration(duration) * time.Millisecond
+
+	if err = json.Unmarshal([]byte(replay), &d.Replay); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// getAttempts fetches the attempts matching the conditions provided.
+func (s *sqlStore) getAttempts(ctx context.Context, conditions string, args ...interface{}) ([]*Attempt, error) {
+	attempts := make([]*Attempt, 0)
+
+	query := `SELECT user_id, game_level, seed, outcome, duration, moves, scores, created_at FROM attempts ` + conditions
+
+	rows, _, err := s.execPrepStmts(ctx, multiRows, query, args...)
+	if err != nil {
+		return attempts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d        = new(Attempt)
+			duration int64
+		)
+
+		err = rows.Scan(&d.TapooID, &d.Level, &d.Seed, &d.Outcome, &duration, &d.Moves, &d.Scores, &d.CreatedAt)
+		if err != nil {
+			return attempts, err
+		}
+
+		d.Duration = time.Duration(duration) * time.Millisecond
+		attempts = append(attempts, d)
+	}
+
+	return attempts, rows.Err()
+}
@@ -0,0 +1,326 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestSaveAttempt tests the functionality of SaveAttempt
+func TestSaveAttempt(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestSaveAttempt: Given the "+driver+" store to record the attempt with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			ctx := context.Background()
+
+			Convey("an invalid attempt, a value that implements an error interface should be returned", func() {
+				for _, item := range []struct {
+					attempt *Attempt
+					msg     string
+				}{
+					{&Attempt{TapooID: "Vf2TqN5MB", Level: -1, Outcome: Failed}, "invalid game level found : '-1'"},
+					{&Attempt{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Succeeded, Scores: -3}, "invalid high scores found : '-3'"},
+					{&Attempt{Level: 2, Outcome: Failed}, "invalid Tapoo ID found : '(empty)'"},
+					{&Attempt{TapooID: "Vf2TqN5MB", Level: 2, Outcome: "won"}, "invalid outcome found : 'won'"},
+					{&Attempt{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Quit, Duration: -time.Second}, "invalid duration found : '-1s'"},
+					{&Attempt{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Quit, Moves: -4}, "invalid moves found : '-4'"},
+				} {
+					data, err := store.SaveAttempt(ctx, item.attempt)
+
+					So(data, ShouldBeNil)
+					So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+					So(err.Error(), ShouldContainSubstring, item.msg)
+				}
+			})
+
+			Convey("a user that does not exist, ErrNotFound should be returned", func() {
+				_, err := store.SaveAttempt(ctx, &Attempt{TapooID: "fake_tapoo_id", Level: 2, Outcome: Failed})
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+
+			Convey("valid attempts, only the succeeded attempts should update the high scores", func() {
+				data, err := store.SaveAttempt(ctx, &Attempt{TapooID: "VZWeOq2p", Level: 25, Seed: 42,
+					Outcome: Failed, Duration: 90 * time.Second, Moves: 120})
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 0)
+
+				data, err = store.SaveAttempt(ctx, &Attempt{TapooID: "VZWeOq2p", Level: 25, Seed: 42,
+					Outcome: Quit, Duration: 10 * time.Second, Moves: 12, Scores: 4000})
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 0)
+
+				level, err := store.GetHighestClearedLevel(ctx, &UserInfor{TapooID: "VZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, 19)
+
+				data, err = store.SaveAttempt(ctx, &Attempt{TapooID: "VZWeOq2p", Level: 25, Seed: 43,
+					Outcome: Succeeded, Duration: 1500 * time.Millisecond, Moves: 40, Scores: 800})
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 800)
+
+				level, err = store.GetHighestClearedLevel(ctx, &UserInfor{TapooID: "VZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, 25)
+
+				// a level is cleared even if the hint penalties left no scores.
+				data, err = store.SaveAttempt(ctx, &Attempt{TapooID: "VZWeOq2p", Level: 26, Seed: 43,
+					Outcome: Succeeded, Duration: 40 * time.Second, Moves: 52})
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 0)
+
+				level, err = store.GetHighestClearedLevel(ctx, &UserInfor{TapooID: "VZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, 26)
+
+				attempts, err := store.GetAttempts(ctx, &AttemptsQuery{TapooID: "VZWeOq2p", Level: 25, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(attempts, ShouldHaveLength, 3)
+
+				outcomes := []string{attempts[0].Outcome, attempts[1].Outcome, attempts[2].Outcome}
+				So(outcomes, ShouldContain, Failed)
+				So(outcomes, ShouldContain, Quit)
+				So(outcomes, ShouldContain, Succeeded)
+
+				for _, a := range attempts {
+					if a.Outcome == Succeeded {
+						So(a.Seed, ShouldEqual, 43)
+						So(a.Duration, ShouldEqual, 1500*time.Millisecond)
+						So(a.Moves, ShouldEqual, 40)
+						So(a.Scores, ShouldEqual, 800)
+						So(a.CreatedAt.IsZero(), ShouldBeFalse)
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestGetAttempts tests the functionality of GetAttempts
+func TestGetAttempts(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetAttempts: Given the "+driver+" store to fetch the attempts history with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			now := time.Date(2026, time.March, 11, 15, 0, 0, 0, time.UTC)
+			addSampleAttempts(store, now)
+
+			Convey("an invalid query, a value that implements an error interface should be returned", func() {
+				for _, item := range []struct {
+					query *AttemptsQuery
+					msg   string
+				}{
+					{&AttemptsQuery{Level: 2, Limit: 5}, "invalid Tapoo ID found : '(empty)'"},
+					{&AttemptsQuery{TapooID: "Vf2TqN5MB", Level: -2, Limit: 5}, "invalid game level found : '-2'"},
+					{&AttemptsQuery{TapooID: "Vf2TqN5MB", Level: 2}, "invalid limit found : '0'"},
+					{&AttemptsQuery{TapooID: "Vf2TqN5MB", Level: 2, Limit: 5, Offset: -1}, "invalid offset found : '-1'"},
+				} {
+					data, err := store.GetAttempts(context.Background(), item.query)
+
+					So(data, ShouldHaveLength, 0)
+					So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+					So(err.Error(), ShouldContainSubstring, item.msg)
+				}
+			})
+
+			Convey("a valid query, the latest attempts should be returned first", func() {
+				data, err := store.GetAttempts(context.Background(), &AttemptsQuery{TapooID: "GzlWAL0mP", Level: AllLevels, Limit: 2})
+
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 2)
+				So(data[0].CreatedAt.Equal(now.Add(-30*time.Minute)), ShouldBeTrue)
+				So(data[1].Level, ShouldEqual, 18)
+
+				data, err = store.GetAttempts(context.Background(), &AttemptsQuery{TapooID: "GzlWAL0mP", Level: 2, Limit: 2, Offset: 1})
+
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 1)
+				So(data[0].CreatedAt.Equal(now.Add(-2*time.Hour)), ShouldBeTrue)
+
+				data, err = store.GetAttempts(context.Background(), &AttemptsQuery{TapooID: "VZWeOq2p", Level: AllLevels, Limit: 2})
+
+				So(err, ShouldBeNil)
+				So(data, ShouldBeEmpty)
+			})
+		})
+	}
+}
+
+// TestGetBestAttempt tests the functionality of GetBestAttempt
+func TestGetBestAttempt(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetBestAttempt: Given the "+driver+" store to fetch the best attempt with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			var (
+				ctx    = context.Background()
+				replay = &Replay{Length: 40, Width: 20, Events: []ReplayEvent{{Time: 500, Kind: "move",
+					Direction: "LEFT"}, {Time: 500, Kind: "succeeded"}}}
+			)
+
+			for _, a := range []*Attempt{
+				{TapooID: "Vf2TqN5MB", Level: 3, Seed: 7, Outcome: Succeeded, Scores: 800, Replay: replay},
+				{TapooID: "Vf2TqN5MB", Level: 3, Seed: 8, Outcome: Failed, Replay: replay},
+				{TapooID: "VZWeOq2p", Level: 3, Seed: 9, Outcome: Succeeded, Scores: 1200, Replay: replay},
+				{TapooID: "VZWeOq2p", Level: 3, Seed: 10, Outcome: Succeeded, Scores: 1500},
+				{TapooID: "VZWeOq2p", Level: 4, Seed: 11, Outcome: Succeeded, Scores: 2000, Replay: replay},
+			} {
+				_, err = store.SaveAttempt(ctx, a)
+				So(err, ShouldBeNil)
+			}
+
+			Convey("an invalid level, a value that implements an error interface should be returned", func() {
+				data, err := store.GetBestAttempt(ctx, &UserInfor{Level: -1})
+
+				So(data, ShouldBeNil)
+				So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+				So(err.Error(), ShouldContainSubstring, "invalid game level found : '-1'")
+			})
+
+			Convey("a level without replayed attempts, ErrNotFound should be returned", func() {
+				_, err := store.GetBestAttempt(ctx, &UserInfor{TapooID: "Vf2TqN5MB", Level: 4})
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+
+				_, err = store.GetBestAttempt(ctx, &UserInfor{Level: 5})
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+
+			Convey("a user, the best attempt of the user should be returned with its replay", func() {
+				data, err := store.GetBestAttempt(ctx, &UserInfor{TapooID: "Vf2TqN5MB", Level: 3})
+
+				So(err, ShouldBeNil)
+				So(data.Seed, ShouldEqual, 7)
+				So(data.Scores, ShouldEqual, 800)
+				So(data.Replay, ShouldResemble, replay)
+			})
+
+			Convey("no user, the best attempt of the level leader should be returned", func() {
+				data, err := store.GetBestAttempt(ctx, &UserInfor{Level: 3})
+
+				So(err, ShouldBeNil)
+				So(data.TapooID, ShouldEqual, "VZWeOq2p")
+				So(data.Scores, ShouldEqual, 1200)
+				So(data.Replay, ShouldResemble, replay)
+			})
+
+			Convey("the attempts history should not hold the replays", func() {
+				data, err := store.GetAttempts(ctx, &AttemptsQuery{TapooID: "Vf2TqN5MB", Level: 3, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 2)
+				So(data[0].Replay, ShouldBeNil)
+				So(data[1].Replay, ShouldBeNil)
+			})
+		})
+	}
+}
+
+// TestGetStats tests the functionality of GetStats
+func TestGetStats(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetStats: Given the "+driver+" store to fetch the user stats with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			now := time.Date(2026, time.March, 11, 15, 0, 0, 0, time.UTC)
+			addSampleAttempts(store, now)
+
+			Convey("a user that does not exist, ErrNotFound should be returned", func() {
+				_, err := store.GetStats(context.Background(), &UserInfor{TapooID: "fake_tapoo_id"})
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+
+				_, err = store.GetStats(context.Background(), &UserInfor{})
+
+				So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+			})
+
+			Convey("a user that has played, the attempts should be summarized", func() {
+				stats, err := store.GetStats(context.Background(), &UserInfor{TapooID: "Vf2TqN5MB"})
+
+				So(err, ShouldBeNil)
+				So(stats.Attempts, ShouldEqual, 3)
+				So(stats.Succeeded, ShouldEqual, 2)
+				So(stats.Levels, ShouldHaveLength, 1)
+				So(stats.Levels[0].Quit, ShouldEqual, 1)
+				So(stats.Levels[0].BestScores, ShouldEqual, 900)
+				So(stats.Levels[0].PersonalBests, ShouldHaveLength, 1)
+			})
+		})
+	}
+}
+
+// TestNewStats tests the functionality of newStats
+func TestNewStats(t *testing.T) {
+	Convey("TestNewStats: Given the attempts of a user ordered by their time", t, func() {
+		now := time.Now()
+
+		attempts := []*Attempt{
+			{Level: 3, Outcome: Failed, Duration: 200 * time.Second, CreatedAt: now.Add(-6 * time.Hour)},
+			{Level: 3, Outcome: Succeeded, Duration: 120 * time.Second, Scores: 600, CreatedAt: now.Add(-5 * time.Hour)},
+			{Level: 1, Outcome: Succeeded, Scores: 1400, CreatedAt: now.Add(-4 * time.Hour)},
+			{Level: 3, Outcome: Succeeded, Duration: 60 * time.Second, Scores: 1200, CreatedAt: now.Add(-3 * time.Hour)},
+			{Level: 3, Outcome: Quit, Duration: 10 * time.Second, CreatedAt: now.Add(-2 * time.Hour)},
+			{Level: 3, Outcome: Succeeded, Duration: 90 * time.Second, Scores: 900, CreatedAt: now.Add(-time.Hour)},
+		}
+
+		stats := newStats("Vf2TqN5MB", attempts)
+
+		Convey("the totals should include every level", func() {
+			So(stats.TapooID, ShouldEqual, "Vf2TqN5MB")
+			So(stats.Attempts, ShouldEqual, 6)
+			So(stats.Succeeded, ShouldEqual, 4)
+			So(stats.WinRate, ShouldAlmostEqual, 4.0/6.0)
+			So(stats.AverageDuration, ShouldEqual, 90*time.Second)
+		})
+
+		Convey("the levels should be ordered and summarized separately", func() {
+			So(stats.Levels, ShouldHaveLength, 2)
+			So(stats.Levels[0].Level, ShouldEqual, 1)
+			So(stats.Levels[0].WinRate, ShouldEqual, 1)
+			So(stats.Levels[0].AverageDuration, ShouldEqual, 0)
+
+			l := stats.Levels[1]
+			So(l.Level, ShouldEqual, 3)
+			So(l.Attempts, ShouldEqual, 5)
+			So(l.Failed, ShouldEqual, 1)
+			So(l.Quit, ShouldEqual, 1)
+			So(l.WinRate, ShouldAlmostEqual, 0.6)
+			So(l.BestScores, ShouldEqual, 1200)
+			So(l.PersonalBests, ShouldResemble, []*Attempt{attempts[1], attempts[3]})
+		})
+
+		Convey("no attempts should return empty stats", func() {
+			stats := newStats("Vf2TqN5MB", nil)
+
+			So(stats.Attempts, ShouldEqual, 0)
+			So(stats.WinRate, ShouldEqual, 0)
+			So(stats.Levels, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,320 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// GlobalLeaderboard is the level of the leaderboard that aggregates the high scores of
+// every level.
+const GlobalLeaderboard = -1
+
+// The periods a leaderboard can be limited to. Days and weeks start at midnight UTC and
+// weeks start on Monday.
+const (
+	AllTime = "all-time"
+	Daily   = "daily"
+	Weekly  = "weekly"
+)
+
+// LeaderboardQuery defines the page of the leaderboard requested.
+type LeaderboardQuery struct {
+	// Level holds the game level of the leaderboard or GlobalLeaderboard.
+	Level int
+
+	// Limit holds the maximum number of entries returned.
+	Limit int
+
+	// Offset holds the number of top entries skipped.
+	Offset int
+
+	// From and To limit the leaderboard to the succeeded attempts completed from From and before To.
+	// The bounds that are zero are ignored, if both are zero the all-time high scores are used.
+	From, To time.Time
+}
+
+// LeaderboardEntry defines the ranked high scores of a user on a level the user has cleared.
+// On the global leaderboard
+// HighScores holds the sum of the user high scores on every level, Level holds the highest
+// level the user has cleared and UpdateAt holds when the user last saved a high score.
+type LeaderboardEntry struct {
+	Rank       int       `json:"rank"`
+	TapooID    string    `json:"user_id"`
+	Level      int       `json:"game_level"`
+	HighScores int       `json:"high_scores"`
+	UpdateAt   time.Time `json:"updated_at"`
+}
+
+// The entries on every leaderboard are ordered by the high scores in descending order,
+// the ties are ordered by the earliest update and then by the tapoo ID.
+const (
+	// clearedScores matches the level scores s of the levels cleared the same way
+	// GetHighestClearedLevel does. It excludes the level scores created before the level was
+	// ever completed.
+	clearedScores = `(s.high_scores > 0 OR EXISTS (SELECT 1 FROM attempts c WHERE c.user_id = s.user_id ` +
+		`and c.game_level = s.game_level and c.outcome = ?))`
+
+	// levelScores selects the all-time high scores of every user level cleared.
+	levelScores = `SELECT user_id, game_level, high_scores, updated_at FROM scores s WHERE ` + clearedScores
+
+	// attemptScores selects the best attempt of every user level among the attempts matching
+	// the conditions provided. updated_at holds when the best attempt was first completed.
+	// Only the succeeded attempts are expected to match the conditions.
+	attemptScores = `SELECT a.user_id, a.game_level, a.scores AS high_scores, MIN(a.created_at) AS updated_at ` +
+		`FROM attempts a WHERE %s and a.scores = (SELECT MAX(b.scores) FROM attempts b WHERE b.user_id = a.user_id ` +
+		`and b.game_level = a.game_level and %s) GROUP BY a.user_id, a.game_level, a.scores`
+
+	// globalScores aggregates the level scores provided of every user across all the levels.
+	// Only the cleared levels are expected to be provided.
+	globalScores = `SELECT user_id, MAX(game_level) AS game_level, SUM(high_scores) AS high_scores, ` +
+		`MAX(updated_at) AS updated_at FROM (%s) l GROUP BY user_id`
+
+	// rankedBefore matches the entries s ranked before the entry me.
+	rankedBefore = `s.high_scores > me.high_scores OR (s.high_scores = me.high_scores and ` +
+		`(s.updated_at < me.updated_at OR (s.updated_at = me.updated_at and s.user_id < me.user_id)))`
+
+	leaderboardOrder = ` ORDER BY high_scores DESC, updated_at, user_id LIMIT ? OFFSET ?;`
+)
+
+// SetPeriod limits the leaderboard to the period provided that includes the time now.
+func (q *LeaderboardQuery) SetPeriod(period string, now time.Time) error {
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	switch period {
+	case AllTime:
+		q.From, q.To = time.Time{}, time.Time{}
+
+	case Daily:
+		q.From, q.To = day, day.AddDate(0, 0, 1)
+
+	case Weekly:
+		// time.Sunday is zero thus the days since Monday are shifted by six days.
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		q.From, q.To = monday, monday.AddDate(0, 0, 7)
+
+	default:
+		return &ErrInvalidInput{Field: "period", Value: period}
+	}
+
+	return nil
+}
+
+// SetSeason limits the leaderboard to the season provided.
+func (q *LeaderboardQuery) SetSeason(s *Season) {
+	q.From, q.To = s.StartsAt, s.EndsAt
+}
+
+// checkQuery checks if the leaderboard page requested is valid.
+func (q *LeaderboardQuery) checkQuery() error {
+	switch {
+	case q.Level < GlobalLeaderboard:
+		return &ErrInvalidInput{Field: "game level", Value: q.Level}
+
+	case q.Offset < 0:
+		return &ErrInvalidInput{Field: "offset", Value: q.Offset}
+
+	case !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From):
+		return &ErrInvalidInput{Field: "period", Value: q.From.Format(time.RFC3339) + " - " + q.To.Format(time.RFC3339)}
+	}
+
+	return checkLimit(q.Limit)
+}
+
+// isAllTime checks if the leaderboard is not limited to a period.
+func (q *LeaderboardQuery) isAllTime() bool {
+	return q.From.IsZero() && q.To.IsZero()
+}
+
+// getScoresTable returns the query that selects the level scores ranked on the leaderboard
+// together with its arguments.
+func (q *LeaderboardQuery) getScoresTable() (string, []interface{}) {
+	var (
+		query string
+		args  []interface{}
+	)
+
+	switch {
+	case q.isAllTime() && q.Level == GlobalLeaderboard:
+		query, args = levelScores, []interface{}{Succeeded}
+
+	case q.isAllTime():
+		query, args = levelScores+` and s.game_level = ?`, []interface{}{Succeeded, q.Level}
+
+	default:
+		outer, outerArgs := q.getPeriodConditions("a")
+		inner, innerArgs := q.getPeriodConditions("b")
+
+		if q.Level != GlobalLeaderboard {
+			outer, outerArgs = "a.game_level = ? and "+outer, append([]interface{}{q.Level}, outerArgs...)
+		}
+
+		query, args = fmt.Sprintf(attemptScores, outer, inner), append(outerArgs, innerArgs...)
+	}
+
+	if q.Level == GlobalLeaderboard {
+		query = fmt.Sprintf(globalScores, query)
+	}
+
+	return query, args
+}
+
+// getPeriodConditions returns the conditions that limit the attempts with the alias provided
+// to the succeeded attempts of the leaderboard period together with their arguments.
+func (q *LeaderboardQuery) getPeriodConditions(alias string) (string, []interface{}) {
+	var (
+		conditions = []string{alias + ".outcome = ?"}
+		args       = []interface{}{Succeeded}
+	)
+
+	// the times are stored in UTC with a precision of a second.
+	if !q.From.IsZero() {
+		conditions = append(conditions, alias+".created_at >= ?")
+		args = append(args, q.From.UTC().Truncate(time.Second))
+	}
+
+	if !q.To.IsZero() {
+		conditions = append(conditions, alias+".created_at < ?")
+		args = append(args, q.To.UTC().Truncate(time.Second))
+	}
+
+	return strings.Join(conditions, " and "), args
+}
+
+// getNeighborsQuery returns the page of the leaderboard provided holding the entry ranked
+// rank together with at most neighbors entries ranked above and below it.
+func getNeighborsQuery(q *LeaderboardQuery, rank, neighbors int) *LeaderboardQuery {
+	offset := rank - 1 - neighbors
+	if offset < 0 {
+		offset = 0
+	}
+
+	return &LeaderboardQuery{Level: q.Level, Offset: offset, Limit: rank - offset + neighbors, From: q.From, To: q.To}
+}
+
+// GetLeaderboard fetches the leaderboard page requested.
+func (s *sqlStore) GetLeaderboard(ctx context.Context, q *LeaderboardQuery) ([]*LeaderboardEntry, error) {
+	if err := q.checkQuery(); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	return s.getLeaderboard(ctx, q)
+}
+
+// getLeaderboard fetches the leaderboard page requested. The query should be valid.
+func (s *sqlStore) getLeaderboard(ctx context.Context, q *LeaderboardQuery) ([]*LeaderboardEntry, error) {
+	entries := make([]*LeaderboardEntry, 0)
+
+	table, args := q.getScoresTable()
+	query := `SELECT user_id, game_level, high_scores, updated_at FROM (` + table + `) t` + leaderboardOrder
+
+	rows, _, err := s.execPrepStmts(ctx, multiRows, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return entries, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d        = &LeaderboardEntry{Rank: q.Offset + len(entries) + 1}
+			updateAt timestamp
+		)
+
+		if err = rows.Scan(&d.TapooID, &d.Level, &d.HighScores, &updateAt); err != nil {
+			return entries, err
+		}
+
+		d.UpdateAt = updateAt.Time
+		entries = append(entries, d)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetUserRank fetches the entry of the user on the leaderboard together with at most
+// neighbors entries ranked above and below it.
+func (s *sqlStore) GetUserRank(ctx context.Context, u *UserInfor, q *LeaderboardQuery, neighbors int) ([]*LeaderboardEntry, error) {
+	if err := u.checkTapooID(); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	if err := checkNeighbors(q, neighbors); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	var entries []*LeaderboardEntry
+
+	err := s.withTx(ctx, func(t *sqlStore) error {
+		table, args := q.getScoresTable()
+
+		query := `SELECT (SELECT COUNT(*) FROM (` + table + `) s WHERE ` + rankedBefore +
+			`) + 1 FROM (` + table + `) me WHERE me.user_id = ?;`
+
+		_, row, err := t.execPrepStmts(ctx, singleRow, query, append(append(args, args...), u.TapooID)...)
+		if err != nil {
+			return err
+		}
+
+		var rank int
+		if err = row.Scan(&rank); err != nil {
+			return mapDriverError(err)
+		}
+
+		entries, err = t.getLeaderboard(ctx, getNeighborsQuery(q, rank, neighbors))
+		return err
+	})
+
+	if err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	return entries, nil
+}
+
+// checkNeighbors checks if the leaderboard and the number of neighbors requested are valid.
+func checkNeighbors(q *LeaderboardQuery, neighbors int) error {
+	if neighbors < 0 {
+		return &ErrInvalidInput{Field: "neighbors", Value: neighbors}
+	}
+
+	return (&LeaderboardQuery{Level: q.Level, Limit: 1, From: q.From, To: q.To}).checkQuery()
+}
+
+// timestamp scans the timestamps returned by the aggregate functions. Unlike the timestamp
+// columns, SQLite returns them as text since their type cannot be known.
+type timestamp struct {
+	time.Time
+}
+
+// Scan implements the sql.Scanner interface.
+func (t *timestamp) Scan(value interface{}) error {
+	var text string
+
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+
+	case []byte:
+		text = string(v)
+
+	case string:
+		text = v
+
+	default:
+		return fmt.Errorf("datastore: unsupported timestamp %T", value)
+	}
+
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if parsed, err := time.ParseInLocation(format, text, time.UTC); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+
+	return fmt.Errorf("datastore: invalid timestamp %q", text)
+}
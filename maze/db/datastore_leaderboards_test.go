@@ -0,0 +1,376 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// setUpdateTime sets the time the user level scores were last updated.
+func setUpdateTime(store Store, tapooID string, level int, updateAt time.Time) error {
+	switch s := store.(type) {
+	case *sqlStore:
+		query := `UPDATE scores SET updated_at = ? WHERE user_id = ? and game_level = ?;`
+		_, _, err := s.execPrepStmts(context.Background(), noReturnVal, query,
+			updateAt.UTC().Format("2006-01-02 15:04:05"), tapooID, level)
+		return err
+
+	case *memoryStore:
+		s.scores[tapooID][level].UpdateAt = updateAt
+	}
+
+	return nil
+}
+
+// addAttempt records the level attempt provided as completed at its CreatedAt time.
+func addAttempt(store Store, a *Attempt) error {
+	switch s := store.(type) {
+	case *sqlStore:
+		return s.recordAttempt(context.Background(), a, a.CreatedAt)
+
+	case *memoryStore:
+		s.attempts = append(s.attempts, a)
+	}
+
+	return nil
+}
+
+// addSampleAttempts records the sample attempts completed during the week of the time now
+// provided, which is expected to be a Wednesday afternoon.
+func addSampleAttempts(store Store, now time.Time) {
+	for _, a := range []*Attempt{
+		{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Succeeded, Scores: 300, CreatedAt: now.Add(-time.Hour)},
+		{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Succeeded, Scores: 900, CreatedAt: now.AddDate(0, 0, -3)},
+		{TapooID: "Vf2TqN5MB", Level: 2, Outcome: Quit, Scores: 2500, CreatedAt: now.Add(-time.Minute)},
+		{TapooID: "GzlWAL0mP", Level: 2, Outcome: Succeeded, Scores: 500, CreatedAt: now.Add(-30 * time.Minute)},
+		{TapooID: "GzlWAL0mP", Level: 2, Outcome: Succeeded, Scores: 500, CreatedAt: now.Add(-2 * time.Hour)},
+		{TapooID: "GzlWAL0mP", Level: 18, Outcome: Succeeded, Scores: 100, CreatedAt: now.Add(-time.Hour)},
+		{TapooID: "FANVZWeOq2p", Level: 2, Outcome: Succeeded, Scores: 700, CreatedAt: now.AddDate(0, 0, -2)},
+		{TapooID: "FbnnuznkFAN", Level: 2, Outcome: Failed, CreatedAt: now.Add(-time.Hour)},
+	} {
+		So(addAttempt(store, a), ShouldBeNil)
+	}
+}
+
+// getRankedIDs returns the tapoo IDs of the leaderboard entries provided and checks
+// that the entries are ranked consecutively from the first rank provided.
+func getRankedIDs(entries []*LeaderboardEntry, firstRank int) []string {
+	ids := make([]string, 0, len(entries))
+
+	for i, entry := range entries {
+		So(entry.Rank, ShouldEqual, firstRank+i)
+		ids = append(ids, entry.TapooID)
+	}
+
+	return ids
+}
+
+// TestGetLeaderboard tests the functionality of GetLeaderboard
+func TestGetLeaderboard(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetLeaderboard: Given the "+driver+" store to fetch the leaderboard with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			Convey("an invalid query, a value that implements an error interface should be returned", func() {
+				for _, item := range []struct {
+					query *LeaderboardQuery
+					msg   string
+				}{
+					{&LeaderboardQuery{Level: -2, Limit: 5}, "invalid game level found : '-2'"},
+					{&LeaderboardQuery{Level: 2, Limit: 0}, "invalid limit found : '0'"},
+					{&LeaderboardQuery{Level: 2, Limit: 5, Offset: -1}, "invalid offset found : '-1'"},
+				} {
+					data, err := store.GetLeaderboard(context.Background(), item.query)
+
+					So(data, ShouldHaveLength, 0)
+					So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+					So(err.Error(), ShouldContainSubstring, item.msg)
+				}
+			})
+
+			Convey("a level, the level high scores should be ranked and paginated", func() {
+				data, err := store.GetLeaderboard(context.Background(), &LeaderboardQuery{Level: 2, Limit: 4})
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB", "FANVZWeOq2p", "FbnnuznkFAN"})
+				So(data[0].HighScores, ShouldEqual, 1948)
+				So(data[0].Level, ShouldEqual, 2)
+				So(data[0].UpdateAt.IsZero(), ShouldBeFalse)
+
+				data, err = store.GetLeaderboard(context.Background(), &LeaderboardQuery{Level: 2, Limit: 4, Offset: 4})
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 5), ShouldResemble, []string{"Fbn56nuznk", "06PE0LPzyCL"})
+
+				data, err = store.GetLeaderboard(context.Background(), &LeaderboardQuery{Level: 2, Limit: 4, Offset: 10})
+
+				So(err, ShouldBeNil)
+				So(data, ShouldBeEmpty)
+			})
+
+			Convey("the global level, the sum of the high scores on every level should be ranked", func() {
+				data, err := store.GetLeaderboard(context.Background(), &LeaderboardQuery{Level: GlobalLeaderboard, Limit: 10})
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB", "FbnnuznkFAN",
+					"VZWeOq2p", "06PE0LPzyCL", "FANVZWeOq2p", "Fbn56nuznk"})
+
+				So(data[0].HighScores, ShouldEqual, 13719)
+				So(data[0].Level, ShouldEqual, 18)
+				So(data[0].UpdateAt.IsZero(), ShouldBeFalse)
+				So(data[6].HighScores, ShouldEqual, 1027)
+				So(data[6].Level, ShouldEqual, 2)
+			})
+
+			Convey("a period, the best attempts completed in the period should be ranked", func() {
+				ctx := context.Background()
+				now := time.Date(2026, time.March, 11, 15, 0, 0, 0, time.UTC)
+
+				addSampleAttempts(store, now)
+
+				q := &LeaderboardQuery{Level: 2, Limit: 5}
+				So(q.SetPeriod(Daily, now), ShouldBeNil)
+
+				data, err := store.GetLeaderboard(ctx, q)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB"})
+				So(data[0].HighScores, ShouldEqual, 500)
+				So(data[0].UpdateAt.Equal(now.Add(-2*time.Hour)), ShouldBeTrue)
+
+				So(q.SetPeriod(Weekly, now), ShouldBeNil)
+
+				data, err = store.GetLeaderboard(ctx, q)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"FANVZWeOq2p", "GzlWAL0mP", "Vf2TqN5MB"})
+				So(data[2].HighScores, ShouldEqual, 300)
+
+				q.SetSeason(&Season{Name: "Spring", StartsAt: now.AddDate(0, 0, -4), EndsAt: now.AddDate(0, 0, -2)})
+
+				data, err = store.GetLeaderboard(ctx, q)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"Vf2TqN5MB"})
+				So(data[0].HighScores, ShouldEqual, 900)
+
+				q = &LeaderboardQuery{Level: GlobalLeaderboard, Limit: 5}
+				So(q.SetPeriod(Daily, now), ShouldBeNil)
+
+				data, err = store.GetLeaderboard(ctx, q)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB"})
+				So(data[0].HighScores, ShouldEqual, 600)
+				So(data[0].Level, ShouldEqual, 18)
+
+				_, err = store.GetLeaderboard(ctx, &LeaderboardQuery{Level: 2, Limit: 5, From: now, To: now})
+
+				So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+				So(err.Error(), ShouldContainSubstring, "invalid period found")
+			})
+
+			Convey("levels cleared without scores left, only the cleared levels should be ranked", func() {
+				ctx := context.Background()
+
+				for _, id := range []string{"clearedTapoo", "startedTapoo"} {
+					_, err := store.GetOrCreateUser(ctx, &UserInfor{TapooID: id})
+					So(err, ShouldBeNil)
+				}
+
+				_, err := store.SaveAttempt(ctx, &Attempt{TapooID: "clearedTapoo", Level: 40, Outcome: Succeeded})
+				So(err, ShouldBeNil)
+
+				_, err = store.GetOrCreateLevelScore(ctx, &UserInfor{TapooID: "startedTapoo", Level: 40})
+				So(err, ShouldBeNil)
+
+				data, err := store.GetLeaderboard(ctx, &LeaderboardQuery{Level: 40, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"clearedTapoo"})
+
+				topScores, err := store.GetTopScores(ctx, &UserInfor{Level: 40}, 5)
+
+				So(err, ShouldBeNil)
+				So(topScores, ShouldHaveLength, 1)
+				So(topScores[0].TapooID, ShouldEqual, "clearedTapoo")
+
+				data, err = store.GetUserRank(ctx, &UserInfor{TapooID: "clearedTapoo"},
+					&LeaderboardQuery{Level: GlobalLeaderboard}, 0)
+
+				So(err, ShouldBeNil)
+				So(data[0].Level, ShouldEqual, 40)
+
+				level, err := store.GetHighestClearedLevel(ctx, &UserInfor{TapooID: "clearedTapoo"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, data[0].Level)
+
+				_, err = store.GetUserRank(ctx, &UserInfor{TapooID: "startedTapoo"}, &LeaderboardQuery{Level: 40}, 0)
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+
+			Convey("equal high scores, the earliest update should be ranked first", func() {
+				ctx := context.Background()
+				now := time.Now().Truncate(time.Second)
+
+				for _, id := range []string{"zzTapoo", "aaTapoo", "mmTapoo"} {
+					_, err := store.GetOrCreateUser(ctx, &UserInfor{TapooID: id})
+					So(err, ShouldBeNil)
+
+					_, err = store.SaveHighScores(ctx, &UserInfor{TapooID: id, Level: 30}, 500)
+					So(err, ShouldBeNil)
+				}
+
+				So(setUpdateTime(store, "zzTapoo", 30, now.Add(-2*time.Hour)), ShouldBeNil)
+				So(setUpdateTime(store, "aaTapoo", 30, now.Add(-time.Hour)), ShouldBeNil)
+				So(setUpdateTime(store, "mmTapoo", 30, now.Add(-time.Hour)), ShouldBeNil)
+
+				data, err := store.GetLeaderboard(ctx, &LeaderboardQuery{Level: 30, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"zzTapoo", "aaTapoo", "mmTapoo"})
+
+				topScores, err := store.GetTopScores(ctx, &UserInfor{Level: 30}, 5)
+
+				So(err, ShouldBeNil)
+				So(topScores[0].TapooID, ShouldEqual, "zzTapoo")
+				So(topScores[1].TapooID, ShouldEqual, "aaTapoo")
+			})
+		})
+	}
+}
+
+// TestGetUserRank tests the functionality of GetUserRank
+func TestGetUserRank(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetUserRank: Given the "+driver+" store to fetch the user rank with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			Convey("an invalid input, a value that implements an error interface should be returned", func() {
+				_, err := store.GetUserRank(context.Background(), &UserInfor{}, &LeaderboardQuery{Level: 2}, 1)
+				So(err.Error(), ShouldContainSubstring, "invalid Tapoo ID found : '(empty)'")
+
+				_, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "VZWeOq2p"}, &LeaderboardQuery{Level: 2}, -1)
+				So(err.Error(), ShouldContainSubstring, "invalid neighbors found : '-1'")
+
+				_, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "VZWeOq2p"}, &LeaderboardQuery{Level: -5}, 1)
+				So(err.Error(), ShouldContainSubstring, "invalid game level found : '-5'")
+			})
+
+			Convey("a user without high scores on the level, ErrNotFound should be returned", func() {
+				data, err := store.GetUserRank(context.Background(), &UserInfor{TapooID: "VZWeOq2p"}, &LeaderboardQuery{Level: 2}, 1)
+
+				So(data, ShouldHaveLength, 0)
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+
+				_, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "fake_tapoo_id"}, &LeaderboardQuery{Level: GlobalLeaderboard}, 1)
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+
+			Convey("a user on the level leaderboard, the neighbors ranked around the user should be returned", func() {
+				data, err := store.GetUserRank(context.Background(), &UserInfor{TapooID: "FANVZWeOq2p"}, &LeaderboardQuery{Level: 2}, 1)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 2), ShouldResemble, []string{"Vf2TqN5MB", "FANVZWeOq2p", "FbnnuznkFAN"})
+
+				data, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "GzlWAL0mP"}, &LeaderboardQuery{Level: 2}, 2)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 1), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB", "FANVZWeOq2p"})
+
+				data, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "06PE0LPzyCL"}, &LeaderboardQuery{Level: 2}, 0)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 6), ShouldResemble, []string{"06PE0LPzyCL"})
+			})
+
+			Convey("a user on the global leaderboard, the neighbors ranked around the user should be returned", func() {
+				data, err := store.GetUserRank(context.Background(), &UserInfor{TapooID: "Fbn56nuznk"}, &LeaderboardQuery{Level: GlobalLeaderboard}, 2)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 5), ShouldResemble, []string{"06PE0LPzyCL", "FANVZWeOq2p", "Fbn56nuznk"})
+				So(data[2].HighScores, ShouldEqual, 1027)
+			})
+
+			Convey("a user on the weekly leaderboard, the neighbors ranked in the week should be returned", func() {
+				now := time.Date(2026, time.March, 11, 15, 0, 0, 0, time.UTC)
+
+				addSampleAttempts(store, now)
+
+				q := &LeaderboardQuery{Level: 2}
+				So(q.SetPeriod(Weekly, now), ShouldBeNil)
+
+				data, err := store.GetUserRank(context.Background(), &UserInfor{TapooID: "Vf2TqN5MB"}, q, 1)
+
+				So(err, ShouldBeNil)
+				So(getRankedIDs(data, 2), ShouldResemble, []string{"GzlWAL0mP", "Vf2TqN5MB"})
+
+				_, err = store.GetUserRank(context.Background(), &UserInfor{TapooID: "FbnnuznkFAN"}, q, 1)
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+		})
+	}
+}
+
+// TestSetPeriod tests the functionality of SetPeriod
+func TestSetPeriod(t *testing.T) {
+	Convey("TestSetPeriod: Given a time on a Sunday evening in Nairobi", t, func() {
+		now := time.Date(2026, time.March, 8, 23, 30, 0, 0, time.FixedZone("EAT", 3*60*60))
+		q := new(LeaderboardQuery)
+
+		Convey("the daily period should be the UTC day of the time", func() {
+			So(q.SetPeriod(Daily, now), ShouldBeNil)
+			So(q.From, ShouldResemble, time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC))
+			So(q.To, ShouldResemble, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("the weekly period should start on the Monday before the time", func() {
+			So(q.SetPeriod(Weekly, now), ShouldBeNil)
+			So(q.From, ShouldResemble, time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC))
+			So(q.To, ShouldResemble, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC))
+
+			So(q.SetPeriod(Weekly, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)), ShouldBeNil)
+			So(q.From, ShouldResemble, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC))
+		})
+
+		Convey("the all-time period should not limit the leaderboard", func() {
+			So(q.SetPeriod(Daily, now), ShouldBeNil)
+			So(q.SetPeriod(AllTime, now), ShouldBeNil)
+			So(q.isAllTime(), ShouldBeTrue)
+		})
+
+		Convey("an unknown period should return an error", func() {
+			err := q.SetPeriod("monthly", now)
+
+			So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+			So(err.Error(), ShouldContainSubstring, "invalid period found : 'monthly'")
+		})
+	})
+}
+
+// TestGetNeighborsQuery tests the functionality of getNeighborsQuery
+func TestGetNeighborsQuery(t *testing.T) {
+	Convey("TestGetNeighborsQuery: Given the user rank and the number of neighbors", t, func() {
+		Convey("the page should start from the first rank if the user is close to the top", func() {
+			So(getNeighborsQuery(&LeaderboardQuery{Level: 2}, 2, 3), ShouldResemble, &LeaderboardQuery{Level: 2, Offset: 0, Limit: 5})
+		})
+
+		Convey("the page should hold the neighbors above and below the user", func() {
+			So(getNeighborsQuery(&LeaderboardQuery{Level: GlobalLeaderboard}, 42, 2), ShouldResemble,
+				&LeaderboardQuery{Level: GlobalLeaderboard, Offset: 39, Limit: 5})
+		})
+	})
+}
@@ -1,10 +1,9 @@
 package db
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -28,93 +27,153 @@ type LevelScoreResponse struct {
 	UpdateAt   time.Time `json:"updated_at"`
 }
 
-const invalidData = "datastore: invalid %s found : '%v'"
-
 var errGenUUID = errors.New("datastore: generating a new UUID failed")
 
 // createLevelScore creates a new level with a default  high score value of zero.
 // This method should always be executed everytime a user moves to a new level.
-func (u *UserInfor) createLevelScore(uuid string) error {
+func (s *sqlStore) createLevelScore(ctx context.Context, u *UserInfor, uuid string) error {
 	query := `INSERT INTO scores (uuid, game_level, user_id) VALUES (?, ?, ?);`
 
-	_, _, err := execPrepStmts(noReturnVal, query, uuid, strconv.Itoa(u.Level), u.TapooID)
+	_, _, err := s.execPrepStmts(ctx, noReturnVal, query, uuid, strconv.Itoa(u.Level), u.TapooID)
 	return err
 }
 
 // getLevelScore fetches the level scores for the provided tapoo user ID.
 // This method should return data if the user want to try out the specific level again.
-func (u *UserInfor) getLevelScore() (*LevelScoreResponse, error) {
+func (s *sqlStore) getLevelScore(ctx context.Context, u *UserInfor) (*LevelScoreResponse, error) {
 	query := `SELECT created_at, high_scores, game_level, user_id, updated_at` +
 		` FROM scores WHERE user_id = ? and game_level = ?;`
 
-	_, row, err := execPrepStmts(singleRow, query, u.TapooID, strconv.Itoa(u.Level))
+	_, row, err := s.execPrepStmts(ctx, singleRow, query, u.TapooID, strconv.Itoa(u.Level))
 	if err != nil {
 		return nil, err
 	}
 
-	var s LevelScoreResponse
+	var d LevelScoreResponse
 
-	err = row.Scan(&s.CreatedAt, &s.HighScores, &s.Level, &s.TapooID, &s.UpdateAt)
-	return &s, err
+	err = row.Scan(&d.CreatedAt, &d.HighScores, &d.Level, &d.TapooID, &d.UpdateAt)
+	return &d, mapDriverError(err)
 }
 
 // GetOrCreateLevelScore fetches or creates data about the user for the specific level.
 // This methods is called every time a new game starts for every level except the training level (level 0).
-func (u *UserInfor) GetOrCreateLevelScore() (*LevelScoreResponse, error) {
-	switch {
-	case u.Level < 0:
-		return nil, fmt.Errorf(invalidData, "game level", u.Level)
+func (s *sqlStore) GetOrCreateLevelScore(ctx context.Context, u *UserInfor) (*LevelScoreResponse, error) {
+	if err := u.checkLevel(); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
 
-	case len(u.TapooID) == 0:
-		return nil, fmt.Errorf(invalidData, "Tapoo ID", u.TapooID+"(empty)")
+	var d *LevelScoreResponse
 
-	case len(u.TapooID) > 64:
-		return nil, fmt.Errorf(invalidData, "Tapoo ID", u.TapooID[:10]+"... (Too long)")
+	err := s.withTx(ctx, func(t *sqlStore) (err error) {
+		d, err = t.getOrCreateLevelScore(ctx, u)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
+	return d, nil
+}
+
+// getOrCreateLevelScore fetches the user level scores creating them first if they don't
+// exist. It should be run in a transaction.
+func (s *sqlStore) getOrCreateLevelScore(ctx context.Context, u *UserInfor) (*LevelScoreResponse, error) {
 	u2, err := uuid.NewV4()
 	if err != nil {
 		return nil, errGenUUID
 	}
 
-	err = u.createLevelScore(u2.String())
-
-	switch {
-	case strings.Contains(err.Error(), "Duplicate entry"):
-	default:
+	// an existing level score is fetched instead of being created.
+	if err = s.createLevelScore(ctx, u, u2.String()); err != nil && !errors.Is(err, ErrDuplicate) {
 		return nil, err
 	}
 
-	return u.getLevelScore()
+	return s.getLevelScore(ctx, u)
+}
+
+// SaveHighScores creates the user level scores if they don't exist and replaces the
+// stored high scores only if the high scores provided are higher.
+func (s *sqlStore) SaveHighScores(ctx context.Context, u *UserInfor, highScores int) (*LevelScoreResponse, error) {
+	return s.SaveAttempt(ctx, &Attempt{TapooID: u.TapooID, Level: u.Level, Outcome: Succeeded, Scores: highScores})
+}
+
+// saveHighScores replaces the stored user level high scores only if the high scores
+// provided are higher.
+func (s *sqlStore) saveHighScores(ctx context.Context, u *UserInfor, highScores int) error {
+	query := `UPDATE scores SET high_scores = ?, updated_at = CURRENT_TIMESTAMP WHERE user_id = ? ` +
+		`and game_level = ? and high_scores < ?;`
+
+	score := strconv.Itoa(highScores)
+
+	_, _, err := s.execPrepStmts(ctx, noReturnVal, query, score, u.TapooID, strconv.Itoa(u.Level), score)
+	return err
 }
 
-// GetTopFiveScores fetches the top five high scores for the provided level.
-func (u *UserInfor) GetTopFiveScores() ([]*LevelScoreResponse, error) {
+// GetHighestClearedLevel fetches the highest level the user has completed successfully.
+// A level is cleared once it has a succeeded attempt even if no scores were left after the
+// hint penalties. The high scores saved before the attempts were recorded count too. If the
+// user has not cleared any level yet, -1 is returned.
+func (s *sqlStore) GetHighestClearedLevel(ctx context.Context, u *UserInfor) (int, error) {
+	if err := u.checkTapooID(); err != nil {
+		return -1, err
+	}
+
+	query := `SELECT COALESCE(MAX(game_level), -1) FROM (SELECT game_level FROM attempts WHERE user_id = ? ` +
+		`and outcome = ? UNION ALL SELECT game_level FROM scores WHERE user_id = ? and high_scores > 0) AS cleared;`
+
+	_, row, err := s.execPrepStmts(ctx, singleRow, query, u.TapooID, Succeeded, u.TapooID)
+	if err != nil {
+		return -1, err
+	}
+
+	level := -1
+	if err = row.Scan(&level); err != nil {
+		return -1, err
+	}
+
+	return level, nil
+}
+
+// GetTopScores fetches the top limit high scores for the provided level. Only the users
+// that have cleared the level are ranked.
+func (s *sqlStore) GetTopScores(ctx context.Context, u *UserInfor, limit int) ([]*LevelScoreResponse, error) {
 	topScores := make([]*LevelScoreResponse, 0)
 
-	if u.Level < 0 {
-		return topScores, fmt.Errorf(invalidData, "game level", u.Level)
+	if err := u.checkLevel(); err != nil {
+		return topScores, err
+	}
+
+	if err := checkLimit(limit); err != nil {
+		return topScores, err
 	}
 
 	query := `SELECT s.created_at, s.high_scores, s.game_level, s.user_id,` +
 		` s.updated_at, u.email FROM scores s, users u WHERE s.game_level = ? ` +
-		`and s.user_id = u.id ORDER BY s.high_scores DESC LIMIT 5;`
+		`and s.user_id = u.id and ` + clearedScores +
+		` ORDER BY s.high_scores DESC, s.updated_at, s.user_id LIMIT ?;`
 
-	rows, _, err := execPrepStmts(multiRows, query, strconv.Itoa(u.Level))
+	rows, _, err := s.execPrepStmts(ctx, multiRows, query, strconv.Itoa(u.Level), Succeeded, limit)
 	if err != nil {
 		return topScores, err
 	}
 
-	// max of 5 result sets expected
+	defer rows.Close()
+
+	// max of limit result sets expected
 	for rows.Next() {
-		s := new(LevelScoreResponse)
+		d := new(LevelScoreResponse)
 
-		err = rows.Scan(&s.CreatedAt, &s.HighScores, &s.Level, &s.TapooID, &s.UpdateAt, &s.Email)
+		err = rows.Scan(&d.CreatedAt, &d.HighScores, &d.Level, &d.TapooID, &d.UpdateAt, &d.Email)
 		if err != nil {
 			return topScores, err
 		}
 
-		topScores = append(topScores, s)
+		topScores = append(topScores, d)
 	}
 
 	return topScores, rows.Err()
@@ -124,23 +183,28 @@ func (u *UserInfor) GetTopFiveScores() ([]*LevelScoreResponse, error) {
 // This method should only be invoked when the specific level is completed successfully.
 // If a level is not completed successfully no scores update made and thus the
 // users status quo for the specific level remains.
-func (u *UserInfor) UpdateLevelScore(highScores int) error {
-	switch {
-	case u.Level < 0:
-		return fmt.Errorf(invalidData, "game level", u.Level)
-
-	case highScores < 0:
-		return fmt.Errorf(invalidData, "high scores", highScores)
+func (s *sqlStore) UpdateLevelScore(ctx context.Context, u *UserInfor, highScores int) error {
+	if err := u.checkLevel(); err != nil {
+		return err
+	}
 
-	case len(u.TapooID) == 0:
-		return fmt.Errorf(invalidData, "Tapoo ID", u.TapooID+"(empty)")
+	if err := checkHighScores(highScores); err != nil {
+		return err
+	}
 
-	case len(u.TapooID) > 64:
-		return fmt.Errorf(invalidData, "Tapoo ID", u.TapooID[:10]+"... (Too long)")
+	if err := u.checkTapooID(); err != nil {
+		return err
 	}
 
-	query := `UPDATE scores SET high_scores = ? WHERE user_id = ? and game_level = ?;`
+	query := `UPDATE scores SET high_scores = ?, updated_at = CURRENT_TIMESTAMP WHERE user_id = ? and game_level = ?;`
 
-	_, _, err := execPrepStmts(noReturnVal, query, strconv.Itoa(highScores), u.TapooID, strconv.Itoa(u.Level))
-	return err
+	return s.withTx(ctx, func(t *sqlStore) error {
+		_, _, err := t.execPrepStmts(ctx, noReturnVal, query, strconv.Itoa(highScores), u.TapooID, strconv.Itoa(u.Level))
+		if err != nil {
+			return err
+		}
+
+		return t.recordAttempt(ctx, &Attempt{TapooID: u.TapooID, Level: u.Level, Outcome: Succeeded,
+			Scores: highScores}, time.Now())
+	})
 }
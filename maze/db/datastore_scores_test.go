@@ -1,68 +1,41 @@
 package db
 
 import (
-	"log"
-	"os"
+	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/go-sql-driver/mysql"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
-// TestMain sets up the test environment by loading the mock data.
-func TestMain(m *testing.M) {
-	withErrorExit := func(err error) {
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	withErrorExit(db.Ping())
-
-	// drop the users and the scores tables if they exist
-	_, err := db.Query("DROP TABLE IF EXISTS scores, users;")
-	withErrorExit(err)
-
-	// recreate the tables
-	err = checkTablesExit()
-	withErrorExit(err)
-
-	// load mock data users mock data
-	loadData := func(filePath, table string) error {
-		mysql.RegisterLocalFile(filePath)
-		_, err = db.Exec(`LOAD DATA LOCAL INFILE '` + filePath +
-			`' INTO TABLE ` + table +
-			` FIELDS TERMINATED BY ',' LINES TERMINATED BY '\n' IGNORE 1 LINES;`)
-		return err
-	}
-
-	withErrorExit(loadData("sample_data_users.csv", "users"))
-	withErrorExit(loadData("sample_data_scores.csv", "scores"))
-
-	os.Exit(m.Run())
-}
-
 // TestCreateLevelScore tests the functionality of createLevelScore
 func TestCreateLevelScore(t *testing.T) {
 	Convey("TestCreateLevelScore: Given the UserInfor to create level scores with correct data", t, func() {
+		store, cleanUp, err := newTestStore(sqliteDriver)
+		So(err, ShouldBeNil)
+
+		defer cleanUp()
+
+		s := store.(*sqlStore)
+
 		Convey("recreating game_level and user_id combination that already exist should return"+
-			"a value that implements an error interface", func() {
-			user := &UserInfor{TapooID: "Vf2TqN5MB", Level: 1}
-			err := user.createLevelScore("sample_uuid_value")
+			" a duplicate entry error", func() {
+			err := s.createLevelScore(context.Background(), &UserInfor{TapooID: "Vf2TqN5MB", Level: 1}, "sample_uuid_value")
 
 			So(err, ShouldNotBeNil)
 			So(err, ShouldImplement, (*error)(nil))
-			So(err.Error(), ShouldContainSubstring, "Duplicate entry 'Vf2TqN5MB-1' for key 'user_id'")
+			So(errors.Is(err, ErrDuplicate), ShouldBeTrue)
 		})
 
 		Convey("creating a new game_level and user_id combination should return a nil value", func() {
 			user := &UserInfor{TapooID: "06PE0LPzyCL", Level: 20}
-			err := user.createLevelScore("sample_uuid_value")
+			err := s.createLevelScore(context.Background(), user, "sample_uuid_value")
 
 			So(err, ShouldBeNil)
 
-			data, err := user.getLevelScore()
+			data, err := s.getLevelScore(context.Background(), user)
 
 			So(err, ShouldBeNil)
 			So(data.TapooID, ShouldEqual, "06PE0LPzyCL")
@@ -75,36 +48,34 @@ func TestCreateLevelScore(t *testing.T) {
 // TestGetLevelScore tests the functionality of getLevelScore
 func TestGetLevelScore(t *testing.T) {
 	Convey("TestGetLevelScore: Given the UserInfor to get level scores with", t, func() {
-		Convey("closed db connection is used, should return a value that implements"+
-			" an error interface", func() {
-			copyOfDb := cloneDb()
-			db.Close()
+		store, cleanUp, err := newTestStore(sqliteDriver)
+		So(err, ShouldBeNil)
 
-			user := &UserInfor{TapooID: "VZWeOq2p", Level: 1}
-			data, err := user.getLevelScore()
+		defer cleanUp()
 
-			db = copyOfDb
+		s := store.(*sqlStore)
 
-			So(db.Ping(), ShouldBeNil)
+		Convey("closed db connection is used, should return a value that implements"+
+			" an error interface", func() {
+			s.Close()
+			data, err := s.getLevelScore(context.Background(), &UserInfor{TapooID: "VZWeOq2p", Level: 1})
 
-			So(data, ShouldEqual, nil)
+			So(data, ShouldBeNil)
 			So(err, ShouldNotBeNil)
 			So(err.Error(), ShouldContainSubstring, "sql: database is closed")
 		})
 
 		Convey("the tapoo id entry does not exist an error should be returned, "+
 			"should return a value that implements an error interface", func() {
-			user := &UserInfor{TapooID: "VZW2eOq2p", Level: 1}
-			data, err := user.getLevelScore()
+			data, err := s.getLevelScore(context.Background(), &UserInfor{TapooID: "VZW2eOq2p", Level: 1})
 
 			So(data, ShouldResemble, new(LevelScoreResponse))
-			So(err, ShouldNotBeNil)
+			So(errors.Is(err, ErrNotFound), ShouldBeTrue)
 			So(err.Error(), ShouldContainSubstring, "sql: no rows in result set")
 		})
 
 		Convey("variables whose tapoo ID entry exists in the db should return a nil value error", func() {
-			user := &UserInfor{TapooID: "VZWeOq2p", Level: 1}
-			data, err := user.getLevelScore()
+			data, err := s.getLevelScore(context.Background(), &UserInfor{TapooID: "VZWeOq2p", Level: 1})
 
 			So(err, ShouldBeNil)
 			So(data.Email, ShouldEqual, "")
@@ -119,155 +90,312 @@ func TestGetLevelScore(t *testing.T) {
 
 // TestGetOrCreateLevelScore tests the functionality of GetOrCreateLevelScore
 func TestGetOrCreateLevelScore(t *testing.T) {
-	errfunc := func(info *UserInfor, errMsg string) {
-		scores, err := info.GetOrCreateLevelScore()
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetOrCreateLevelScore: Given the "+driver+" store to get or create level scores with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-		So(err, ShouldNotBeNil)
-		So(err, ShouldImplement, (*error)(nil))
-		So(err.Error(), ShouldContainSubstring, errMsg)
-		So(scores, ShouldBeNil)
-	}
+			defer cleanUp()
 
-	Convey("TestGetOrCreateLevelScore: Given UserInfo data to get or create level scores with", t, func() {
-		Convey("game level less than zero, a value that implements an error interface should be returned", func() {
-			errfunc(&UserInfor{TapooID: "VZWeOq2p", Level: -1}, "invalid game level found : '-1'")
-		})
+			errfunc := func(info *UserInfor, errMsg string) {
+				scores, err := store.GetOrCreateLevelScore(context.Background(), info)
 
-		Convey("an empty tapoo ID, a value that implements an error interface should be returned", func() {
-			errfunc(&UserInfor{TapooID: "", Level: 1}, "invalid Tapoo ID found : '(empty)'")
-		})
+				So(err, ShouldNotBeNil)
+				So(err, ShouldImplement, (*error)(nil))
+				So(err.Error(), ShouldContainSubstring, errMsg)
+				So(scores, ShouldBeNil)
 
-		Convey("tapoo ID longer than 64 characters, a value that implements an error"+
-			" interface should be returned", func() {
-			user := &UserInfor{TapooID: "a6a1-b43d84afa437-d3140030-4a5c-4352-9a8a-8fe4d988502-9a8a-8fe4d9", Level: 3}
+				var invalid *ErrInvalidInput
+				So(errors.As(err, &invalid), ShouldBeTrue)
+			}
 
-			errfunc(user, "invalid Tapoo ID found : 'a6a1-b43d8... (Too long)'")
+			Convey("game level less than zero, a value that implements an error interface should be returned", func() {
+				errfunc(&UserInfor{TapooID: "VZWeOq2p", Level: -1}, "invalid game level found : '-1'")
+			})
+
+			Convey("an empty tapoo ID, a value that implements an error interface should be returned", func() {
+				errfunc(&UserInfor{TapooID: "", Level: 1}, "invalid Tapoo ID found : '(empty)'")
+			})
+
+			Convey("tapoo ID longer than 64 characters, a value that implements an error"+
+				" interface should be returned", func() {
+				user := &UserInfor{TapooID: "a6a1-b43d84afa437-d3140030-4a5c-4352-9a8a-8fe4d988502-9a8a-8fe4d9", Level: 3}
+
+				errfunc(user, "invalid Tapoo ID found : 'a6a1-b43d8... (Too long)'")
+			})
+
+			Convey("tapoo ID of a user that does not exist, a value that implements an"+
+				" error interface in returned", func() {
+				scores, err := store.GetOrCreateLevelScore(context.Background(), &UserInfor{TapooID: "VZWe   Oq2p", Level: 3})
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+				So(scores, ShouldBeNil)
+			})
+
+			Convey("all variables correctly used and have no invalid characters, "+
+				"the error value returned should be nil", func() {
+				scores, err := store.GetOrCreateLevelScore(context.Background(), &UserInfor{TapooID: "06PE0LPzyCL", Level: 3})
+
+				So(err, ShouldBeNil)
+				So(scores.HighScores, ShouldEqual, 1203)
+				So(scores.TapooID, ShouldEqual, "06PE0LPzyCL")
+				So(scores.Email, ShouldEqual, "")
+				So(scores.Level, ShouldEqual, 3)
+				So(scores.CreatedAt, ShouldHappenBefore, time.Now())
+				So(scores.UpdateAt, ShouldHappenBefore, time.Now())
+			})
+
+			Convey("a level that the user has not played, new level scores should be created", func() {
+				scores, err := store.GetOrCreateLevelScore(context.Background(), &UserInfor{TapooID: "06PE0LPzyCL", Level: 30})
+
+				So(err, ShouldBeNil)
+				So(scores.HighScores, ShouldEqual, 0)
+				So(scores.Level, ShouldEqual, 30)
+			})
 		})
+	}
+}
 
-		Convey("tapoo ID that is not a foerign key in users table, a value that implements an"+
-			" error interface in returned", func() {
-			errfunc(&UserInfor{TapooID: "VZWe   Oq2p", Level: 3}, "Cannot add or update a child row: a foreign key constraint fails")
-		})
+// TestGetHighestClearedLevel tests the functionality of GetHighestClearedLevel
+func TestGetHighestClearedLevel(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetHighestClearedLevel: Given the "+driver+" store to fetch the highest cleared level with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-		Convey("all variables correctly used and have no invalid characters, "+
-			"the error value returned should be nil", func() {
-			user := &UserInfor{TapooID: "06PE0LPzyCL", Level: 3}
-			scores, err := user.GetOrCreateLevelScore()
+			defer cleanUp()
 
-			So(err, ShouldBeNil)
-			So(scores.HighScores, ShouldEqual, 1203)
-			So(scores.TapooID, ShouldEqual, "06PE0LPzyCL")
-			So(scores.Email, ShouldEqual, "")
-			So(scores.Level, ShouldEqual, 3)
-			So(scores.CreatedAt, ShouldHappenBefore, time.Now())
-			So(scores.UpdateAt, ShouldHappenBefore, time.Now())
+			Convey("an empty tapoo ID, a value that implements an error interface should be returned", func() {
+				level, err := store.GetHighestClearedLevel(context.Background(), &UserInfor{TapooID: ""})
+
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid Tapoo ID found : '(empty)'")
+				So(level, ShouldEqual, -1)
+			})
+
+			Convey("a tapoo ID that has not cleared any level, -1 should be returned", func() {
+				level, err := store.GetHighestClearedLevel(context.Background(), &UserInfor{TapooID: "fake_tapoo_id"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, -1)
+			})
+
+			Convey("a tapoo ID that has cleared some levels, the highest level should be returned", func() {
+				level, err := store.GetHighestClearedLevel(context.Background(), &UserInfor{TapooID: "Fbn56nuznk"})
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, 2)
+			})
 		})
-	})
+	}
 }
 
-// TestGetTopFiveScores tests the functionality of GetTopFiveScores
-func TestGetTopFiveScores(t *testing.T) {
-	Convey("TestGetTopFiveScores: Given the UserInfor to fetch top five scores with ", t, func() {
-		Convey("the game level as a value less than zero, a value that implements "+
-			"an error interface should be returned", func() {
-			user := &UserInfor{Level: -23, TapooID: "fake_tapoo_id"}
-			data, err := user.GetTopFiveScores()
+// TestGetTopScores tests the functionality of GetTopScores
+func TestGetTopScores(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetTopScores: Given the "+driver+" store to fetch the top scores with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-			So(data, ShouldHaveLength, 0)
-			So(err, ShouldNotBeNil)
-			So(err, ShouldImplement, (*error)(nil))
-			So(err.Error(), ShouldContainSubstring, "invalid game level found : '-23'")
-		})
+			defer cleanUp()
 
-		Convey("the database connection used bieng invalid, a value that "+
-			"implements an error interface should be returned", func() {
-			copyOfDb := cloneDb()
-			db.Close()
+			Convey("the game level as a value less than zero, a value that implements "+
+				"an error interface should be returned", func() {
+				data, err := store.GetTopScores(context.Background(), &UserInfor{Level: -23, TapooID: "fake_tapoo_id"}, 5)
 
-			user := &UserInfor{Level: 3, TapooID: "fake_tapoo_id"}
-			data, err := user.GetTopFiveScores()
+				So(data, ShouldHaveLength, 0)
+				So(err, ShouldNotBeNil)
+				So(err, ShouldImplement, (*error)(nil))
+				So(err.Error(), ShouldContainSubstring, "invalid game level found : '-23'")
+			})
 
-			db = copyOfDb
+			Convey("the store that is closed, a value that implements an error"+
+				" interface should be returned", func() {
+				store.Close()
+				data, err := store.GetTopScores(context.Background(), &UserInfor{Level: 3, TapooID: "fake_tapoo_id"}, 5)
 
-			So(db.Ping(), ShouldBeNil)
+				So(data, ShouldHaveLength, 0)
+				So(err, ShouldNotBeNil)
+			})
 
-			So(data, ShouldHaveLength, 0)
-			So(err, ShouldNotBeNil)
-			So(err, ShouldImplement, (*error)(nil))
-			So(err.Error(), ShouldContainSubstring, "sql: database is closed")
-		})
+			Convey("the valid game level value used, the top five scores should be"+
+				" returned in descending order", func() {
+				data, err := store.GetTopScores(context.Background(), &UserInfor{Level: 2, TapooID: ""}, 5)
 
-		Convey("the valid database connection and game level value used, the error value"+
-			" returned should be equal to nil", func() {
-			user := &UserInfor{Level: 2, TapooID: ""}
-			data, err := user.GetTopFiveScores()
+				// test data
+				topScores := []int{1948, 1653, 1616, 1584, 1027}
+				userIDs := []string{"GzlWAL0mP", "Vf2TqN5MB", "FANVZWeOq2p", "FbnnuznkFAN", "Fbn56nuznk"}
+				userEmails := []string{"ckumaar0@tripod.com", "sgravell1@europa.eu",
+					"test.user@naihub.com", "sclaussen3@cam.ac.uk", "sample.user@niahub.com"}
 
-			// test data
-			topScores := []int{1948, 1653, 1616, 1584, 1027}
-			userIDs := []string{"GzlWAL0mP", "Vf2TqN5MB", "FANVZWeOq2p", "FbnnuznkFAN", "Fbn56nuznk"}
-			userEmails := []string{"ckumaar0@tripod.com", "sgravell1@europa.eu",
-				"test.user@naihub.com", "sclaussen3@cam.ac.uk", "sample.user@niahub.com"}
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 5)
 
-			So(err, ShouldBeNil)
-			So(data, ShouldHaveLength, 5)
+				for i, item := range data {
+					So(item.HighScores, ShouldEqual, topScores[i])
+					So(item.TapooID, ShouldEqual, userIDs[i])
+					So(item.Email, ShouldEqual, userEmails[i])
+				}
+			})
 
-			for _, item := range data {
-				So(topScores, ShouldContain, item.HighScores)
-				So(userIDs, ShouldContain, item.TapooID)
-				So(userEmails, ShouldContain, item.Email)
-			}
+			Convey("the limit less than one, a value that implements an error interface should be returned", func() {
+				data, err := store.GetTopScores(context.Background(), &UserInfor{Level: 2}, 0)
+
+				So(data, ShouldHaveLength, 0)
+				So(err.Error(), ShouldContainSubstring, "invalid limit found : '0'")
+			})
+
+			Convey("the limit provided, only the top limit high scores should be returned", func() {
+				data, err := store.GetTopScores(context.Background(), &UserInfor{Level: 2}, 2)
+
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 2)
+				So(data[1].HighScores, ShouldEqual, 1653)
+
+				data, err = store.GetTopScores(context.Background(), &UserInfor{Level: 2}, 100)
+
+				So(err, ShouldBeNil)
+				So(data, ShouldHaveLength, 6)
+			})
 		})
-	})
+	}
 }
 
 // TestUpdateLevelScores tests the functionality of UpdateLevelScores
 func TestUpdateLevelScores(t *testing.T) {
-	errfunc := func(info *UserInfor, highScores int, errMsg string) {
-		err := info.UpdateLevelScore(highScores)
+	for _, driver := range getTestDrivers() {
+		Convey("TestUpdateLevelScores: Given the "+driver+" store, the UserInfor and High Scores with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-		So(err, ShouldNotBeNil)
-		So(err, ShouldImplement, (*error)(nil))
-		So(err.Error(), ShouldContainSubstring, errMsg)
-	}
+			defer cleanUp()
 
-	Convey("TestUpdateLevelScores: Given the UserInfor and High Scores with", t, func() {
-		Convey("the game level less than zero, a value that implements "+
-			"an error interface should be returned", func() {
-			user := &UserInfor{Level: -12, TapooID: "82hsgdj"}
-			errfunc(user, 3223, "invalid game level found : '-12'")
-		})
+			errfunc := func(info *UserInfor, highScores int, errMsg string) {
+				err := store.UpdateLevelScore(context.Background(), info, highScores)
 
-		Convey("the high Scores less than zero, a value that implements "+
-			"an error interface should be returned", func() {
-			user := &UserInfor{Level: 23, TapooID: "82hsgdj"}
-			errfunc(user, -326, "invalid high scores found : '-326'")
-		})
+				So(err, ShouldNotBeNil)
+				So(err, ShouldImplement, (*error)(nil))
+				So(err.Error(), ShouldContainSubstring, errMsg)
+			}
 
-		Convey("the tapoo ID empty, a value that implements "+
-			"an error interface should be returned", func() {
-			user := &UserInfor{Level: 23, TapooID: ""}
-			errfunc(user, 326, "invalid Tapoo ID found : '(empty)'")
-		})
+			Convey("the game level less than zero, a value that implements "+
+				"an error interface should be returned", func() {
+				errfunc(&UserInfor{Level: -12, TapooID: "82hsgdj"}, 3223, "invalid game level found : '-12'")
+			})
 
-		Convey("the tapoo ID longer than 64 charactes, a value that implements "+
-			"an error interface should be returned", func() {
-			user := &UserInfor{Level: 23,
-				TapooID: "a6a1-b43d84afa437-d3140030-4a5c-4352-9a8a-8fe4d988502-9a8a-8fe4d9"}
+			Convey("the high Scores less than zero, a value that implements "+
+				"an error interface should be returned", func() {
+				errfunc(&UserInfor{Level: 23, TapooID: "82hsgdj"}, -326, "invalid high scores found : '-326'")
+			})
 
-			errfunc(user, 326, "invalid Tapoo ID found : 'a6a1-b43d8... (Too long)'")
-		})
+			Convey("the tapoo ID empty, a value that implements "+
+				"an error interface should be returned", func() {
+				errfunc(&UserInfor{Level: 23, TapooID: ""}, 326, "invalid Tapoo ID found : '(empty)'")
+			})
 
-		Convey("the correct values provided, the error value returned should be nil", func() {
-			user := &UserInfor{Level: 1, TapooID: "VZWeOq2p"}
-			err := user.UpdateLevelScore(1000)
+			Convey("the tapoo ID longer than 64 charactes, a value that implements "+
+				"an error interface should be returned", func() {
+				user := &UserInfor{Level: 23,
+					TapooID: "a6a1-b43d84afa437-d3140030-4a5c-4352-9a8a-8fe4d988502-9a8a-8fe4d9"}
 
-			So(err, ShouldBeNil)
+				errfunc(user, 326, "invalid Tapoo ID found : 'a6a1-b43d8... (Too long)'")
+			})
+
+			Convey("the correct values provided, the error value returned should be nil", func() {
+				user := &UserInfor{Level: 1, TapooID: "VZWeOq2p"}
+
+				So(store.UpdateLevelScore(context.Background(), user, 1000), ShouldBeNil)
 
-			data, err := user.getLevelScore()
+				data, err := store.GetOrCreateLevelScore(context.Background(), user)
 
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 1000)
+				So(data.Level, ShouldEqual, 1)
+			})
+		})
+	}
+}
+
+// TestSaveHighScores tests the functionality of SaveHighScores
+func TestSaveHighScores(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestSaveHighScores: Given the "+driver+" store, the UserInfor and High Scores with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
 			So(err, ShouldBeNil)
-			So(data.HighScores, ShouldEqual, 1000)
-			So(data.Level, ShouldEqual, 1)
+
+			defer cleanUp()
+
+			Convey("the high scores less than zero, a value that implements "+
+				"an error interface should be returned", func() {
+				data, err := store.SaveHighScores(context.Background(), &UserInfor{Level: 2, TapooID: "VZWeOq2p"}, -3)
+
+				So(data, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid high scores found : '-3'")
+			})
+
+			Convey("the context that is cancelled, a value that implements "+
+				"an error interface should be returned", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				data, err := store.SaveHighScores(ctx, &UserInfor{Level: 2, TapooID: "VZWeOq2p"}, 3000)
+
+				So(data, ShouldBeNil)
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			})
+
+			Convey("the high scores lower than the stored ones, the stored high scores should be kept", func() {
+				data, err := store.SaveHighScores(context.Background(), &UserInfor{Level: 1, TapooID: "VZWeOq2p"}, 100)
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 533)
+			})
+
+			Convey("the high scores higher than the stored ones, the stored high scores should be replaced", func() {
+				data, err := store.SaveHighScores(context.Background(), &UserInfor{Level: 1, TapooID: "VZWeOq2p"}, 1000)
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 1000)
+			})
+
+			Convey("a level that the user has not played, the level scores should be created", func() {
+				data, err := store.SaveHighScores(context.Background(), &UserInfor{Level: 40, TapooID: "VZWeOq2p"}, 20)
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 20)
+				So(data.Level, ShouldEqual, 40)
+			})
+
+			Convey("concurrent submissions for the same level, the highest one should be kept", func() {
+				var (
+					wg   sync.WaitGroup
+					errs = make(chan error, 20)
+				)
+
+				for i := 1; i <= 20; i++ {
+					wg.Add(1)
+
+					go func(highScores int) {
+						defer wg.Done()
+
+						_, err := store.SaveHighScores(context.Background(),
+							&UserInfor{Level: 41, TapooID: "GzlWAL0mP"}, highScores)
+						errs <- err
+					}(i * 10)
+				}
+
+				wg.Wait()
+				close(errs)
+
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+
+				data, err := store.GetOrCreateLevelScore(context.Background(), &UserInfor{Level: 41, TapooID: "GzlWAL0mP"})
+
+				So(err, ShouldBeNil)
+				So(data.HighScores, ShouldEqual, 200)
+			})
 		})
-	})
+	}
 }
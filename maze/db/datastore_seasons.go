@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Season defines a named period the leaderboards can be limited to. The season starts
+// at StartsAt and ends before EndsAt.
+type Season struct {
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// checkSeason checks if the season name and its period are valid.
+func (s *Season) checkSeason() error {
+	if err := checkSeasonName(s.Name); err != nil {
+		return err
+	}
+
+	if s.StartsAt.IsZero() || !s.EndsAt.After(s.StartsAt) {
+		return &ErrInvalidInput{Field: "season period", Value: s.StartsAt.Format(time.RFC3339) +
+			" - " + s.EndsAt.Format(time.RFC3339)}
+	}
+
+	return nil
+}
+
+// checkSeasonName checks if the season name is valid.
+func checkSeasonName(name string) error {
+	switch {
+	case len(name) == 0:
+		return &ErrInvalidInput{Field: "season name", Value: "(empty)"}
+
+	case len(name) > 64:
+		return &ErrInvalidInput{Field: "season name", Value: name[:10] + "... (Too long)"}
+	}
+
+	return nil
+}
+
+// CreateSeason creates the season provided. ErrDuplicate is returned if a season with
+// the same name exists.
+func (s *sqlStore) CreateSeason(ctx context.Context, season *Season) error {
+	if err := season.checkSeason(); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO seasons (name, starts_at, ends_at) VALUES (?, ?, ?);`
+
+	_, _, err := s.execPrepStmts(ctx, noReturnVal, query, season.Name,
+		season.StartsAt.UTC().Truncate(time.Second), season.EndsAt.UTC().Truncate(time.Second))
+	return err
+}
+
+// GetSeason fetches the season with the name provided. ErrNotFound is returned if the
+// season does not exist.
+func (s *sqlStore) GetSeason(ctx context.Context, name string) (*Season, error) {
+	if err := checkSeasonName(name); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT name, starts_at, ends_at FROM seasons WHERE name = ?;`
+
+	_, row, err := s.execPrepStmts(ctx, singleRow, query, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var d Season
+	if err = row.Scan(&d.Name, &d.StartsAt, &d.EndsAt); err != nil {
+		return nil, mapDriverError(err)
+	}
+
+	return &d, nil
+}
+
+// GetSeasons fetches all the seasons ordered by their start.
+func (s *sqlStore) GetSeasons(ctx context.Context) ([]*Season, error) {
+	seasons := make([]*Season, 0)
+
+	query := `SELECT name, starts_at, ends_at FROM seasons ORDER BY starts_at, name;`
+
+	rows, _, err := s.execPrepStmts(ctx, multiRows, query)
+	if err != nil {
+		return seasons, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		d := new(Season)
+
+		if err = rows.Scan(&d.Name, &d.StartsAt, &d.EndsAt); err != nil {
+			return seasons, err
+		}
+
+		seasons = append(seasons, d)
+	}
+
+	return seasons, rows.Err()
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestSeasons tests the functionality of CreateSeason, GetSeason and GetSeasons
+func TestSeasons(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestSeasons: Given the "+driver+" store to manage the seasons with ", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
+
+			defer cleanUp()
+
+			ctx := context.Background()
+			start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+			Convey("an invalid season, a value that implements an error interface should be returned", func() {
+				for _, item := range []struct {
+					season *Season
+					msg    string
+				}{
+					{&Season{StartsAt: start, EndsAt: start.AddDate(0, 1, 0)}, "invalid season name found : '(empty)'"},
+					{&Season{Name: strings.Repeat("s", 65), StartsAt: start, EndsAt: start.AddDate(0, 1, 0)},
+						"invalid season name found : 'ssssssssss... (Too long)'"},
+					{&Season{Name: "Spring", StartsAt: start, EndsAt: start}, "invalid season period found"},
+					{&Season{Name: "Spring", EndsAt: start}, "invalid season period found"},
+				} {
+					err := store.CreateSeason(ctx, item.season)
+
+					So(err, ShouldHaveSameTypeAs, &ErrInvalidInput{})
+					So(err.Error(), ShouldContainSubstring, item.msg)
+				}
+			})
+
+			Convey("valid seasons, the seasons should be created and fetched", func() {
+				So(store.CreateSeason(ctx, &Season{Name: "Summer", StartsAt: start.AddDate(0, 3, 0),
+					EndsAt: start.AddDate(0, 6, 0)}), ShouldBeNil)
+				So(store.CreateSeason(ctx, &Season{Name: "Spring", StartsAt: start,
+					EndsAt: start.AddDate(0, 3, 0)}), ShouldBeNil)
+
+				err := store.CreateSeason(ctx, &Season{Name: "Spring", StartsAt: start, EndsAt: start.AddDate(0, 1, 0)})
+
+				So(errors.Is(err, ErrDuplicate), ShouldBeTrue)
+
+				season, err := store.GetSeason(ctx, "Spring")
+
+				So(err, ShouldBeNil)
+				So(season.StartsAt.Equal(start), ShouldBeTrue)
+				So(season.EndsAt.Equal(start.AddDate(0, 3, 0)), ShouldBeTrue)
+
+				_, err = store.GetSeason(ctx, "Winter")
+
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+
+				seasons, err := store.GetSeasons(ctx)
+
+				So(err, ShouldBeNil)
+				So(seasons, ShouldHaveLength, 2)
+				So(seasons[0].Name, ShouldEqual, "Spring")
+				So(seasons[1].Name, ShouldEqual, "Summer")
+			})
+		})
+	}
+}
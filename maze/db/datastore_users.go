@@ -1,28 +1,13 @@
 package db
 
 import (
-	"database/sql"
-	"fmt"
-	"strings"
+	"context"
+	"errors"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
 )
 
-const (
-	// noReturnVal indicates the sql query being executed should not
-	// return any value. Return value not expected.
-	noReturnVal int = iota
-
-	// singleRow indicates the sql query bieng executed should only
-	// return a single row of the expected result set.
-	singleRow
-
-	// multiRows indicates the sql query being executed should return
-	// multiple rows of the expected result set.
-	multiRows
-)
-
 // UserInfoResponse defines the expected response from users.
 type UserInfoResponse struct {
 	CreatedAt time.Time `json:"created_at"`
@@ -32,41 +17,38 @@ type UserInfoResponse struct {
 }
 
 // createUser creates a new user using the tapoo ID provided.
-func (u *UserInfor) createUser(uuid string) error {
+func (s *sqlStore) createUser(ctx context.Context, u *UserInfor, uuid string) error {
 	query := `INSERT INTO users (uuid, id, email) VALUES (?, ?, ?);`
 
-	_, _, err := execPrepStmts(noReturnVal, query, uuid, u.TapooID, u.Email)
+	_, _, err := s.execPrepStmts(ctx, noReturnVal, query, uuid, u.TapooID, u.Email)
 	return err
 }
 
 // getUser checks if the tapoo ID provided exists in users.
-func (u *UserInfor) getUser() (*UserInfoResponse, error) {
+func (s *sqlStore) getUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error) {
 	query := `SELECT id, email, created_at, updated_at FROM users WHERE id = ?;`
 
 	var d UserInfoResponse
 
-	_, row, err := execPrepStmts(singleRow, query, u.TapooID)
+	_, row, err := s.execPrepStmts(ctx, singleRow, query, u.TapooID)
 	if err != nil {
 		return nil, err
 	}
 
 	err = row.Scan(&d.TapooID, &d.Email, &d.CreatedAt, &d.UpdateAt)
 
-	return &d, err
+	return &d, mapDriverError(err)
 }
 
 // GetOrCreateUser creates the new user with tapoo ID provided if the
 // it does not exists. Email used can be empty or not.
-func (u *UserInfor) GetOrCreateUser() (*UserInfoResponse, error) {
-	switch {
-	case len(u.TapooID) == 0:
-		return nil, fmt.Errorf(invalidData, "Tapoo ID", u.TapooID+"(empty)")
-
-	case len(u.TapooID) > 64:
-		return nil, fmt.Errorf(invalidData, "Tapoo ID", u.TapooID[:10]+"... (Too long)")
+func (s *sqlStore) GetOrCreateUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
 
-	case len(u.Email) > 64:
-		return nil, fmt.Errorf(invalidData, "Email", u.Email[:10]+"... (Too long)")
+	if err := u.checkEmail(false); err != nil {
+		return nil, err
 	}
 
 	u4, err := uuid.NewV4()
@@ -74,65 +56,53 @@ func (u *UserInfor) GetOrCreateUser() (*UserInfoResponse, error) {
 		return nil, errGenUUID
 	}
 
-	err = u.createUser(u4.String())
+	var d *UserInfoResponse
 
-	switch {
-	case strings.Contains(err.Error(), "Duplicate entry"):
-	default:
+	// an existing user is fetched instead of being created.
+	err = s.withTx(ctx, func(t *sqlStore) error {
+		if err := t.createUser(ctx, u, u4.String()); err != nil && !errors.Is(err, ErrDuplicate) {
+			return err
+		}
+
+		d, err = t.getUser(ctx, u)
+		return err
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
-	return u.getUser()
+	return d, nil
 }
 
-// UpdateUser should update the tapoo user information.
-// While updating a user, the email should not be empty otherwise
-// an error will be returned.
-func (u *UserInfor) UpdateUser() error {
-	switch {
-	case len(u.TapooID) == 0:
-		return fmt.Errorf(invalidData, "Tapoo ID", u.TapooID+"(empty)")
-
-	case len(u.TapooID) > 64:
-		return fmt.Errorf(invalidData, "Tapoo ID", u.TapooID[:10]+"... (Too long)")
-
-	case len(u.Email) == 0:
-		return fmt.Errorf(invalidData, "Email", u.Email+"(empty)")
-
-	case len(u.Email) > 64:
-		return fmt.Errorf(invalidData, "Email", u.Email[:10]+"... (Too long)")
+// GetUser fetches the user with the tapoo ID provided.
+func (s *sqlStore) GetUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
 	}
 
-	query := `UPDATE users SET email = ? WHERE id = ?;`
-
-	_, _, err := execPrepStmts(noReturnVal, query, u.Email, u.TapooID)
-	return err
-}
-
-// execPrepStmts executes the Prepared statement for the sql queries.
-func execPrepStmts(queryType int, sqlQuery string, val ...interface{}) (*sql.Rows, *sql.Row, error) {
-	stmt, err := db.Prepare(sqlQuery)
+	d, err := s.getUser(ctx, u)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	defer stmt.Close()
+	return d, nil
+}
 
-	switch queryType {
-	case noReturnVal:
-		_, err = db.Exec(sqlQuery, val...)
-		return nil, nil, err
+// UpdateUser should update the tapoo user information.
+// While updating a user, the email should not be empty otherwise
+// an error will be returned.
+func (s *sqlStore) UpdateUser(ctx context.Context, u *UserInfor) error {
+	if err := u.checkTapooID(); err != nil {
+		return err
+	}
 
-	case singleRow:
-		row := db.QueryRow(sqlQuery, val...)
-		return nil, row, nil
+	if err := u.checkEmail(true); err != nil {
+		return err
+	}
 
-	case multiRows:
-		rows, err := db.Query(sqlQuery, val...)
-		return rows, nil, err
+	query := `UPDATE users SET email = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;`
 
-	default:
-		return nil, nil,
-			fmt.Errorf(invalidData, "queryType", queryType)
-	}
+	_, _, err := s.execPrepStmts(ctx, noReturnVal, query, u.Email, u.TapooID)
+	return err
 }
@@ -1,8 +1,8 @@
 package db
 
 import (
-	"database/sql"
-	"reflect"
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -12,24 +12,31 @@ import (
 // TestCreateUser tests the functionality of createUser
 func TestCreateUser(t *testing.T) {
 	Convey("TestCreateUser: Given the UserInfor when creating a user with", t, func() {
-		Convey("values that already exist in the database, a value that implements an error"+
-			" interface should be returned", func() {
+		store, cleanUp, err := newTestStore(sqliteDriver)
+		So(err, ShouldBeNil)
+
+		defer cleanUp()
+
+		s := store.(*sqlStore)
+
+		Convey("values that already exist in the database, a duplicate entry error"+
+			" should be returned", func() {
 			user := &UserInfor{TapooID: "FbnnuznkFAN"}
-			err := user.createUser("ea49be59-b553-430c-a706-7860dcb3ea12")
+			err := s.createUser(context.Background(), user, "ea49be59-b553-430c-a706-7860dcb3ea12")
 
 			So(err, ShouldNotBeNil)
 			So(err, ShouldImplement, (*error)(nil))
-			So(err.Error(), ShouldContainSubstring, "Duplicate entry 'ea49be59-b553-430c-a706-7860dcb3ea12'")
+			So(errors.Is(err, ErrDuplicate), ShouldBeTrue)
 		})
 
 		Convey("values that have no invalid characters, a nil error value should"+
 			" be returned", func() {
 			user := &UserInfor{TapooID: "9a9a-7a9a5808e086", Email: "test@naihub.com"}
-			err := user.createUser("f538ab54-1692-41bf-9a9a-7a98808e086d")
+			err := s.createUser(context.Background(), user, "f538ab54-1692-41bf-9a9a-7a98808e086d")
 
 			So(err, ShouldBeNil)
 
-			data, err := user.getUser()
+			data, err := s.getUser(context.Background(), user)
 
 			So(err, ShouldBeNil)
 			So(data.TapooID, ShouldEqual, "9a9a-7a9a5808e086")
@@ -40,45 +47,37 @@ func TestCreateUser(t *testing.T) {
 
 // TestGetUser tests the functionality of getUser
 func TestGetUser(t *testing.T) {
-	errFunc := func(user *UserInfor, errMsg string) {
-		data, err := user.getUser()
+	Convey("TestGetUser: Given the UserInfor when fetching a user with", t, func() {
+		store, cleanUp, err := newTestStore(sqliteDriver)
+		So(err, ShouldBeNil)
 
-		So(err, ShouldNotBeNil)
-		So(data, ShouldResemble, new(UserInfoResponse))
-		So(err, ShouldImplement, (*error)(nil))
-		So(err.Error(), ShouldContainSubstring, errMsg)
-	}
+		defer cleanUp()
+
+		s := store.(*sqlStore)
 
-	Convey("TestGetUser: Given the UserInfor when fetching a user with", t, func() {
 		Convey("the tapoo id provided that does not exist, a value that implements an error"+
 			" interface should be returned", func() {
-			user := &UserInfor{Level: 23, TapooID: "fake_sample_id"}
+			data, err := s.getUser(context.Background(), &UserInfor{Level: 23, TapooID: "fake_sample_id"})
 
-			errFunc(user, "sql: no rows in result set")
+			So(err, ShouldNotBeNil)
+			So(data, ShouldResemble, new(UserInfoResponse))
+			So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "sql: no rows in result set")
 		})
 
 		Convey("closed db connections, a value that implements an error interface should"+
 			" be returned", func() {
-			copyOfDb := cloneDb()
-			db.Close()
-			user := &UserInfor{Level: 2, TapooID: "fake_sample_id"}
-			data, err := user.getUser()
-
-			db = copyOfDb
-
-			So(db.Ping(), ShouldBeNil)
+			s.Close()
+			data, err := s.getUser(context.Background(), &UserInfor{Level: 2, TapooID: "fake_sample_id"})
 
 			So(err, ShouldNotBeNil)
 			So(data, ShouldBeNil)
-			So(err, ShouldImplement, (*error)(nil))
 			So(err.Error(), ShouldContainSubstring, "sql: database is closed")
 		})
 
 		Convey("the values used are properly escaped and the tapoo id exists in the db, "+
 			"a nil error value should be returned", func() {
-			user := &UserInfor{Level: 18, TapooID: "GzlWAL0mP"}
-
-			data, err := user.getUser()
+			data, err := s.getUser(context.Background(), &UserInfor{Level: 18, TapooID: "GzlWAL0mP"})
 
 			So(err, ShouldBeNil)
 			So(data.CreatedAt, ShouldHappenBefore, time.Now())
@@ -91,124 +90,164 @@ func TestGetUser(t *testing.T) {
 
 // TestGetOrCreateUser tests the functionality of GetOrCreateUser
 func TestGetOrCreateUser(t *testing.T) {
-	errFunc := func(user *UserInfor, errMsg string) {
-		data, err := user.GetOrCreateUser()
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetOrCreateUser: Given the "+driver+" store when fetching or creating"+
+			" a user with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-		So(err, ShouldNotBeNil)
-		So(data, ShouldBeNil)
-		So(err, ShouldImplement, (*error)(nil))
-		So(err.Error(), ShouldContainSubstring, errMsg)
-	}
+			defer cleanUp()
 
-	Convey("TestGetOrCreateUser: Given the UserInfor when fetching or creating"+
-		" a user with", t, func() {
-		Convey("the empty tapoo ID, a value that implements an error interface"+
-			" should be returned", func() {
-			user := &UserInfor{TapooID: ""}
+			errFunc := func(user *UserInfor, errMsg string) {
+				data, err := store.GetOrCreateUser(context.Background(), user)
 
-			errFunc(user, "invalid Tapoo ID found : '(empty)'")
-		})
+				So(err, ShouldNotBeNil)
+				So(data, ShouldBeNil)
+				So(err, ShouldImplement, (*error)(nil))
+				So(err.Error(), ShouldContainSubstring, errMsg)
+			}
 
-		Convey("the tapoo ID longer that 64 characters, a value that implements an error"+
-			"interface should be returned", func() {
-			user := &UserInfor{TapooID: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-afba-6734grywu"}
+			Convey("the empty tapoo ID, a value that implements an error interface"+
+				" should be returned", func() {
+				errFunc(&UserInfor{TapooID: ""}, "invalid Tapoo ID found : '(empty)'")
+			})
 
-			errFunc(user, "invalid Tapoo ID found : '2af80406-5... (Too long)'")
-		})
+			Convey("the tapoo ID longer that 64 characters, a value that implements an error"+
+				"interface should be returned", func() {
+				user := &UserInfor{TapooID: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-afba-6734grywu"}
 
-		Convey("the email longer than 64 characters, a value that implements an error"+
-			" interface should be returned", func() {
-			user := &UserInfor{TapooID: "SWfddew34",
-				Email: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-a3f@niahub.com"}
+				errFunc(user, "invalid Tapoo ID found : '2af80406-5... (Too long)'")
+			})
 
-			errFunc(user, "invalid Email found : '2af80406-5... (Too long)'")
-		})
+			Convey("the email longer than 64 characters, a value that implements an error"+
+				" interface should be returned", func() {
+				user := &UserInfor{TapooID: "SWfddew34",
+					Email: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-a3f@niahub.com"}
 
-		Convey("the db connection that is invalid, a value that implements an error"+
-			" interface should be returned", func() {
-			copyOfDb := cloneDb()
-			user := &UserInfor{TapooID: "SWfddew34"}
+				errFunc(user, "invalid Email found : '2af80406-5... (Too long)'")
+			})
 
-			db.Close()
-			data, err := user.GetOrCreateUser()
-			db = copyOfDb
+			Convey("the store that is closed, a value that implements an error"+
+				" interface should be returned", func() {
+				store.Close()
+				data, err := store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "SWfddew34"})
 
-			So(db.Ping(), ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(data, ShouldBeNil)
+			})
 
-			So(err, ShouldNotBeNil)
-			So(data, ShouldBeNil)
-			So(err, ShouldImplement, (*error)(nil))
-			So(err.Error(), ShouldContainSubstring, "sql: database is closed")
-		})
+			Convey("the tapoo ID that already exists, the existing user should be returned", func() {
+				data, err := store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "FANVZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(data.Email, ShouldEqual, "test.user@naihub.com")
+				So(data.TapooID, ShouldEqual, "FANVZWeOq2p")
+				So(data.CreatedAt, ShouldHappenBefore, time.Now())
+				So(data.UpdateAt, ShouldHappenBefore, time.Now())
+			})
+
+			Convey("the tapoo ID that does not exist, a new user should be created", func() {
+				data, err := store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "SWfddew34", Email: "new@naihub.com"})
 
-		Convey("the correct user infor used and the database connection is not invalid"+
-			" the error value returned should be a nil value", func() {
-			user := &UserInfor{TapooID: "FANVZWeOq2p"}
-			data, err := user.GetOrCreateUser()
+				So(err, ShouldBeNil)
+				So(data.Email, ShouldEqual, "new@naihub.com")
+				So(data.TapooID, ShouldEqual, "SWfddew34")
+			})
+		})
+	}
+}
 
+// TestGetUserByID tests the functionality of GetUser
+func TestGetUserByID(t *testing.T) {
+	for _, driver := range getTestDrivers() {
+		Convey("TestGetUserByID: Given the "+driver+" store when fetching a user with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
 			So(err, ShouldBeNil)
-			So(data.Email, ShouldEqual, "test.user@naihub.com")
-			So(data.TapooID, ShouldEqual, "FANVZWeOq2p")
-			So(data.CreatedAt, ShouldHappenBefore, time.Now())
-			So(data.UpdateAt, ShouldHappenBefore, time.Now())
+
+			defer cleanUp()
+
+			Convey("the empty tapoo ID, a value that implements an error interface"+
+				" should be returned", func() {
+				data, err := store.GetUser(context.Background(), &UserInfor{TapooID: ""})
+
+				So(data, ShouldBeNil)
+				So(err.Error(), ShouldContainSubstring, "invalid Tapoo ID found : '(empty)'")
+			})
+
+			Convey("the tapoo ID that does not exist, ErrNotFound should be returned", func() {
+				data, err := store.GetUser(context.Background(), &UserInfor{TapooID: "fake_sample_id"})
+
+				So(data, ShouldBeNil)
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+
+			Convey("the tapoo ID that exists, the user should be returned", func() {
+				data, err := store.GetUser(context.Background(), &UserInfor{TapooID: "GzlWAL0mP"})
+
+				So(err, ShouldBeNil)
+				So(data.Email, ShouldEqual, "ckumaar0@tripod.com")
+				So(data.TapooID, ShouldEqual, "GzlWAL0mP")
+			})
 		})
-	})
+	}
 }
 
 // TestUpdateUser tests the functionality of UpdateUser
 func TestUpdateUser(t *testing.T) {
-	errFunc := func(user *UserInfor, errMsg string) {
-		err := user.UpdateUser()
+	for _, driver := range getTestDrivers() {
+		Convey("TestUpdateUser: Given the "+driver+" store while updating the user with", t, func() {
+			store, cleanUp, err := newTestStore(driver)
+			So(err, ShouldBeNil)
 
-		So(err, ShouldNotBeNil)
-		So(err, ShouldImplement, (*error)(nil))
-		So(err.Error(), ShouldContainSubstring, errMsg)
-	}
+			defer cleanUp()
 
-	Convey("TestUpdateUser: Given the UserInfor while updating the user with", t, func() {
-		Convey("the empty tapoo ID, a value that implements the error interface is"+
-			" returned", func() {
-			user := &UserInfor{TapooID: "", Email: "sample_user@naihub.com"}
+			errFunc := func(user *UserInfor, errMsg string) {
+				err := store.UpdateUser(context.Background(), user)
 
-			errFunc(user, "invalid Tapoo ID found : '(empty)'")
-		})
+				So(err, ShouldNotBeNil)
+				So(err, ShouldImplement, (*error)(nil))
+				So(err.Error(), ShouldContainSubstring, errMsg)
+			}
 
-		Convey("the tapoo ID having more that 64 characters, a value that implements"+
-			" the error interface is returned", func() {
-			user := &UserInfor{Email: "sample_user@naihub.com",
-				TapooID: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-afba-6734grywu"}
+			Convey("the empty tapoo ID, a value that implements the error interface is"+
+				" returned", func() {
+				errFunc(&UserInfor{TapooID: "", Email: "sample_user@naihub.com"}, "invalid Tapoo ID found : '(empty)'")
+			})
 
-			errFunc(user, "invalid Tapoo ID found : '2af80406-5... (Too long)'")
-		})
+			Convey("the tapoo ID having more that 64 characters, a value that implements"+
+				" the error interface is returned", func() {
+				user := &UserInfor{Email: "sample_user@naihub.com",
+					TapooID: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-afba-6734grywu"}
 
-		Convey("the empty email, a value that implements the error interface is"+
-			" returned", func() {
-			user := &UserInfor{TapooID: "f80406-5be2", Email: ""}
+				errFunc(user, "invalid Tapoo ID found : '2af80406-5... (Too long)'")
+			})
 
-			errFunc(user, "invalid Email found : '(empty)'")
-		})
+			Convey("the empty email, a value that implements the error interface is"+
+				" returned", func() {
+				errFunc(&UserInfor{TapooID: "f80406-5be2", Email: ""}, "invalid Email found : '(empty)'")
+			})
 
-		Convey("the email having more that 64 characters, a value that implements"+
-			" the error interface is returned", func() {
-			user := &UserInfor{TapooID: "SWfddew34",
-				Email: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-a3f@niahub.com"}
+			Convey("the email having more that 64 characters, a value that implements"+
+				" the error interface is returned", func() {
+				user := &UserInfor{TapooID: "SWfddew34",
+					Email: "2af80406-5be2-4569-afba-b14e861-2af81406-5b42-893d-a3f@niahub.com"}
 
-			errFunc(user, "invalid Email found : '2af80406-5... (Too long)'")
-		})
+				errFunc(user, "invalid Email found : '2af80406-5... (Too long)'")
+			})
 
-		Convey("the correct values used, a nil value error should be returned", func() {
-			user := &UserInfor{TapooID: "Vf2TqN5MB", Email: "sample_user@naihub.com"}
-			err := user.UpdateUser()
+			Convey("the correct values used, a nil value error should be returned", func() {
+				user := &UserInfor{TapooID: "Vf2TqN5MB", Email: "sample_user@naihub.com"}
 
-			So(err, ShouldBeNil)
+				So(store.UpdateUser(context.Background(), user), ShouldBeNil)
 
-			data, err := user.getUser()
+				data, err := store.GetOrCreateUser(context.Background(), &UserInfor{TapooID: "Vf2TqN5MB"})
 
-			So(err, ShouldBeNil)
-			So(data.Email, ShouldEqual, "sample_user@naihub.com")
-			So(data.TapooID, ShouldEqual, "Vf2TqN5MB")
+				So(err, ShouldBeNil)
+				So(data.Email, ShouldEqual, "sample_user@naihub.com")
+				So(data.TapooID, ShouldEqual, "Vf2TqN5MB")
+			})
 		})
-	})
+	}
 }
 
 // TestExecPrepStmts tests the functionality of execPrepStmts
@@ -220,24 +259,25 @@ func TestExecPrepStmts(t *testing.T) {
 	}
 
 	Convey("TestExecPrepStmts: Given a query and its other metadata with", t, func() {
-		Convey("closed database connection, a value that implements an error interface should be returned", func() {
-			copyOfDb := cloneDb()
-			db.Close()
+		store, cleanUp, err := newTestStore(sqliteDriver)
+		So(err, ShouldBeNil)
+
+		defer cleanUp()
 
-			rows, _, err := execPrepStmts(multiRows, "SELECT * FROM users;", "")
-			db = copyOfDb
+		s := store.(*sqlStore)
+
+		Convey("closed database connection, a value that implements an error interface should be returned", func() {
+			s.Close()
+			rows, _, err := s.execPrepStmts(context.Background(), multiRows, "SELECT * FROM users;", "")
 
 			So(rows, ShouldBeNil)
 
 			errFunc(err, "sql: database is closed")
-
-			// Re-establish a connection if necessary
-			So(db.Ping(), ShouldBeNil)
 		})
 
 		Convey("singleRow queryType found no resultSet data match, a value that "+
 			"implements the error interface should be returned", func() {
-			_, row, err := execPrepStmts(singleRow, "SELECT email FROM users WHERE id = ?;", "VZW7274Oq2p")
+			_, row, err := s.execPrepStmts(context.Background(), singleRow, "SELECT email FROM users WHERE id = ?;", "VZW7274Oq2p")
 
 			So(row, ShouldNotBeNil)
 			So(err, ShouldBeNil)
@@ -249,48 +289,26 @@ func TestExecPrepStmts(t *testing.T) {
 
 		Convey("query missing some arguments, a value that "+
 			"implements the error interface should be returned", func() {
-			_, _, err := execPrepStmts(noReturnVal,
+			_, _, err := s.execPrepStmts(context.Background(), noReturnVal,
 				"UPDATE scores SET high_scores = ? WHERE game_level = ? and user_id = ?;", "1000", "12")
 
-			errFunc(err, "sql: expected 3 arguments, got 2")
-		})
-
-		Convey("query missing the only argument, a value that "+
-			"implements the error interface should be returned", func() {
-			_, row, err := execPrepStmts(singleRow, "SELECT email FROM users WHERE id = ?")
-
-			So(row, ShouldNotBeNil)
-			So(err, ShouldBeNil)
-
-			err = row.Scan(nil)
-
-			errFunc(err, "Error 1064: You have an error in your SQL syntax;")
-		})
-
-		Convey("query having extra arguments, a value that "+
-			"implements the error interface should be returned", func() {
-			rows, _, err := execPrepStmts(multiRows, "SELECT email FROM users WHERE id LIKE ?;", "V", "S")
-
-			So(rows, ShouldBeNil)
-
-			errFunc(err, "sql: expected 1 arguments, got 2")
+			errFunc(err, "want 3 got 2")
 		})
 
 		Convey("queryType that is non existent, a value that "+
 			"implements the error interface should be returned", func() {
-			_, _, err := execPrepStmts(5, "SELECT email FROM users")
+			_, _, err := s.execPrepStmts(context.Background(), 5, "SELECT email FROM users")
 
 			errFunc(err, "invalid queryType found : '5'")
 		})
 
 		Convey("noReturnVal queryType having the correct values, should return a nil error value", func() {
-			_, _, err := execPrepStmts(noReturnVal,
+			_, _, err := s.execPrepStmts(context.Background(), noReturnVal,
 				"UPDATE scores SET high_scores = ? WHERE game_level = ? and user_id = ?;", "1000", "12", "VZWeOq2p")
 
 			So(err, ShouldBeNil)
 
-			user := &UserInfor{Level: 12, TapooID: "VZWeOq2p"}
-			data, err := user.getLevelScore()
+			data, err := s.getLevelScore(context.Background(), &UserInfor{Level: 12, TapooID: "VZWeOq2p"})
 
 			So(err, ShouldBeNil)
 			So(data.HighScores, ShouldEqual, 1000)
@@ -298,7 +316,7 @@ func TestExecPrepStmts(t *testing.T) {
 
 		Convey("singleRow queryType having the correct values, should return the fetched data and a nil error value", func() {
 			d := UserInfoResponse{}
-			_, row, err := execPrepStmts(singleRow, "SELECT email FROM users WHERE id = ?;", "VZWeOq2p")
+			_, row, err := s.execPrepStmts(context.Background(), singleRow, "SELECT email FROM users WHERE id = ?;", "VZWeOq2p")
 			So(err, ShouldBeNil)
 
 			err = row.Scan(&d.Email)
@@ -308,10 +326,12 @@ func TestExecPrepStmts(t *testing.T) {
 		})
 
 		Convey("multiRows queryType having the correct values, should return the fetched data and a nil value error", func() {
-			rows, _, err := execPrepStmts(multiRows, "SELECT email FROM users LIMIT 5;")
+			rows, _, err := s.execPrepStmts(context.Background(), multiRows, "SELECT email FROM users LIMIT 5;")
 
 			So(err, ShouldBeNil)
 
+			defer rows.Close()
+
 			count := 0
 
 			for rows.Next() {
@@ -322,16 +342,3 @@ func TestExecPrepStmts(t *testing.T) {
 		})
 	})
 }
-
-// cloneDb makes a deep copy of the database connection
-// that is used exclusively for testing.
-func cloneDb() *sql.DB {
-	x := reflect.ValueOf(db)
-	copy := &sql.DB{}
-	starX := x.Elem()
-	y := reflect.New(starX.Type())
-	starY := y.Elem()
-	starY.Set(starX)
-	reflect.ValueOf(copy).Elem().Set(y.Elem())
-	return copy
-}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// The following errors are returned by every store so that the callers can check the
+// cause of an error using errors.Is whatever the driver used.
+var (
+	// ErrNotFound is returned when the record requested or the record it refers to
+	// does not exist.
+	ErrNotFound = errors.New("datastore: record not found")
+
+	// ErrDuplicate is returned when the record being created already exists.
+	ErrDuplicate = errors.New("datastore: duplicate record found")
+)
+
+const invalidData = "datastore: invalid %s found : '%v'"
+
+// The following MySQL error numbers are mapped to the datastore errors.
+const (
+	// mysqlDuplicateEntry is returned when a unique key is violated.
+	mysqlDuplicateEntry = 1062
+
+	// mysqlNoReferencedRow is returned when a foreign key refers to a missing row.
+	mysqlNoReferencedRow = 1452
+)
+
+// ErrInvalidInput is returned when a value provided to the store is invalid. Field holds
+// the name of the invalid value and Value describes why it is invalid.
+type ErrInvalidInput struct {
+	Field string
+	Value interface{}
+}
+
+// Error returns the description of the invalid value.
+func (e *ErrInvalidInput) Error() string {
+	return fmt.Sprintf(invalidData, e.Field, e.Value)
+}
+
+// mapDriverError maps the errors returned by the MySQL and the SQLite drivers to the
+// datastore errors. The driver error message is kept in the error returned.
+func mapDriverError(err error) error {
+	var kind error
+
+	switch e := err.(type) {
+	case *mysql.MySQLError:
+		switch e.Number {
+		case mysqlDuplicateEntry:
+			kind = ErrDuplicate
+
+		case mysqlNoReferencedRow:
+			kind = ErrNotFound
+		}
+
+	case sqlite3.Error:
+		switch e.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			kind = ErrDuplicate
+
+		case sqlite3.ErrConstraintForeignKey:
+			kind = ErrNotFound
+		}
+
+	default:
+		if err == sql.ErrNoRows {
+			kind = ErrNotFound
+		}
+	}
+
+	if kind == nil {
+		return err
+	}
+
+	return fmt.Errorf("%w :: %s", kind, err.Error())
+}
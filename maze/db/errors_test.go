@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMapDriverError tests the functionality of mapDriverError
+func TestMapDriverError(t *testing.T) {
+	Convey("TestMapDriverError: Given the error returned by a driver", t, func() {
+		Convey("a nil error should remain nil", func() {
+			So(mapDriverError(nil), ShouldBeNil)
+		})
+
+		Convey("a missing row should be mapped to ErrNotFound", func() {
+			err := mapDriverError(sql.ErrNoRows)
+
+			So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "sql: no rows in result set")
+		})
+
+		Convey("the duplicate entry errors of both drivers should be mapped to ErrDuplicate", func() {
+			So(errors.Is(mapDriverError(&mysql.MySQLError{Number: 1062}), ErrDuplicate), ShouldBeTrue)
+			So(errors.Is(mapDriverError(sqlite3.Error{Code: sqlite3.ErrConstraint,
+				ExtendedCode: sqlite3.ErrConstraintUnique}), ErrDuplicate), ShouldBeTrue)
+			So(errors.Is(mapDriverError(sqlite3.Error{Code: sqlite3.ErrConstraint,
+				ExtendedCode: sqlite3.ErrConstraintPrimaryKey}), ErrDuplicate), ShouldBeTrue)
+		})
+
+		Convey("the missing foreign key errors of both drivers should be mapped to ErrNotFound", func() {
+			So(errors.Is(mapDriverError(&mysql.MySQLError{Number: 1452}), ErrNotFound), ShouldBeTrue)
+			So(errors.Is(mapDriverError(sqlite3.Error{Code: sqlite3.ErrConstraint,
+				ExtendedCode: sqlite3.ErrConstraintForeignKey}), ErrNotFound), ShouldBeTrue)
+		})
+
+		Convey("any other error should be returned as it is", func() {
+			err := errors.New("sql: database is closed")
+
+			So(mapDriverError(err), ShouldEqual, err)
+			So(mapDriverError(&mysql.MySQLError{Number: 1064}), ShouldResemble, &mysql.MySQLError{Number: 1064})
+		})
+	})
+}
+
+// TestErrInvalidInput tests the functionality of ErrInvalidInput
+func TestErrInvalidInput(t *testing.T) {
+	Convey("TestErrInvalidInput: Given an invalid value used by a store", t, func() {
+		store := NewMemoryStore()
+		defer store.Close()
+
+		Convey("the name of the invalid field should be available using errors.As", func() {
+			err := store.UpdateLevelScore(context.Background(), &UserInfor{TapooID: "VZWeOq2p", Level: 2}, -4)
+
+			var invalid *ErrInvalidInput
+
+			So(errors.As(err, &invalid), ShouldBeTrue)
+			So(invalid.Field, ShouldEqual, "high scores")
+			So(invalid.Value, ShouldEqual, -4)
+			So(err.Error(), ShouldEqual, "datastore: invalid high scores found : '-4'")
+		})
+	})
+}
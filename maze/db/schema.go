@@ -0,0 +1,412 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Migration defines a versioned change of the database schema. Applied and AppliedAt
+// are only set on the migrations returned by MigrationStatus.
+type Migration struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+
+	// up and down hold the statements that apply and revert the migration on every
+	// SQL driver supported. A driver without statements needs no schema change.
+	up, down map[string][]string
+}
+
+// Migrator is implemented by the stores whose schema is versioned.
+type Migrator interface {
+	// MigrateUp applies the pending migrations in ascending version order. Only the
+	// first steps migrations are applied unless steps is less than one. If dryRun is set
+	// nothing is applied. The statements applied or to be applied are returned.
+	MigrateUp(ctx context.Context, steps int, dryRun bool) ([]string, error)
+
+	// MigrateDown reverts the applied migrations in descending version order. Only the
+	// last steps migrations are reverted unless steps is less than one. If dryRun is set
+	// nothing is reverted. The statements run or to be run are returned.
+	MigrateDown(ctx context.Context, steps int, dryRun bool) ([]string, error)
+
+	// MigrationStatus returns all the migrations known indicating those already applied.
+	MigrationStatus(ctx context.Context) ([]Migration, error)
+}
+
+// migrations holds every schema change in the order they should be applied. A migration
+// should never be edited once released, a new one should be appended instead.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_users_and_scores",
+		up: map[string][]string{
+			mysqlDriver: {
+				`CREATE TABLE IF NOT EXISTS users (uuid CHAR(36) NOT NULL, id VARCHAR(64) NOT NULL, email VARCHAR(64) NULL, ` +
+					`created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT  CURRENT_TIMESTAMP ON ` +
+					`UPDATE CURRENT_TIMESTAMP, PRIMARY KEY(uuid), KEY (id), KEY (email), UNIQUE(id) )ENGINE=InnoDB DEFAULT CHARSET=latin1;`,
+
+				`CREATE TABLE IF NOT EXISTS scores (uuid CHAR(36) NOT NULL, user_id VARCHAR(64) NOT NULL, game_level INT ` +
+					`DEFAULT 0, high_scores INT DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP ` +
+					`DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, PRIMARY KEY(uuid), FOREIGN KEY(user_id) REFERENCES ` +
+					`users(id), KEY(game_level), KEY(high_scores), UNIQUE(user_id, game_level) )ENGINE=InnoDB DEFAULT CHARSET=latin1;`,
+			},
+
+			sqliteDriver: {
+				`CREATE TABLE IF NOT EXISTS users (uuid CHAR(36) NOT NULL PRIMARY KEY, id VARCHAR(64) NOT NULL UNIQUE, ` +
+					`email VARCHAR(64) NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP ` +
+					`DEFAULT CURRENT_TIMESTAMP);`,
+
+				`CREATE TABLE IF NOT EXISTS scores (uuid CHAR(36) NOT NULL PRIMARY KEY, user_id VARCHAR(64) NOT NULL ` +
+					`REFERENCES users(id), game_level INT DEFAULT 0, high_scores INT DEFAULT 0, created_at TIMESTAMP ` +
+					`DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, UNIQUE(user_id, game_level));`,
+
+				`CREATE INDEX IF NOT EXISTS scores_game_level ON scores (game_level);`,
+
+				`CREATE INDEX IF NOT EXISTS scores_high_scores ON scores (high_scores);`,
+			},
+		},
+		down: map[string][]string{
+			mysqlDriver:  {`DROP TABLE IF EXISTS scores;`, `DROP TABLE IF EXISTS users;`},
+			sqliteDriver: {`DROP TABLE IF EXISTS scores;`, `DROP TABLE IF EXISTS users;`},
+		},
+	},
+	{
+		// SQLite always stores the text as UTF-8 thus only MySQL needs the change.
+		Version: 2,
+		Name:    "convert_tables_to_utf8mb4",
+		up: map[string][]string{
+			mysqlDriver: {
+				`SET FOREIGN_KEY_CHECKS = 0;`,
+				`ALTER TABLE users CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;`,
+				`ALTER TABLE scores CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;`,
+				`SET FOREIGN_KEY_CHECKS = 1;`,
+			},
+		},
+		down: map[string][]string{
+			mysqlDriver: {
+				`SET FOREIGN_KEY_CHECKS = 0;`,
+				`ALTER TABLE scores CONVERT TO CHARACTER SET latin1;`,
+				`ALTER TABLE users CONVERT TO CHARACTER SET latin1;`,
+				`SET FOREIGN_KEY_CHECKS = 1;`,
+			},
+		},
+	},
+	{
+		// every completed attempt is recorded so that the leaderboards can be limited to a
+		// period. The current high scores are recorded as the first attempts.
+		Version: 3,
+		Name:    "create_attempts_and_seasons",
+		up: map[string][]string{
+			mysqlDriver: {
+				`CREATE TABLE IF NOT EXISTS attempts (uuid CHAR(36) NOT NULL, user_id VARCHAR(64) NOT NULL, game_level INT ` +
+					`NOT NULL, scores INT NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY(uuid), ` +
+					`FOREIGN KEY(user_id) REFERENCES users(id), KEY(game_level, created_at), KEY(user_id, game_level) ` +
+					`)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+
+				`CREATE TABLE IF NOT EXISTS seasons (name VARCHAR(64) NOT NULL, starts_at DATETIME NOT NULL, ends_at ` +
+					`DATETIME NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY(name), KEY(starts_at) ` +
+					`)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;`,
+
+				`INSERT INTO attempts (uuid, user_id, game_level, scores, created_at) SELECT uuid, user_id, game_level, ` +
+					`high_scores, updated_at FROM scores WHERE high_scores > 0;`,
+			},
+
+			sqliteDriver: {
+				`CREATE TABLE IF NOT EXISTS attempts (uuid CHAR(36) NOT NULL PRIMARY KEY, user_id VARCHAR(64) NOT NULL ` +
+					`REFERENCES users(id), game_level INT NOT NULL, scores INT NOT NULL, created_at TIMESTAMP NOT NULL ` +
+					`DEFAULT CURRENT_TIMESTAMP);`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_game_level ON attempts (game_level, created_at);`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_user_id ON attempts (user_id, game_level);`,
+
+				`CREATE TABLE IF NOT EXISTS seasons (name VARCHAR(64) NOT NULL PRIMARY KEY, starts_at TIMESTAMP NOT NULL, ` +
+					`ends_at TIMESTAMP NOT NULL, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP);`,
+
+				// the attempts times are stored in the format used by the driver to bind the
+				// times so that they can be compared as text.
+				`INSERT INTO attempts (uuid, user_id, game_level, scores, created_at) SELECT uuid, user_id, game_level, ` +
+					`high_scores, strftime('%Y-%m-%d %H:%M:%S+00:00', updated_at) FROM scores WHERE high_scores > 0;`,
+			},
+		},
+		down: map[string][]string{
+			mysqlDriver:  {`DROP TABLE IF EXISTS seasons;`, `DROP TABLE IF EXISTS attempts;`},
+			sqliteDriver: {`DROP TABLE IF EXISTS seasons;`, `DROP TABLE IF EXISTS attempts;`},
+		},
+	},
+	{
+		// the failed and the quit levels are recorded as attempts too, they are deleted once
+		// reverted. The duration is stored in milliseconds. The existing attempts all succeeded.
+		Version: 4,
+		Name:    "add_attempts_history",
+		up: map[string][]string{
+			mysqlDriver: {
+				`ALTER TABLE attempts ADD COLUMN seed BIGINT NOT NULL DEFAULT 0, ADD COLUMN outcome VARCHAR(16) NOT NULL ` +
+					`DEFAULT 'succeeded', ADD COLUMN duration INT NOT NULL DEFAULT 0, ADD COLUMN moves INT NOT NULL DEFAULT 0, ` +
+					`ADD KEY attempts_user_id_created_at (user_id, created_at);`,
+			},
+
+			sqliteDriver: {
+				`ALTER TABLE attempts ADD COLUMN seed BIGINT NOT NULL DEFAULT 0;`,
+				`ALTER TABLE attempts ADD COLUMN outcome VARCHAR(16) NOT NULL DEFAULT 'succeeded';`,
+				`ALTER TABLE attempts ADD COLUMN duration INT NOT NULL DEFAULT 0;`,
+				`ALTER TABLE attempts ADD COLUMN moves INT NOT NULL DEFAULT 0;`,
+				`CREATE INDEX IF NOT EXISTS attempts_user_id_created_at ON attempts (user_id, created_at);`,
+			},
+		},
+		down: map[string][]string{
+			mysqlDriver: {
+				`DELETE FROM attempts WHERE outcome <> 'succeeded';`,
+
+				`ALTER TABLE attempts DROP KEY attempts_user_id_created_at, DROP COLUMN seed, DROP COLUMN outcome, DROP COLUMN duration, ` +
+					`DROP COLUMN moves;`,
+			},
+
+			// older SQLite versions cannot drop columns thus the table is copied without them.
+			sqliteDriver: {
+				`DROP INDEX IF EXISTS attempts_user_id_created_at;`,
+
+				`CREATE TABLE attempts_v3 (uuid CHAR(36) NOT NULL PRIMARY KEY, user_id VARCHAR(64) NOT NULL ` +
+					`REFERENCES users(id), game_level INT NOT NULL, scores INT NOT NULL, created_at TIMESTAMP NOT NULL ` +
+					`DEFAULT CURRENT_TIMESTAMP);`,
+
+				`INSERT INTO attempts_v3 (uuid, user_id, game_level, scores, created_at) SELECT uuid, user_id, ` +
+					`game_level, scores, created_at FROM attempts WHERE outcome = 'succeeded';`,
+
+				`DROP TABLE attempts;`,
+
+				`ALTER TABLE attempts_v3 RENAME TO attempts;`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_game_level ON attempts (game_level, created_at);`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_user_id ON attempts (user_id, game_level);`,
+			},
+		},
+	},
+	{
+		// the replays of the attempts are stored as JSON so that the levels can be raced
+		// against the best attempts. The attempts recorded before have no replay.
+		Version: 5,
+		Name:    "add_attempts_replay",
+		up: map[string][]string{
+			mysqlDriver:  {`ALTER TABLE attempts ADD COLUMN replay MEDIUMTEXT NULL;`},
+			sqliteDriver: {`ALTER TABLE attempts ADD COLUMN replay TEXT NULL;`},
+		},
+		down: map[string][]string{
+			mysqlDriver: {`ALTER TABLE attempts DROP COLUMN replay;`},
+
+			// older SQLite versions cannot drop columns thus the table is copied without it.
+			sqliteDriver: {
+				`DROP INDEX IF EXISTS attempts_game_level;`,
+
+				`DROP INDEX IF EXISTS attempts_user_id;`,
+
+				`DROP INDEX IF EXISTS attempts_user_id_created_at;`,
+
+				`CREATE TABLE attempts_v4 (uuid CHAR(36) NOT NULL PRIMARY KEY, user_id VARCHAR(64) NOT NULL ` +
+					`REFERENCES users(id), game_level INT NOT NULL, scores INT NOT NULL, created_at TIMESTAMP NOT NULL ` +
+					`DEFAULT CURRENT_TIMESTAMP, seed BIGINT NOT NULL DEFAULT 0, outcome VARCHAR(16) NOT NULL DEFAULT ` +
+					`'succeeded', duration INT NOT NULL DEFAULT 0, moves INT NOT NULL DEFAULT 0);`,
+
+				`INSERT INTO attempts_v4 (uuid, user_id, game_level, scores, created_at, seed, outcome, duration, moves) ` +
+					`SELECT uuid, user_id, game_level, scores, created_at, seed, outcome, duration, moves FROM attempts;`,
+
+				`DROP TABLE attempts;`,
+
+				`ALTER TABLE attempts_v4 RENAME TO attempts;`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_game_level ON attempts (game_level, created_at);`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_user_id ON attempts (user_id, game_level);`,
+
+				`CREATE INDEX IF NOT EXISTS attempts_user_id_created_at ON attempts (user_id, created_at);`,
+			},
+		},
+	},
+}
+
+// createMigrationsTable holds the statement that creates the table recording the
+// migrations applied on every driver.
+var createMigrationsTable = map[string]string{
+	mysqlDriver: `CREATE TABLE IF NOT EXISTS schema_migrations (version INT NOT NULL, name VARCHAR(128) NOT NULL, ` +
+		`applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY(version) )ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`,
+
+	sqliteDriver: `CREATE TABLE IF NOT EXISTS schema_migrations (version INT NOT NULL PRIMARY KEY, ` +
+		`name VARCHAR(128) NOT NULL, applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP);`,
+}
+
+// countMigrationsTable holds the statement that checks if the migrations table exists
+// on every driver.
+var countMigrationsTable = map[string]string{
+	mysqlDriver: `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() ` +
+		`and table_name = 'schema_migrations';`,
+
+	sqliteDriver: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' and name = 'schema_migrations';`,
+}
+
+// MigrationStatus returns all the migrations known indicating those already applied.
+func (s *sqlStore) MigrationStatus(ctx context.Context) ([]Migration, error) {
+	applied, err := s.getAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Migration, 0, len(migrations))
+
+	for _, m := range migrations {
+		m.AppliedAt, m.Applied = applied[m.Version]
+		status = append(status, m)
+	}
+
+	return status, nil
+}
+
+// MigrateUp applies the pending migrations in ascending version order.
+func (s *sqlStore) MigrateUp(ctx context.Context, steps int, dryRun bool) ([]string, error) {
+	status, err := s.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0, len(status))
+
+	for _, m := range status {
+		if !m.Applied {
+			pending = append(pending, m)
+		}
+	}
+
+	return s.runMigrations(ctx, getMigrationSteps(pending, steps), true, dryRun)
+}
+
+// MigrateDown reverts the applied migrations in descending version order.
+func (s *sqlStore) MigrateDown(ctx context.Context, steps int, dryRun bool) ([]string, error) {
+	status, err := s.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(status))
+
+	for _, m := range status {
+		if m.Applied {
+			applied = append(applied, m)
+		}
+	}
+
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Version > applied[j].Version })
+
+	return s.runMigrations(ctx, getMigrationSteps(applied, steps), false, dryRun)
+}
+
+// getAppliedMigrations fetches the time every applied migration was applied mapped by
+// its version. No migration has been applied if the migrations table does not exist.
+func (s *sqlStore) getAppliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, countMigrationsTable[s.driver]).Scan(&count); err != nil || count == 0 {
+		return applied, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations;`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// runMigrations applies or reverts the migrations provided in the order provided. Every
+// migration runs in its own transaction together with the update of the migrations table.
+func (s *sqlStore) runMigrations(ctx context.Context, list []Migration, up, dryRun bool) ([]string, error) {
+	var (
+		stmts     []string
+		direction = "down"
+	)
+
+	if up {
+		direction = "up"
+	}
+
+	if !dryRun && len(list) > 0 {
+		if _, err := s.db.ExecContext(ctx, createMigrationsTable[s.driver]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range list {
+		queries := m.down[s.driver]
+		record, args := `DELETE FROM schema_migrations WHERE version = ?;`, []interface{}{m.Version}
+
+		if up {
+			queries = m.up[s.driver]
+			record, args = `INSERT INTO schema_migrations (version, name) VALUES (?, ?);`, []interface{}{m.Version, m.Name}
+		}
+
+		stmts = append(stmts, fmt.Sprintf("-- %d_%s.%s", m.Version, m.Name, direction))
+		stmts = append(stmts, queries...)
+
+		if dryRun {
+			continue
+		}
+
+		if err := s.applyMigration(ctx, queries, record, args...); err != nil {
+			return stmts, fmt.Errorf("migration %d_%s.%s failed :: %v", m.Version, m.Name, direction, err)
+		}
+	}
+
+	return stmts, nil
+}
+
+// applyMigration runs the migration queries and the query recording it in a transaction.
+// MySQL commits the schema changes implicitly thus the transaction only guarantees that the
+// migration is recorded if all its queries succeed.
+func (s *sqlStore) applyMigration(ctx context.Context, queries []string, record string, args ...interface{}) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, query := range queries {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getMigrationSteps returns the first steps migrations provided, all of them if steps is
+// less than one.
+func getMigrationSteps(list []Migration, steps int) []Migration {
+	if steps < 1 || steps > len(list) {
+		return list
+	}
+
+	return list[:steps]
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestMigrations tests the functionality of MigrateUp, MigrateDown and MigrationStatus
+func TestMigrations(t *testing.T) {
+	Convey("TestMigrations: Given a SQLite store whose schema has not been migrated", t, func() {
+		dir, cleanUp := getTempDir()
+		defer cleanUp()
+
+		ctx := context.Background()
+
+		store, err := Open(ctx, Config{Driver: sqliteDriver, DbPath: filepath.Join(dir, "tapoo.db"),
+			SkipMigrations: true})
+		So(err, ShouldBeNil)
+
+		defer store.Close()
+
+		m, ok := store.(Migrator)
+		So(ok, ShouldBeTrue)
+
+		// countTables returns the number of tables found in the SQLite database.
+		countTables := func() int {
+			var count int
+			err := store.(*sqlStore).db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table';`).Scan(&count)
+			So(err, ShouldBeNil)
+			return count
+		}
+
+		Convey("all the migrations should be pending", func() {
+			status, err := m.MigrationStatus(ctx)
+
+			So(err, ShouldBeNil)
+			So(status, ShouldHaveLength, len(migrations))
+
+			for i, item := range status {
+				So(item.Version, ShouldEqual, i+1)
+				So(item.Applied, ShouldBeFalse)
+			}
+		})
+
+		Convey("a dry run should return the statements without applying them", func() {
+			stmts, err := m.MigrateUp(ctx, 0, true)
+
+			So(err, ShouldBeNil)
+			So(stmts[0], ShouldEqual, "-- 1_create_users_and_scores.up")
+			So(stmts, ShouldContain, "-- 2_convert_tables_to_utf8mb4.up")
+			So(countTables(), ShouldEqual, 0)
+		})
+
+		Convey("migrating up a single step should only apply the first migration", func() {
+			stmts, err := m.MigrateUp(ctx, 1, false)
+
+			So(err, ShouldBeNil)
+			So(stmts, ShouldHaveLength, 1+len(migrations[0].up[sqliteDriver]))
+
+			status, err := m.MigrationStatus(ctx)
+
+			So(err, ShouldBeNil)
+			So(status[0].Applied, ShouldBeTrue)
+			So(status[0].AppliedAt, ShouldHappenBefore, time.Now().Add(time.Second))
+			So(status[1].Applied, ShouldBeFalse)
+
+			Convey("and the tables created should be usable", func() {
+				data, err := store.GetOrCreateUser(ctx, &UserInfor{TapooID: "VZWeOq2p"})
+
+				So(err, ShouldBeNil)
+				So(data.TapooID, ShouldEqual, "VZWeOq2p")
+			})
+
+			Convey("and migrating up again should apply the remaining migrations only", func() {
+				stmts, err = m.MigrateUp(ctx, 0, false)
+
+				So(err, ShouldBeNil)
+				So(stmts, ShouldHaveLength, 2+len(migrations[2].up[sqliteDriver])+1+len(migrations[3].up[sqliteDriver])+
+					1+len(migrations[4].up[sqliteDriver]))
+				So(stmts[0], ShouldEqual, "-- 2_convert_tables_to_utf8mb4.up")
+				So(stmts[1], ShouldEqual, "-- 3_create_attempts_and_seasons.up")
+				So(stmts, ShouldContain, "-- 4_add_attempts_history.up")
+				So(stmts, ShouldContain, "-- 5_add_attempts_replay.up")
+
+				stmts, err = m.MigrateUp(ctx, 0, false)
+
+				So(err, ShouldBeNil)
+				So(stmts, ShouldBeEmpty)
+			})
+		})
+
+		Convey("migrating down should revert the last migration applied first", func() {
+			_, err := m.MigrateUp(ctx, 0, false)
+			So(err, ShouldBeNil)
+
+			stmts, err := m.MigrateDown(ctx, 1, false)
+
+			So(err, ShouldBeNil)
+			So(stmts, ShouldHaveLength, 1+len(migrations[4].down[sqliteDriver]))
+			So(stmts[0], ShouldEqual, "-- 5_add_attempts_replay.down")
+
+			Convey("and a dry run should leave the tables in place", func() {
+				stmts, err = m.MigrateDown(ctx, 0, true)
+
+				So(err, ShouldBeNil)
+				So(stmts, ShouldContain, "DROP TABLE IF EXISTS users;")
+				So(countTables(), ShouldEqual, 5)
+			})
+
+			Convey("and reverting all the migrations should drop the tables", func() {
+				_, err = m.MigrateDown(ctx, 0, false)
+				So(err, ShouldBeNil)
+
+				status, err := m.MigrationStatus(ctx)
+
+				So(err, ShouldBeNil)
+				So(status[0].Applied, ShouldBeFalse)
+
+				// only the migrations table should be left.
+				So(countTables(), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+// TestGetMigrationSteps tests the functionality of getMigrationSteps
+func TestGetMigrationSteps(t *testing.T) {
+	Convey("TestGetMigrationSteps: Given the migrations and the number of steps", t, func() {
+		Convey("all the migrations should be returned if steps is less than one or too large", func() {
+			So(getMigrationSteps(migrations, 0), ShouldHaveLength, len(migrations))
+			So(getMigrationSteps(migrations, -3), ShouldHaveLength, len(migrations))
+			So(getMigrationSteps(migrations, len(migrations)+1), ShouldHaveLength, len(migrations))
+		})
+
+		Convey("only the first steps migrations should be returned otherwise", func() {
+			So(getMigrationSteps(migrations, 1), ShouldHaveLength, 1)
+			So(getMigrationSteps(migrations, 1)[0].Version, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,141 @@
+package db
+
+import "context"
+
+// Store defines the storage of the tapoo users, their level scores and the level
+// leaderboards. MySQL, SQLite and in-memory stores are supported while the api package
+// provides a store backed by the tapoo REST API. Every method stops once the context
+// provided is cancelled and is safe to be called concurrently.
+type Store interface {
+	// GetOrCreateUser creates the new user with tapoo ID provided if the
+	// it does not exists. Email used can be empty or not.
+	GetOrCreateUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error)
+
+	// GetUser fetches the user with the tapoo ID provided. ErrNotFound is returned if
+	// the user does not exist.
+	GetUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error)
+
+	// UpdateUser should update the tapoo user information. While updating a user,
+	// the email should not be empty otherwise an error will be returned.
+	UpdateUser(ctx context.Context, u *UserInfor) error
+
+	// GetOrCreateLevelScore fetches or creates data about the user for the specific level.
+	// This methods is called every time a new game starts for every level except the training level (level 0).
+	GetOrCreateLevelScore(ctx context.Context, u *UserInfor) (*LevelScoreResponse, error)
+
+	// UpdateLevelScore updates the user high scores for the provided level.
+	// This method should only be invoked when the specific level is completed successfully.
+	// The scores are also recorded as an attempt completed now.
+	UpdateLevelScore(ctx context.Context, u *UserInfor, highScores int) error
+
+	// SaveHighScores creates the user level scores if they don't exist and replaces the
+	// stored high scores only if the high scores provided are higher. The check and the
+	// update happen atomically. The high scores provided are recorded as a succeeded attempt
+	// completed now whether they are higher or not. The level scores stored afterwards are returned.
+	SaveHighScores(ctx context.Context, u *UserInfor, highScores int) (*LevelScoreResponse, error)
+
+	// SaveAttempt records the level attempt provided as completed now, creating the user
+	// level scores first if they don't exist. The scores of a succeeded attempt replace the
+	// stored high scores only if they are higher. The level scores stored afterwards are returned.
+	SaveAttempt(ctx context.Context, a *Attempt) (*LevelScoreResponse, error)
+
+	// GetAttempts fetches the page of the user attempts history requested, the latest first.
+	GetAttempts(ctx context.Context, q *AttemptsQuery) ([]*Attempt, error)
+
+	// GetBestAttempt fetches the succeeded attempt with the highest scores on the level
+	// provided among the attempts whose replay was recorded, the earliest first if they
+	// tie. The attempts of every user are considered if the tapoo ID is empty. ErrNotFound
+	// is returned if no such attempt exists.
+	GetBestAttempt(ctx context.Context, u *UserInfor) (*Attempt, error)
+
+	// GetStats fetches the summary of the levels played by the user. ErrNotFound is returned
+	// if the user does not exist.
+	GetStats(ctx context.Context, u *UserInfor) (*Stats, error)
+
+	// GetHighestClearedLevel fetches the highest level the user has completed successfully.
+	// If the user has not cleared any level yet, -1 is returned.
+	GetHighestClearedLevel(ctx context.Context, u *UserInfor) (int, error)
+
+	// GetTopScores fetches the top limit high scores for the provided level in
+	// descending order. Ties are ordered by the earliest update and then by the tapoo ID.
+	GetTopScores(ctx context.Context, u *UserInfor, limit int) ([]*LevelScoreResponse, error)
+
+	// GetLeaderboard fetches the ranked entries of the leaderboard page requested. The
+	// entries are ordered like the top scores. The level GlobalLeaderboard ranks the users
+	// by the sum of their high scores on every level. A leaderboard limited to a period
+	// ranks the best attempts completed in the period.
+	GetLeaderboard(ctx context.Context, q *LeaderboardQuery) ([]*LeaderboardEntry, error)
+
+	// GetUserRank fetches the entry of the user on the leaderboard together with at most
+	// neighbors entries ranked above and below it. The limit and the offset of the query are
+	// ignored. ErrNotFound is returned if the user has no entry on the leaderboard.
+	GetUserRank(ctx context.Context, u *UserInfor, q *LeaderboardQuery, neighbors int) ([]*LeaderboardEntry, error)
+
+	// CreateSeason creates the season provided. ErrDuplicate is returned if a season with
+	// the same name exists.
+	CreateSeason(ctx context.Context, s *Season) error
+
+	// GetSeason fetches the season with the name provided. ErrNotFound is returned if the
+	// season does not exist.
+	GetSeason(ctx context.Context, name string) (*Season, error)
+
+	// GetSeasons fetches all the seasons ordered by their start.
+	GetSeasons(ctx context.Context) ([]*Season, error)
+
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// checkTapooID checks if the user tapoo ID is valid.
+func (u *UserInfor) checkTapooID() error {
+	switch {
+	case len(u.TapooID) == 0:
+		return &ErrInvalidInput{Field: "Tapoo ID", Value: u.TapooID + "(empty)"}
+
+	case len(u.TapooID) > 64:
+		return &ErrInvalidInput{Field: "Tapoo ID", Value: u.TapooID[:10] + "... (Too long)"}
+	}
+
+	return nil
+}
+
+// checkEmail checks if the user email is valid. The email is required
+// only if the user information is being updated.
+func (u *UserInfor) checkEmail(required bool) error {
+	switch {
+	case len(u.Email) == 0 && required:
+		return &ErrInvalidInput{Field: "Email", Value: u.Email + "(empty)"}
+
+	case len(u.Email) > 64:
+		return &ErrInvalidInput{Field: "Email", Value: u.Email[:10] + "... (Too long)"}
+	}
+
+	return nil
+}
+
+// checkLevel checks if the user game level is valid.
+func (u *UserInfor) checkLevel() error {
+	if u.Level < 0 {
+		return &ErrInvalidInput{Field: "game level", Value: u.Level}
+	}
+
+	return nil
+}
+
+// checkLimit checks if the maximum number of records requested is valid.
+func checkLimit(limit int) error {
+	if limit < 1 {
+		return &ErrInvalidInput{Field: "limit", Value: limit}
+	}
+
+	return nil
+}
+
+// checkHighScores checks if the high scores provided are valid.
+func checkHighScores(highScores int) error {
+	if highScores < 0 {
+		return &ErrInvalidInput{Field: "high scores", Value: highScores}
+	}
+
+	return nil
+}
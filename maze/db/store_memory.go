@@ -0,0 +1,693 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errStoreClosed is returned when a closed memory store is used.
+var errStoreClosed = errors.New("datastore: the store is closed")
+
+// memoryStore implements the Store interface in memory. The data is lost once the
+// store is closed thus it is best suited for the tests and the games played offline.
+type memoryStore struct {
+	mu     sync.Mutex
+	closed bool
+	users  map[string]*UserInfoResponse
+
+	// scores holds the level scores of every user mapped by the tapoo ID and the level.
+	scores map[string]map[int]*LevelScoreResponse
+
+	// attempts holds every level played in the order they were completed.
+	attempts []*Attempt
+
+	// seasons holds the seasons mapped by their names.
+	seasons map[string]*Season
+}
+
+// NewMemoryStore creates a new empty store that holds the data in memory.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		users:   make(map[string]*UserInfoResponse),
+		scores:  make(map[string]map[int]*LevelScoreResponse),
+		seasons: make(map[string]*Season),
+	}
+}
+
+// Close discards all the data held by the store.
+func (m *memoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed, m.users, m.scores, m.attempts, m.seasons = true, nil, nil, nil, nil
+
+	return nil
+}
+
+// lock locks the store if the context provided is not done and the store is not closed.
+// The store is left unlocked if an error is returned.
+func (m *memoryStore) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+
+	if m.closed {
+		m.mu.Unlock()
+		return errStoreClosed
+	}
+
+	return nil
+}
+
+// GetOrCreateUser creates the new user with tapoo ID provided if the
+// it does not exists. Email used can be empty or not.
+func (m *memoryStore) GetOrCreateUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkEmail(false); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	user, ok := m.users[u.TapooID]
+	if !ok {
+		now := time.Now()
+		user = &UserInfoResponse{TapooID: u.TapooID, Email: u.Email, CreatedAt: now, UpdateAt: now}
+		m.users[u.TapooID] = user
+	}
+
+	d := *user
+	return &d, nil
+}
+
+// GetUser fetches the user with the tapoo ID provided.
+func (m *memoryStore) GetUser(ctx context.Context, u *UserInfor) (*UserInfoResponse, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	user, ok := m.users[u.TapooID]
+	if !ok {
+		return nil, fmt.Errorf("%w :: user %s", ErrNotFound, u.TapooID)
+	}
+
+	d := *user
+	return &d, nil
+}
+
+// UpdateUser should update the tapoo user information.
+// While updating a user, the email should not be empty otherwise
+// an error will be returned.
+func (m *memoryStore) UpdateUser(ctx context.Context, u *UserInfor) error {
+	if err := u.checkTapooID(); err != nil {
+		return err
+	}
+
+	if err := u.checkEmail(true); err != nil {
+		return err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+
+	defer m.mu.Unlock()
+
+	if user, ok := m.users[u.TapooID]; ok {
+		user.Email, user.UpdateAt = u.Email, time.Now()
+	}
+
+	return nil
+}
+
+// GetOrCreateLevelScore fetches or creates data about the user for the specific level.
+func (m *memoryStore) GetOrCreateLevelScore(ctx context.Context, u *UserInfor) (*LevelScoreResponse, error) {
+	if err := u.checkLevel(); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	score, err := m.getOrCreateLevelScore(u)
+	if err != nil {
+		return nil, err
+	}
+
+	d := *score
+	return &d, nil
+}
+
+// getOrCreateLevelScore fetches the user level scores creating them first if they don't
+// exist. The store should be locked.
+func (m *memoryStore) getOrCreateLevelScore(u *UserInfor) (*LevelScoreResponse, error) {
+	if _, ok := m.users[u.TapooID]; !ok {
+		return nil, fmt.Errorf("%w :: user %s", ErrNotFound, u.TapooID)
+	}
+
+	if m.scores[u.TapooID] == nil {
+		m.scores[u.TapooID] = make(map[int]*LevelScoreResponse)
+	}
+
+	score, ok := m.scores[u.TapooID][u.Level]
+	if !ok {
+		now := time.Now()
+		score = &LevelScoreResponse{TapooID: u.TapooID, Level: u.Level, CreatedAt: now, UpdateAt: now}
+		m.scores[u.TapooID][u.Level] = score
+	}
+
+	return score, nil
+}
+
+// SaveHighScores creates the user level scores if they don't exist and replaces the
+// stored high scores only if the high scores provided are higher.
+func (m *memoryStore) SaveHighScores(ctx context.Context, u *UserInfor, highScores int) (*LevelScoreResponse, error) {
+	return m.SaveAttempt(ctx, &Attempt{TapooID: u.TapooID, Level: u.Level, Outcome: Succeeded, Scores: highScores})
+}
+
+// SaveAttempt records the level attempt provided as completed now, creating the user
+// level scores first if they don't exist. The scores of a succeeded attempt replace the
+// stored high scores only if they are higher.
+func (m *memoryStore) SaveAttempt(ctx context.Context, a *Attempt) (*LevelScoreResponse, error) {
+	if err := a.checkAttempt(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	score, err := m.getOrCreateLevelScore(&UserInfor{TapooID: a.TapooID, Level: a.Level})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if a.Outcome == Succeeded && a.Scores > score.HighScores {
+		score.HighScores, score.UpdateAt = a.Scores, now
+	}
+
+	attempt := *a
+	attempt.CreatedAt = now
+	m.attempts = append(m.attempts, &attempt)
+
+	d := *score
+	return &d, nil
+}
+
+// GetAttempts fetches the page of the user attempts history requested, the latest first.
+func (m *memoryStore) GetAttempts(ctx context.Context, q *AttemptsQuery) ([]*Attempt, error) {
+	attempts := make([]*Attempt, 0)
+
+	if err := q.checkQuery(); err != nil {
+		return attempts, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return attempts, err
+	}
+
+	defer m.mu.Unlock()
+
+	for _, a := range m.getAttempts(q.TapooID) {
+		if q.Level == AllLevels || a.Level == q.Level {
+			attempts = append(attempts, a)
+		}
+	}
+
+	// the latest attempts are returned first, the last recorded first if they were
+	// completed at the same time.
+	for i, j := 0, len(attempts)-1; i < j; i, j = i+1, j-1 {
+		attempts[i], attempts[j] = attempts[j], attempts[i]
+	}
+
+	if q.Offset >= len(attempts) {
+		return attempts[:0], nil
+	}
+
+	attempts = attempts[q.Offset:]
+	if len(attempts) > q.Limit {
+		attempts = attempts[:q.Limit]
+	}
+
+	return attempts, nil
+}
+
+// GetStats fetches the summary of the levels played by the user.
+func (m *memoryStore) GetStats(ctx context.Context, u *UserInfor) (*Stats, error) {
+	if err := u.checkTapooID(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[u.TapooID]; !ok {
+		return nil, fmt.Errorf("%w :: user %s", ErrNotFound, u.TapooID)
+	}
+
+	return newStats(u.TapooID, m.getAttempts(u.TapooID)), nil
+}
+
+// GetBestAttempt fetches the succeeded attempt with the highest scores on the level
+// provided among the attempts whose replay was recorded.
+func (m *memoryStore) GetBestAttempt(ctx context.Context, u *UserInfor) (*Attempt, error) {
+	if err := u.checkBestAttempt(); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	var best *Attempt
+
+	// the attempts are recorded in the order they were completed thus the earliest
+	// attempt wins the ties.
+	for _, a := range m.attempts {
+		if a.Level == u.Level && a.Outcome == Succeeded && a.Replay != nil &&
+			(len(u.TapooID) == 0 || a.TapooID == u.TapooID) && (best == nil || a.Scores > best.Scores) {
+			best = a
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("%w :: best attempt of level %d", ErrNotFound, u.Level)
+	}
+
+	d := *best
+	return &d, nil
+}
+
+// getAttempts returns copies of the user attempts ordered by their time without their
+// replays. m.mu must be held.
+func (m *memoryStore) getAttempts(tapooID string) []*Attempt {
+	var attempts []*Attempt
+
+	for _, a := range m.attempts {
+		if a.TapooID == tapooID {
+			d := *a
+			d.Replay = nil
+			attempts = append(attempts, &d)
+		}
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool { return attempts[i].CreatedAt.Before(attempts[j].CreatedAt) })
+
+	return attempts
+}
+
+// UpdateLevelScore updates the user high scores for the provided level.
+func (m *memoryStore) UpdateLevelScore(ctx context.Context, u *UserInfor, highScores int) error {
+	if err := u.checkLevel(); err != nil {
+		return err
+	}
+
+	if err := checkHighScores(highScores); err != nil {
+		return err
+	}
+
+	if err := u.checkTapooID(); err != nil {
+		return err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+
+	defer m.mu.Unlock()
+
+	if score, ok := m.scores[u.TapooID][u.Level]; ok {
+		now := time.Now()
+
+		score.HighScores, score.UpdateAt = highScores, now
+		m.attempts = append(m.attempts, &Attempt{TapooID: u.TapooID, Level: u.Level, Outcome: Succeeded,
+			Scores: highScores, CreatedAt: now})
+	}
+
+	return nil
+}
+
+// GetHighestClearedLevel fetches the highest level the user has completed successfully.
+// A level is cleared once it has a succeeded attempt or positive high scores. If the user
+// has not cleared any level yet, -1 is returned.
+func (m *memoryStore) GetHighestClearedLevel(ctx context.Context, u *UserInfor) (int, error) {
+	if err := u.checkTapooID(); err != nil {
+		return -1, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return -1, err
+	}
+
+	defer m.mu.Unlock()
+
+	level := -1
+	for _, score := range m.scores[u.TapooID] {
+		if score.HighScores > 0 && score.Level > level {
+			level = score.Level
+		}
+	}
+
+	for _, a := range m.attempts {
+		if a.TapooID == u.TapooID && a.Outcome == Succeeded && a.Level > level {
+			level = a.Level
+		}
+	}
+
+	return level, nil
+}
+
+// GetTopScores fetches the top limit high scores for the provided level. Only the users
+// that have cleared the level are ranked.
+func (m *memoryStore) GetTopScores(ctx context.Context, u *UserInfor, limit int) ([]*LevelScoreResponse, error) {
+	topScores := make([]*LevelScoreResponse, 0)
+
+	if err := u.checkLevel(); err != nil {
+		return topScores, err
+	}
+
+	if err := checkLimit(limit); err != nil {
+		return topScores, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return topScores, err
+	}
+
+	defer m.mu.Unlock()
+
+	for tapooID, levels := range m.scores {
+		if score, ok := levels[u.Level]; ok && m.isCleared(tapooID, score) {
+			d := *score
+			d.Email = m.users[tapooID].Email
+
+			topScores = append(topScores, &d)
+		}
+	}
+
+	// ties are ordered by the earliest update and then by the tapoo ID so that the
+	// results are always the same.
+	sort.Slice(topScores, func(i, j int) bool {
+		switch {
+		case topScores[i].HighScores != topScores[j].HighScores:
+			return topScores[i].HighScores > topScores[j].HighScores
+
+		case !topScores[i].UpdateAt.Equal(topScores[j].UpdateAt):
+			return topScores[i].UpdateAt.Before(topScores[j].UpdateAt)
+		}
+
+		return topScores[i].TapooID < topScores[j].TapooID
+	})
+
+	if len(topScores) > limit {
+		topScores = topScores[:limit]
+	}
+
+	return topScores, nil
+}
+
+// GetLeaderboard fetches the leaderboard page requested.
+func (m *memoryStore) GetLeaderboard(ctx context.Context, q *LeaderboardQuery) ([]*LeaderboardEntry, error) {
+	if err := q.checkQuery(); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	defer m.mu.Unlock()
+
+	return getLeaderboardPage(m.getLeaderboard(q), q), nil
+}
+
+// GetUserRank fetches the entry of the user on the leaderboard together with at most
+// neighbors entries ranked above and below it.
+func (m *memoryStore) GetUserRank(ctx context.Context, u *UserInfor, q *LeaderboardQuery, neighbors int) ([]*LeaderboardEntry, error) {
+	if err := u.checkTapooID(); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	if err := checkNeighbors(q, neighbors); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return []*LeaderboardEntry{}, err
+	}
+
+	defer m.mu.Unlock()
+
+	entries := m.getLeaderboard(q)
+
+	for _, entry := range entries {
+		if entry.TapooID == u.TapooID {
+			return getLeaderboardPage(entries, getNeighborsQuery(q, entry.Rank, neighbors)), nil
+		}
+	}
+
+	return []*LeaderboardEntry{}, fmt.Errorf("%w :: user %s has no level %d scores", ErrNotFound, u.TapooID, q.Level)
+}
+
+// getLeaderboard returns all the ranked entries of the leaderboard. m.mu must be held.
+func (m *memoryStore) getLeaderboard(q *LeaderboardQuery) []*LeaderboardEntry {
+	entries := make([]*LeaderboardEntry, 0)
+
+	for _, score := range m.getLevelScores(q) {
+		if q.Level == GlobalLeaderboard || score.Level == q.Level {
+			entries = append(entries, score)
+		}
+	}
+
+	if q.Level == GlobalLeaderboard {
+		entries = getGlobalEntries(entries)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		switch {
+		case entries[i].HighScores != entries[j].HighScores:
+			return entries[i].HighScores > entries[j].HighScores
+
+		case !entries[i].UpdateAt.Equal(entries[j].UpdateAt):
+			return entries[i].UpdateAt.Before(entries[j].UpdateAt)
+		}
+
+		return entries[i].TapooID < entries[j].TapooID
+	})
+
+	for i, entry := range entries {
+		entry.Rank = i + 1
+	}
+
+	return entries
+}
+
+// getLevelScores returns the high scores of every user level cleared ranked on the leaderboard.
+// If the leaderboard is limited to a period, the best attempts completed in the period
+// are returned instead. m.mu must be held.
+func (m *memoryStore) getLevelScores(q *LeaderboardQuery) []*LeaderboardEntry {
+	var scores []*LeaderboardEntry
+
+	if q.isAllTime() {
+		for tapooID, levels := range m.scores {
+			for _, score := range levels {
+				if !m.isCleared(tapooID, score) {
+					continue
+				}
+
+				scores = append(scores, &LeaderboardEntry{TapooID: tapooID, Level: score.Level,
+					HighScores: score.HighScores, UpdateAt: score.UpdateAt})
+			}
+		}
+
+		return scores
+	}
+
+	type key struct {
+		tapooID string
+		level   int
+	}
+
+	best := make(map[key]*LeaderboardEntry)
+
+	for _, a := range m.attempts {
+		if a.Outcome != Succeeded || (!q.From.IsZero() && a.CreatedAt.Before(q.From)) ||
+			(!q.To.IsZero() && !a.CreatedAt.Before(q.To)) {
+			continue
+		}
+
+		// the earliest of the best attempts is kept.
+		k := key{a.TapooID, a.Level}
+		if score, ok := best[k]; !ok || a.Scores > score.HighScores ||
+			(a.Scores == score.HighScores && a.CreatedAt.Before(score.UpdateAt)) {
+			best[k] = &LeaderboardEntry{TapooID: a.TapooID, Level: a.Level, HighScores: a.Scores, UpdateAt: a.CreatedAt}
+		}
+	}
+
+	for _, score := range best {
+		scores = append(scores, score)
+	}
+
+	return scores
+}
+
+// isCleared checks if the user level scores provided belong to a level the user has cleared
+// the same way GetHighestClearedLevel does. m.mu must be held.
+func (m *memoryStore) isCleared(tapooID string, score *LevelScoreResponse) bool {
+	if score.HighScores > 0 {
+		return true
+	}
+
+	for _, a := range m.attempts {
+		if a.TapooID == tapooID && a.Level == score.Level && a.Outcome == Succeeded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getGlobalEntries aggregates the level scores provided of every user across all the levels.
+// Only the cleared levels are expected to be provided.
+func getGlobalEntries(scores []*LeaderboardEntry) []*LeaderboardEntry {
+	users := make(map[string]*LeaderboardEntry)
+	entries := make([]*LeaderboardEntry, 0)
+
+	for _, score := range scores {
+		entry, ok := users[score.TapooID]
+		if !ok {
+			entry = &LeaderboardEntry{TapooID: score.TapooID, Level: -1}
+			users[score.TapooID] = entry
+			entries = append(entries, entry)
+		}
+
+		entry.HighScores += score.HighScores
+
+		if score.Level > entry.Level {
+			entry.Level = score.Level
+		}
+
+		if score.UpdateAt.After(entry.UpdateAt) {
+			entry.UpdateAt = score.UpdateAt
+		}
+	}
+
+	return entries
+}
+
+// CreateSeason creates the season provided.
+func (m *memoryStore) CreateSeason(ctx context.Context, season *Season) error {
+	if err := season.checkSeason(); err != nil {
+		return err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+
+	defer m.mu.Unlock()
+
+	if _, ok := m.seasons[season.Name]; ok {
+		return fmt.Errorf("%w :: season %s", ErrDuplicate, season.Name)
+	}
+
+	d := *season
+	m.seasons[season.Name] = &d
+
+	return nil
+}
+
+// GetSeason fetches the season with the name provided.
+func (m *memoryStore) GetSeason(ctx context.Context, name string) (*Season, error) {
+	if err := checkSeasonName(name); err != nil {
+		return nil, err
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	defer m.mu.Unlock()
+
+	season, ok := m.seasons[name]
+	if !ok {
+		return nil, fmt.Errorf("%w :: season %s", ErrNotFound, name)
+	}
+
+	d := *season
+	return &d, nil
+}
+
+// GetSeasons fetches all the seasons ordered by their start.
+func (m *memoryStore) GetSeasons(ctx context.Context) ([]*Season, error) {
+	seasons := make([]*Season, 0)
+
+	if err := m.lock(ctx); err != nil {
+		return seasons, err
+	}
+
+	defer m.mu.Unlock()
+
+	for _, season := range m.seasons {
+		d := *season
+		seasons = append(seasons, &d)
+	}
+
+	sort.Slice(seasons, func(i, j int) bool {
+		if !seasons[i].StartsAt.Equal(seasons[j].StartsAt) {
+			return seasons[i].StartsAt.Before(seasons[j].StartsAt)
+		}
+		return seasons[i].Name < seasons[j].Name
+	})
+
+	return seasons, nil
+}
+
+// getLeaderboardPage returns the entries of the leaderboard page requested.
+func getLeaderboardPage(entries []*LeaderboardEntry, q *LeaderboardQuery) []*LeaderboardEntry {
+	if q.Offset >= len(entries) {
+		return []*LeaderboardEntry{}
+	}
+
+	entries = entries[q.Offset:]
+	if len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+
+	return entries
+}
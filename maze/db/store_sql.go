@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const (
+	// noReturnVal indicates the sql query being executed should not
+	// return any value. Return value not expected.
+	noReturnVal int = iota
+
+	// singleRow indicates the sql query bieng executed should only
+	// return a single row of the expected result set.
+	singleRow
+
+	// multiRows indicates the sql query being executed should return
+	// multiple rows of the expected result set.
+	multiRows
+)
+
+// querier defines the methods shared by the database connection pool and a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlStore implements the Store interface on a SQL database. The queries used are
+// supported by both the MySQL and the SQLite databases.
+type sqlStore struct {
+	db     *sql.DB
+	driver string
+
+	// q runs the queries, it is either the connection pool or the current transaction.
+	q querier
+}
+
+// newSQLStore creates a pool of connection that can be used concurrently to access the
+// database of the driver provided. The pending migrations are applied if migrate is set.
+func newSQLStore(ctx context.Context, driver, dataSource string, migrate bool) (*sqlStore, error) {
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("db connection: incorrect database configurations used :: %s", err.Error())
+	}
+
+	s := &sqlStore{db: db, driver: driver, q: db}
+
+	if !migrate {
+		return s, nil
+	}
+
+	if _, err = s.MigrateUp(ctx, 0, false); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close closes the database connection pool.
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn on a copy of the store whose queries run in a single transaction. The
+// transaction is committed if fn returns no error otherwise it is rolled back.
+func (s *sqlStore) withTx(ctx context.Context, fn func(t *sqlStore) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(&sqlStore{db: s.db, driver: s.driver, q: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// execPrepStmts executes the Prepared statement for the sql queries.
+func (s *sqlStore) execPrepStmts(ctx context.Context, queryType int, sqlQuery string,
+	val ...interface{}) (*sql.Rows, *sql.Row, error) {
+	stmt, err := s.q.PrepareContext(ctx, sqlQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer stmt.Close()
+
+	switch queryType {
+	case noReturnVal:
+		_, err = s.q.ExecContext(ctx, sqlQuery, val...)
+		return nil, nil, mapDriverError(err)
+
+	case singleRow:
+		row := s.q.QueryRowContext(ctx, sqlQuery, val...)
+		return nil, row, nil
+
+	case multiRows:
+		rows, err := s.q.QueryContext(ctx, sqlQuery, val...)
+		return rows, nil, err
+
+	default:
+		return nil, nil, &ErrInvalidInput{Field: "queryType", Value: queryType}
+	}
+}
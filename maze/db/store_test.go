@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// getTestDrivers returns the drivers whose stores the tests should run on. The MySQL
+// store is only tested if its environment variables are set.
+func getTestDrivers() []string {
+	drivers := []string{memoryDriver, sqliteDriver}
+
+	if _, ok := os.LookupEnv("TAPOO_DB_HOST"); ok {
+		drivers = append(drivers, mysqlDriver)
+	}
+
+	return drivers
+}
+
+// getTempDir creates a temporary directory and returns a function that deletes it.
+func getTempDir() (string, func()) {
+	dir, err := ioutil.TempDir("", "tapoo_db")
+	if err != nil {
+		panic(err)
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// newTestStore creates the store of the driver provided and loads the sample data into it.
+// The function returned closes the store and deletes any files it created.
+func newTestStore(driver string) (Store, func(), error) {
+	var (
+		c       = Config{Driver: driver}
+		cleanUp = func() {}
+		err     error
+	)
+
+	switch driver {
+	case sqliteDriver:
+		var dir string
+		dir, cleanUp = getTempDir()
+		c.DbPath = filepath.Join(dir, "tapoo.db")
+
+	case mysqlDriver:
+		if c, err = ConfigFromEnv(); err != nil {
+			return nil, cleanUp, err
+		}
+
+		c.Driver = mysqlDriver
+	}
+
+	store, err := Open(context.Background(), c)
+	if err != nil {
+		cleanUp()
+		return nil, func() {}, err
+	}
+
+	closeStore := func() {
+		store.Close()
+		cleanUp()
+	}
+
+	if err = loadSampleData(store); err != nil {
+		closeStore()
+		return nil, func() {}, err
+	}
+
+	return store, closeStore, nil
+}
+
+// loadSampleData loads the users and the scores sample data into the store provided.
+// The MySQL tables are recreated before the data is loaded.
+func loadSampleData(store Store) error {
+	users, err := readCSVFile("sample_data_users.csv")
+	if err != nil {
+		return err
+	}
+
+	scores, err := readCSVFile("sample_data_scores.csv")
+	if err != nil {
+		return err
+	}
+
+	switch s := store.(type) {
+	case *sqlStore:
+		if s.driver == mysqlDriver {
+			if _, err = s.db.Exec("DROP TABLE IF EXISTS seasons, attempts, scores, users, schema_migrations;"); err != nil {
+				return err
+			}
+
+			if _, err = s.MigrateUp(context.Background(), 0, false); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range users {
+			if err = s.createUser(context.Background(), &UserInfor{TapooID: r[1], Email: r[2]}, r[0]); err != nil {
+				return err
+			}
+		}
+
+		for _, r := range scores {
+			query := `INSERT INTO scores (uuid, user_id, game_level, high_scores) VALUES (?, ?, ?, ?);`
+			if _, _, err = s.execPrepStmts(context.Background(), noReturnVal, query, r[0], r[1], r[2], r[3]); err != nil {
+				return err
+			}
+		}
+
+	case *memoryStore:
+		now := time.Now()
+
+		for _, r := range users {
+			s.users[r[1]] = &UserInfoResponse{TapooID: r[1], Email: r[2], CreatedAt: now, UpdateAt: now}
+		}
+
+		for _, r := range scores {
+			level, _ := strconv.Atoi(r[2])
+			highScores, _ := strconv.Atoi(r[3])
+
+			if s.scores[r[1]] == nil {
+				s.scores[r[1]] = make(map[int]*LevelScoreResponse)
+			}
+
+			s.scores[r[1]][level] = &LevelScoreResponse{TapooID: r[1], Level: level,
+				HighScores: highScores, CreatedAt: now, UpdateAt: now}
+		}
+	}
+
+	return nil
+}
+
+// readCSVFile reads the records of the csv file provided without its header.
+func readCSVFile(filePath string) ([][]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return records[1:], nil
+}
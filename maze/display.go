@@ -2,7 +2,6 @@ package maze
 
 import (
 	"fmt"
-	"reflect"
 	"strings"
 
 	termbox "github.com/nsf/termbox-go"
@@ -11,8 +10,8 @@ import (
 const (
 	intro            = "   You are playing the Maze runner, hide and seek game (Tapoo).      "
 	website          = " Visit https://www.tapoo.naihub.com/54ec478gA for more information.  "
-	playerNavigation = "      Use the Arrow Keys to navigate the player (in green)           "
-	statusMsg        = "         Press Space to Pause.         Scores: %d            "
+	playerNavigation = "  Use the Arrow Keys to navigate the player (in green), H for a hint "
+	statusMsg        = "  Press Space to Pause.    Level: %d    Scores: %d    Seed: %d     "
 
 	space              = "                                                                         "
 	pauseMsg           = "                              Game Paused !!!                            "
@@ -20,12 +19,27 @@ const (
 	gameOverFailed     = "      Game Over! : Ooops!!!, Failed to locate the target on time.        "
 	gameOverNavigation = "        Press ESC or Ctrl+C to quit.     Press Ctrl+P to Proceed         "
 	highScores         = "                   High Scores: %d                             "
+	levelTooLarge      = "  The terminal is too small for the next level, resize it and Proceed.  "
+
+	hidingMsg         = "  Player %d: Hide! Press Enter to hide here.    Time Left: %3ds     "
+	hidingAtStartMsg  = "  Player %d: Move away from the start to hide!  Time Left: %3ds     "
+	seekingMsg        = "  Player %d: Seek Player %d!     Time Left: %3ds     Seed: %d      "
+	handoverMsg       = "    Player %d has hidden. Player %d, press Enter to start seeking.     "
+	hiderFound        = "          Round %d of %d: Player %d found Player %d on time.            "
+	hiderNotFound     = "      Round %d of %d: Player %d failed to find Player %d on time.       "
+	hotseatScores     = "              Player 1: %d            Player 2: %d                  "
+	hotseatWinner     = "             Game Over! : Player %d wins the hide and seek.            "
+	hotseatDraw       = "            Game Over! : The hide and seek ended in a draw.            "
+	hotseatNavigation = "        Press ESC or Ctrl+C to quit.     Press Enter to Proceed        "
 )
 
 // fill prints a string to the termbox view box on the given coordinates.
 func fill(x, y int, val string, foreground termbox.Attribute) {
-	for index, char := range val {
+	// the index is counted in characters since the wall characters may span several bytes.
+	index := 0
+	for _, char := range val {
 		termbox.SetCell(x+index, y, char, foreground, coldef)
+		index++
 	}
 }
 
@@ -45,47 +59,97 @@ func drawMaze(config *Dimensions, data [][]string) {
 }
 
 // refreshUI refreshes the scores value and update the player positions.
-func refreshUI(config *Dimensions, count int, data [][]string) {
-	drawMaze(config, data)
-	targetPos := config.FinalPosition
-	startPos := config.StartPosition
+func (g *Game) refreshUI() {
+	drawMaze(g.Config, g.Maze)
+
+	g.mu.Lock()
+	targetPos := g.Config.getCellAddress(g.Config.FinalPosition).MiddleCenter
+	startPos := g.Config.getCellAddress(g.Config.StartPosition).MiddleCenter
+
+	for _, cell := range g.hint {
+		hintPos := g.Config.getCellAddress(cell).MiddleCenter
+		termbox.SetCell((hintPos[1]*2)+3, hintPos[0]+7, '.', termbox.ColorYellow, coldef)
+	}
+
+	// the hiding spot is never shown in the hide and seek mode.
+	if g.role == runner {
+		termbox.SetCell((targetPos[1]*2)+3, targetPos[0]+7, '#', termbox.ColorRed, termbox.ColorRed)
+	}
+
+	// the ghost is drawn without a background so that it looks faded next to the player.
+	if g.ghost != nil {
+		ghostPos := g.Config.getCellAddress(g.ghost.position).MiddleCenter
+		termbox.SetCell((ghostPos[1]*2)+3, ghostPos[0]+7, '@', termbox.ColorCyan, coldef)
+	}
 
-	termbox.SetCell((targetPos[1]*2)+3, targetPos[0]+7, '#', termbox.ColorRed, termbox.ColorRed)
 	termbox.SetCell((startPos[1]*2)+3, startPos[0]+7, '@', termbox.ColorGreen, termbox.ColorGreen)
 
-	fill(len(data[1])/3, len(data)+8, fmt.Sprintf(statusMsg, count), coldef)
+	status := fmt.Sprintf(statusMsg, g.Level, g.scores, g.Config.Seed)
+
+	switch {
+	case g.role != runner || len(g.message) > 0:
+		status = g.message
 
-	// check if target has been located
-	go func() {
-		if reflect.DeepEqual(startPos, targetPos) {
-			status <- succeeded
-		}
-	}()
+	case g.ghost != nil:
+		status = fmt.Sprintf(ghostMsg, g.Level, g.scores, g.ghost.owner, g.ghost.getDelta())
+	}
+
+	fill(len(g.Maze[1])/3, len(g.Maze)+8, status, coldef)
+	g.mu.Unlock()
 
 	termbox.Flush()
 }
 
 // interruptUI displays some text indicating  if the game is paused or
-// after the player won or lost a given tapoo game level.
-func interruptUI(msg string, config *Dimensions, data [][]string, color termbox.Attribute) {
-	drawMaze(config, data)
+// after the player won or lost a given tapoo game level. The scores are only
+// displayed if the status provided is not pause.
+func (g *Game) interruptUI(status int, msg string, color termbox.Attribute) {
+	drawMaze(g.Config, g.Maze)
 
-	xAxis := len(data[1]) / 4
+	xAxis := len(g.Maze[1]) / 4
 
 	for _, loc := range []int{3, 5, 7, 9} {
-		fill(xAxis, len(data)/2+loc, space, coldef)
+		fill(xAxis, len(g.Maze)/2+loc, space, coldef)
 	}
 
 	for loc, msg := range map[int]string{4: msg, 8: gameOverNavigation} {
-		fill(xAxis, len(data)/2+loc, msg, coldef)
+		fill(xAxis, len(g.Maze)/2+loc, msg, coldef)
 	}
 
 	scoresMsg := space
-	if !paused {
-		scoresMsg = fmt.Sprintf(highScores, scores)
+	if status != pause {
+		scoresMsg = fmt.Sprintf(highScores, g.Scores())
+	}
+
+	fill(xAxis, len(g.Maze)/2+6, scoresMsg, color)
+
+	g.mu.Lock()
+	for loc, msg := range g.topScores {
+		fill(xAxis, len(g.Maze)/2+loc+10, space, coldef)
+		fill(xAxis, len(g.Maze)/2+loc+10, msg, coldef)
 	}
+	g.mu.Unlock()
+
+	termbox.Flush()
+}
+
+// blankUI clears the maze from the termbox view so that the seeker cannot see where
+// the hider went and displays the messages provided instead.
+func (g *Game) blankUI(msgs ...string) {
+	drawMaze(g.Config, g.Maze)
 
-	fill(xAxis, len(data)/2+6, scoresMsg, color)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, d := range g.Maze {
+		fill(3, 7+k, strings.Repeat(" ", len(strings.Join(d, ""))), coldef)
+	}
+
+	xAxis := len(g.Maze[1]) / 4
+
+	for loc, msg := range msgs {
+		fill(xAxis, len(g.Maze)/2+(loc*2)+4, msg, coldef)
+	}
 
 	termbox.Flush()
 }
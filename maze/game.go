@@ -1,10 +1,16 @@
 package maze
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/dmigwi/tapoo/maze/api"
+	"github.com/dmigwi/tapoo/maze/db"
+	"github.com/dmigwi/tapoo/maze/solver"
 	termbox "github.com/nsf/termbox-go"
 )
 
@@ -31,70 +37,338 @@ const (
 	// Status should be updated after the player voluntarily paused the game or won the level
 	// or even failed to finish the level successfully.
 	quit
+
+	// ready status is updated when the hider confirms the hiding spot or when the
+	// seeker is ready to start seeking in the hide and seek mode.
+	ready
 )
 
-var (
-	scores int
+const (
+	// runner role is used when a single player is locating the target placed in the maze.
+	runner = iota
 
-	paused = false
+	// hider role is used when the player is choosing the hiding spot in the hide and seek mode.
+	hider
 
-	status = make(chan int)
+	// seeker role is used when the player is locating the hider in the hide and seek mode.
+	seeker
 )
 
+// refreshInterval defines how often the game screen is redrawn while a level is played.
+const refreshInterval = 100 * time.Millisecond
+
+// hintSteps defines the number of cells on the path to the target that are highlighted by a hint.
+const hintSteps = 5
+
+// hintPenalty defines the scores deducted every time a hint is shown.
+const hintPenalty = 500
+
+// Game defines a single tapoo game session. It owns the maze, the player and the target
+// positions, the scores and the status channel so that several games can be hosted
+// independently in the same process. Grid holds the maze topology while Maze holds
+// its terminal printable view. Metrics holds the measurements of the maze difficulty.
+// The events of the current level are logged so that the level attempts can be verified,
+// the whole game is recorded to a replay file only if a recorder is set. If a ghost mode
+// is set, the player races against the ghost of a previous attempt of every level in the
+// maze it was played in, the other levels are generated from the seed chosen by the player.
+type Game struct {
+	Config    *Dimensions
+	Grid      *Grid
+	Maze      [][]string
+	Level     int
+	Intensity int
+	Metrics   *solver.Metrics
+
+	mu        sync.Mutex
+	seed      int64
+	scores    int
+	paused    bool
+	status    chan int
+	hint      []int
+	hintsUsed int
+	found     bool
+	moves     int
+	role      int
+	start     int
+	message   string
+	store     db.Store
+	user      *db.UserInfor
+	topScores []string
+	recorder  *recorder
+	levelLog  []replayEvent
+	startedAt time.Time
+	ghostMode string
+	ghost     *ghost
+	clock     *gameClock
+}
+
+// NewGame creates a new game of the provided level whose maze fits the terminal size
+// provided. The maze is generated from the seed provided.
+func NewGame(level int, seed int64, terminalSize Dimensions) (*Game, error) {
+	g := newGame(seed)
+
+	if err := g.loadMaze(level, seed, terminalSize); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// newGame creates a new game without a maze whose levels are generated from the seed
+// provided. A level should be loaded before the game is played.
+func newGame(seed int64) *Game {
+	return &Game{seed: seed, status: make(chan int), clock: newGameClock(time.Now)}
+}
+
+// loadMaze replaces the current maze with a new maze of the provided level generated
+// from the seed provided. The scores and the hints of the previous maze are discarded and
+// the game clock is stopped with the time limit of the new level.
+func (g *Game) loadMaze(level int, seed int64, terminalSize Dimensions) error {
+	config, err := getMazeDimensions(level, seed, terminalSize)
+	if err != nil {
+		return err
+	}
+
+	grid, metrics := config.generateLevelMaze(level)
+
+	intensity := getLevelIntensity(level)
+
+	data, err := config.renderMaze(grid, intensity)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Config, g.Grid, g.Maze, g.Metrics = config, grid, data, metrics
+	g.Level, g.Intensity = level, intensity
+	g.scores, g.hint, g.hintsUsed, g.moves, g.found = 0, nil, 0, 0, false
+	g.start = config.StartPosition
+	g.levelLog, g.startedAt = nil, time.Now()
+	g.clock.reset(config.getTimeLimit(), 0)
+
+	g.record(replayEvent{Kind: replayLevel, Level: level, Seed: seed, Length: terminalSize.Length,
+		Width: terminalSize.Width})
+
+	return nil
+}
+
+// Move changes the player position in the direction provided if no wall exists in that direction.
+// The player cannot move while the game is paused since the game clock is stopped.
+func (g *Game) Move(direction string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return
+	}
+
+	position := g.Config.StartPosition
+	g.Config.playerMovement(g.Grid, direction)
+
+	if g.Config.StartPosition != position {
+		g.moves++
+		g.record(replayEvent{Kind: replayMove, Direction: direction})
+	}
+
+	// the target is located as soon as the player reaches it even if the player moves off
+	// it before the game screen is refreshed. The hider cannot locate the hiding spot.
+	if g.role != hider && g.Config.StartPosition == g.Config.FinalPosition {
+		g.found = true
+	}
+
+	// the hint is kept as long as the player follows it.
+	switch {
+	case len(g.hint) > 0 && g.hint[0] == g.Config.StartPosition:
+		g.hint = g.hint[1:]
+
+	case len(g.hint) > 0 && g.hint[0] != g.Config.StartPosition:
+		g.hint = nil
+	}
+}
+
+// ShowHint highlights the next cells on the shortest path from the player to the target.
+// Every hint shown deducts the hint penalty from the scores. No hint is shown while the
+// game is paused.
+func (g *Game) ShowHint() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return nil
+	}
+
+	solution, err := solver.AStar(g.Grid, g.Config.StartPosition, g.Config.FinalPosition)
+	if err != nil {
+		return err
+	}
+
+	steps := solution.Path[1:]
+	if len(steps) > hintSteps {
+		steps = steps[:hintSteps]
+	}
+
+	g.hint = steps
+	g.hintsUsed++
+
+	g.record(replayEvent{Kind: replayHint})
+
+	return nil
+}
+
+// requestHint shows the hint requested by the player or by a replay. A perfect maze always
+// has a path to the target thus the error returned by ShowHint is ignored.
+func (g *Game) requestHint() {
+	g.ShowHint()
+}
+
+// Hint returns the cells highlighted by the last hint shown that the player is yet to walk through.
+func (g *Game) Hint() []int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]int{}, g.hint...)
+}
+
+// Position returns the cell the player is currently at.
+func (g *Game) Position() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.Config.StartPosition
+}
+
+// IsTargetFound checks if the player has reached the target since the level or the
+// seeking turn started.
+func (g *Game) IsTargetFound() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.found
+}
+
+// Scores returns the current game scores.
+func (g *Game) Scores() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.scores
+}
+
+// setScores updates the current game scores less the penalty of all the hints shown.
+func (g *Game) setScores(scores int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.scores = scores - (g.hintsUsed * hintPenalty)
+}
+
+// isPaused checks if the game is paused.
+func (g *Game) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.paused
+}
+
+// setPaused updates the game paused state.
+func (g *Game) setPaused(paused bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.paused = paused
+}
+
+// getRole returns the role of the player currently navigating the maze.
+func (g *Game) getRole() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.role
+}
+
+// setRole updates the role of the player currently navigating the maze.
+func (g *Game) setRole(role int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.role = role
+}
+
 // playerMovement calculates the actual player position
 // depending on the navigation keys pressed.
-func (config *Dimensions) playerMovement(data [][]string, direction string) {
-	startPos := config.StartPosition
-	xVal, zVal := startPos[1], startPos[0]
+func (config *Dimensions) playerMovement(grid *Grid, direction string) {
+	var (
+		newPos    int
+		neighbors = config.getCellNeighbors(config.StartPosition)
+	)
 
-	switch {
-	case (direction == "LEFT") && ((xVal - 2) > 0) && isSpaceFound(data[zVal][xVal-1]):
-		config.StartPosition[1] = xVal - 2
+	switch direction {
+	case "LEFT":
+		newPos = neighbors.Left
 
-	case (direction == "RIGHT") && ((xVal + 2) <= config.Length*2) && isSpaceFound(data[zVal][xVal+1]):
-		config.StartPosition[1] = xVal + 2
+	case "RIGHT":
+		newPos = neighbors.Right
 
-	case (direction == "UP") && ((zVal - 2) > 0) && isSpaceFound(data[zVal-1][xVal]):
-		config.StartPosition[0] = zVal - 2
+	case "UP":
+		newPos = neighbors.Top
 
-	case (direction == "DOWN") && ((zVal + 2) <= config.Width*2) && isSpaceFound(data[zVal+1][xVal]):
-		config.StartPosition[0] = zVal + 2
+	case "DOWN":
+		newPos = neighbors.Bottom
+	}
+
+	if grid.IsPassable(config.StartPosition, newPos) {
+		config.StartPosition = newPos
 	}
 }
 
 // handlePlayerMovement detects that keys pressed on the keyboard
 // and provides that direction that the player should move to.
-func (config *Dimensions) handlePlayerMovement(event termbox.Key, data [][]string) {
-	switch event {
+func (g *Game) handlePlayerMovement(event termbox.Event) {
+	role := g.getRole()
+
+	// hints are not shown in the hide and seek mode since they reveal the hiding spot.
+	if (event.Ch == 'h' || event.Ch == 'H') && role == runner {
+		g.requestHint()
+		return
+	}
+
+	switch event.Key {
 	case termbox.KeyEsc, termbox.KeyCtrlC:
-		status <- quit
+		g.status <- quit
 
 	case termbox.KeyCtrlP:
-		status <- proceed
+		g.status <- proceed
+
+	case termbox.KeyEnter:
+		if role != runner {
+			g.status <- ready
+		}
 
 	case termbox.KeySpace:
-		status <- pause
+		g.status <- pause
 
 	case termbox.KeyArrowLeft:
-		config.playerMovement(data, "LEFT")
+		g.Move("LEFT")
 
 	case termbox.KeyArrowRight:
-		config.playerMovement(data, "RIGHT")
+		g.Move("RIGHT")
 
 	case termbox.KeyArrowUp:
-		config.playerMovement(data, "UP")
+		g.Move("UP")
 
 	case termbox.KeyArrowDown:
-		config.playerMovement(data, "DOWN")
+		g.Move("DOWN")
 	}
 }
 
 // handleKeyboardMapping handles all the keyboard input as captured by termbox
-func (config *Dimensions) handleKeyboardMapping(data [][]string) {
+func (g *Game) handleKeyboardMapping() {
 	for {
 		switch ev := termbox.PollEvent(); ev.Type {
 		case termbox.EventKey:
-			config.handlePlayerMovement(ev.Key, data)
+			g.handlePlayerMovement(ev)
 
 		case termbox.EventError:
 			panic(ev.Err)
@@ -102,73 +376,307 @@ func (config *Dimensions) handleKeyboardMapping(data [][]string) {
 	}
 }
 
-// Start define where the tapoo game starts at.
-func Start() {
+// Start define where the tapoo game starts at. The seed string provided is used to
+// reproduce a specific maze on a terminal of the same size, if it is empty a new random
+// seed is used. If a game saved by the player exists, the player is offered to resume it
+// first. If the database is available, the player is prompted for the Tapoo ID if none is
+// provided and the game resumes from the level after the highest level the player has
+// cleared. The game is recorded to the replay file provided if it is not empty. If the
+// ghost mode provided is not empty, the player races against the best attempt of every
+// level by the player (best) or by the level leader (leader) in the maze it was played in.
+// The error that ended the game is returned once the terminal is restored and the store
+// and the replay file are closed.
+func Start(seed, tapooID, replayFile, ghostMode string) error {
 	var (
-		data [][]string
+		user  *db.UserInfor
+		level = 1
 
-		// If an error is thrown print it and exit
-		errfunc = func(err error) {
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-		}
+		// the prompts share the scanner so that the input it buffers is not lost.
+		in = bufio.NewScanner(os.Stdin)
 	)
 
-	errfunc(termbox.Init())
+	if err := checkGhostMode(ghostMode); err != nil {
+		return err
+	}
+
+	// only the games saved by the player provided or by any player if none is provided are offered.
+	if s, err := readSaveGame(getSavePath()); err == nil && (len(tapooID) == 0 || s.TapooID == tapooID) {
+		resume, err := readConfirmation(in, os.Stdout, fmt.Sprintf(resumePrompt, s.Level))
+		if err == nil && resume {
+			return Resume(replayFile, ghostMode)
+		}
+	}
+
+	store, err := openStore()
+	if err == nil {
+		defer store.Close()
+	}
+
+	if err == nil && len(tapooID) == 0 {
+		tapooID, err = readTapooID(in, os.Stdout)
+	}
+
+	if err == nil {
+		user, level, err = loadUser(store, tapooID)
+	}
+
+	switch {
+	case errors.Is(err, api.ErrUnavailable):
+		fmt.Printf(queuedMsg, err)
+
+	case err != nil:
+		fmt.Printf(offlineMsg, err)
+	}
+
+	if err = termbox.Init(); err != nil {
+		return err
+	}
+
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	// the maze is only generated once the ghost of the level is known.
+	game := newGame(getMazeSeed(seed))
+
+	game.store, game.user, game.ghostMode = store, user, ghostMode
+	if err = game.loadLevel(level, game.seed, getTerminalSize(termbox.Size())); err != nil {
+		return err
+	}
+
+	if len(replayFile) > 0 {
+		f, err := os.Create(replayFile)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		if err = game.startRecording(f); err != nil {
+			return err
+		}
+	}
+
+	go game.handleKeyboardMapping()
+
+	return game.play()
+}
+
+// Resume continues the game saved when the player last quit a paused game exactly where
+// the player left off. The saved game is removed once it is resumed so that it can only be
+// resumed once. The replay file, the ghost mode and the error returned work like the ones
+// of Start.
+func Resume(replayFile, ghostMode string) error {
+	if err := checkGhostMode(ghostMode); err != nil {
+		return err
+	}
+
+	savePath := getSavePath()
+
+	s, err := readSaveGame(savePath)
+	if err != nil {
+		return err
+	}
+
+	var user *db.UserInfor
+
+	store, err := openStore()
+	if err == nil {
+		defer store.Close()
+	}
+
+	// the games saved without a Tapoo ID are resumed without saving the high scores.
+	if err == nil && len(s.TapooID) > 0 {
+		user, _, err = loadUser(store, s.TapooID)
+	}
+
+	switch {
+	case errors.Is(err, api.ErrUnavailable):
+		fmt.Printf(queuedMsg, err)
+
+	case err != nil:
+		fmt.Printf(offlineMsg, err)
+	}
+
+	if err = termbox.Init(); err != nil {
+		return err
+	}
 
 	defer termbox.Close()
 	termbox.SetInputMode(termbox.InputEsc)
 
-	val, err := getMazeDimensions(1, getTerminalSize(termbox.Size()))
-	errfunc(err)
+	game, err := s.loadGame(getTerminalSize(termbox.Size()))
+	if err != nil {
+		return err
+	}
 
-	data, err = val.generateMaze(1)
-	errfunc(err)
+	if err = os.Remove(savePath); err != nil {
+		return err
+	}
 
-	go val.handleKeyboardMapping(data)
+	// the ghost is only raced from the next level since it would replace the saved maze.
+	game.store, game.user, game.ghostMode = store, user, ghostMode
+
+	if len(replayFile) > 0 {
+		f, err := os.Create(replayFile)
+		if err != nil {
+			return err
+		}
 
+		defer f.Close()
+
+		if err = game.startRecording(f); err != nil {
+			return err
+		}
+	}
+
+	go game.handleKeyboardMapping()
+
+	return game.play()
+}
+
+// play runs the game loop until the player quits the game. Locating the target on time
+// advances the player to the next level while failing to locate it replays the same level.
+// The game clock only runs while the level is played thus proceeding after pausing the
+// game resumes the level from where it was paused while quitting a paused game saves it so
+// that it can be resumed later. An error is returned if the game could not be saved.
+func (g *Game) play() error {
 	var (
-		timer       = time.NewTicker(500 * time.Microsecond)
-		totalCells  = val.Length * val.Width
-		timeout     = time.NewTimer(time.Duration(totalCells) * time.Second)
-		currentTime = time.Now().Unix()
+		timer = time.NewTicker(refreshInterval)
+
+		// outcome holds the status that paused the game.
+		outcome int
 	)
 
-mainloop:
+	// the ticker is replaced every time the game is resumed.
+	defer func() { timer.Stop() }()
+
+	// interrupt stops the timer and displays the message provided.
+	interrupt := func(status int, msg string, color termbox.Attribute) {
+		timer.Stop()
+		g.clock.pause()
+
+		outcome = status
+
+		g.recordStatus(status)
+
+		g.mu.Lock()
+		g.topScores = nil
+		g.mu.Unlock()
+
+		if status == succeeded || status == failed {
+			if err := g.saveAttempt(getOutcome(status), g.clock.getPlayed()); err != nil {
+				g.mu.Lock()
+				g.topScores = getTopScoresMsgs(g.Level, nil, err)
+				g.mu.Unlock()
+			}
+		}
+
+		if status != pause && len(g.topScores) == 0 {
+			g.loadTopScores()
+		}
+
+		g.interruptUI(status, msg, color)
+		g.setPaused(true)
+	}
+
+	g.clock.resume()
+
 	for {
 		select {
-		case timeVal := <-timer.C:
-			scores = (totalCells - int(timeVal.Unix()-currentTime)) * 100
+		case <-timer.C:
+			// a tick sent before the ticker was stopped is ignored.
+			if g.isPaused() {
+				continue
+			}
 
-			refreshUI(val, scores, data)
-		case <-timeout.C:
-			go func() { status <- failed }()
+			g.setScores(g.clock.getScores())
+			g.moveGhost(g.clock.getPlayed())
 
-		case returnedStatus := <-status:
-			timer.Stop()
-			timeout.Stop()
+			g.refreshUI()
 
 			switch {
-			case returnedStatus == succeeded:
-				interruptUI(gameOverSucceed, val, data, termbox.ColorGreen)
-				paused = true
+			case g.IsTargetFound():
+				interrupt(succeeded, gameOverSucceed, termbox.ColorGreen)
 
-			case returnedStatus == failed:
-				interruptUI(gameOverFailed, val, data, termbox.ColorRed)
-				paused = true
+			case g.clock.isTimedOut():
+				interrupt(failed, gameOverFailed, termbox.ColorRed)
+			}
 
-			case returnedStatus == quit && paused:
-				break mainloop
+		case returnedStatus := <-g.status:
+			switch {
+			case returnedStatus == quit:
+				var err error
+
+				// the level is recorded as quit only if it could not be saved.
+				if g.isPaused() && outcome == pause {
+					if err = g.saveGame(getSavePath()); err == nil {
+						return nil
+					}
+
+					err = fmt.Errorf("the game could not be saved: %v", err)
+				}
+
+				g.recordStatus(quit)
+
+				// quitting a level before it is over is recorded too.
+				g.clock.pause()
+
+				if !g.isPaused() || outcome == pause {
+					g.saveAttempt(db.Quit, g.clock.getPlayed())
+				}
+
+				return err
+
+			case returnedStatus == pause && !g.isPaused():
+				interrupt(pause, pauseMsg, termbox.ColorBlue)
+
+			case returnedStatus == proceed && g.isPaused():
+				if outcome != pause {
+					terminalSize := getTerminalSize(termbox.Size())
+
+					err := g.loadLevel(getNextLevel(g.Level, outcome), g.seed, terminalSize)
+					if err != nil {
+						g.interruptUI(outcome, levelTooLarge, termbox.ColorRed)
+						continue
+					}
+				}
+
+				g.recordStatus(proceed)
 
-			case returnedStatus == proceed && paused:
-				// paused = false
+				timer = time.NewTicker(refreshInterval)
+				g.clock.resume()
 
-			case returnedStatus == pause:
-				paused = true
-				interruptUI(pauseMsg, val, data, termbox.ColorBlue)
+				g.setPaused(false)
 			}
 		}
 	}
 }
+
+// getOutcome returns the attempt outcome of the game status provided.
+func getOutcome(status int) string {
+	if status == succeeded {
+		return db.Succeeded
+	}
+
+	return db.Failed
+}
+
+// getTimeLimit returns the time the player has to locate the target. A second is
+// allocated for every cell in the maze.
+func (g *Game) getTimeLimit() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.Config.getTimeLimit()
+}
+
+// getTimeLimit returns the time limit of a level whose maze has the dimensions provided.
+func (config *Dimensions) getTimeLimit() time.Duration {
+	return time.Duration(config.Length*config.Width) * time.Second
+}
+
+// getLevelScores returns the scores of a level with the time limit provided after the time
+// spent provided. A hundred scores are awarded for every second left.
+func getLevelScores(timeLimit, spent time.Duration) int {
+	return int((timeLimit-spent)/time.Second) * 100
+}
@@ -1,53 +1,265 @@
 package maze
 
 import (
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/dmigwi/tapoo/maze/solver"
+	termbox "github.com/nsf/termbox-go"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 // TestPlayerMovement tests the functionality of playerMovement
 func TestPlayerMovement(t *testing.T) {
-	data := [][]string{
-		[]string{"|", "---", "|", "---", "|", "---", "|"},
-		[]string{"|", " A ", " ", "   ", "|", "   ", "|"},
-		[]string{"|", "   ", "|", "   ", "|", "---", "|"},
-		[]string{"|", "   ", " ", " B ", " ", "   ", "|"},
-		[]string{"|", "---", "|", "   ", "|", "---", "|"},
-		[]string{"|", "   ", "|", "   ", "|", "   ", "|"},
-		[]string{"|", "---", "|", "---", "|", "---", "|"},
+	// grid represents the maze below with cells A and B being cell 1 and cell 5 respectively.
+	//   |---|---|---|
+	//   | A     |   |
+	//   |   |   |---|
+	//   |     B     |
+	//   |---|   |---|
+	//   |   |   |   |
+	//   |---|---|---|
+	grid := NewGrid(3, 3)
+	for _, path := range [][2]int{{1, 2}, {1, 4}, {2, 5}, {4, 5}, {5, 6}, {5, 8}} {
+		grid.Carve(path[0], path[1])
 	}
 
-	Convey("TestPlayerMovement: Given the grid view and the current player position", t, func() {
+	Convey("TestPlayerMovement: Given the grid and the current player position", t, func() {
 		var d = Dimensions{Length: 3, Width: 3}
 
 		Convey("is at the middle the player should be able to move to all directions"+
 			"position exists for the direction provided", func() {
-			for direction, output := range map[string][]int{
-				"LEFT": []int{3, 1}, "RIGHT": []int{3, 5},
-				"DOWN": []int{5, 3}, "UP": []int{1, 3}} {
+			for direction, output := range map[string]int{
+				"LEFT": 4, "RIGHT": 6, "DOWN": 8, "UP": 2} {
 
-				d.StartPosition = []int{3, 3}
+				d.StartPosition = 5
 
-				d.playerMovement(data, direction)
+				d.playerMovement(grid, direction)
 
-				So(output[0], ShouldEqual, d.StartPosition[0])
-				So(output[1], ShouldEqual, d.StartPosition[1])
+				So(d.StartPosition, ShouldEqual, output)
 			}
 
-			Convey("is at a corner, the player should only be able to move to directions with spaces", func() {
-				for direction, output := range map[string][]int{
-					"LEFT": []int{1, 1}, "RIGHT": []int{1, 3},
-					"DOWN": []int{3, 1}, "UP": []int{1, 1}} {
+			Convey("is at a corner, the player should only be able to move to directions with paths", func() {
+				for direction, output := range map[string]int{
+					"LEFT": 1, "RIGHT": 2, "DOWN": 4, "UP": 1} {
 
-					d.StartPosition = []int{1, 1}
+					d.StartPosition = 1
 
-					d.playerMovement(data, direction)
+					d.playerMovement(grid, direction)
 
-					So(output[0], ShouldEqual, d.StartPosition[0])
-					So(output[1], ShouldEqual, d.StartPosition[1])
+					So(d.StartPosition, ShouldEqual, output)
 				}
 			})
 		})
 	})
 }
+
+// TestNewGame tests the functionality of NewGame
+func TestNewGame(t *testing.T) {
+	Convey("TestNewGame: Given the level, the seed and the terminal size", t, func() {
+		Convey("where the terminal size is too small, an error should be returned", func() {
+			game, err := NewGame(20, 1, Dimensions{Length: 5, Width: 5})
+
+			So(game, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "terminal size is too small")
+		})
+
+		Convey("where the terminal size is large enough, a new game should be returned", func() {
+			game, err := NewGame(1, 1, Dimensions{Length: 40, Width: 20})
+
+			So(err, ShouldBeNil)
+			So(game.Maze, ShouldNotBeEmpty)
+			So(game.Level, ShouldEqual, 1)
+			So(game.Intensity, ShouldEqual, getLevelIntensity(1))
+			So(game.Grid.Cells(), ShouldEqual, game.Config.Length*game.Config.Width)
+			So(game.Config.StartPosition, ShouldBeGreaterThan, 0)
+			So(game.Config.FinalPosition, ShouldBeGreaterThan, 0)
+			So(game.IsTargetFound(), ShouldBeFalse)
+		})
+	})
+}
+
+// TestLoadMaze tests the functionality of loadMaze
+func TestLoadMaze(t *testing.T) {
+	Convey("TestLoadMaze: Given a game and the next level to be played", t, func() {
+		game, err := NewGame(4, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		game.ShowHint()
+		game.setScores(10000)
+
+		Convey("the larger maze of the next level should replace the current maze", func() {
+			cells := game.Grid.Cells()
+
+			So(game.loadMaze(5, 2018, Dimensions{Length: 40, Width: 20}), ShouldBeNil)
+
+			So(game.Level, ShouldEqual, 5)
+			So(game.Intensity, ShouldEqual, 2)
+			So(game.Grid.Cells(), ShouldEqual, cells+diff)
+			So(game.Scores(), ShouldEqual, 0)
+			So(game.Hint(), ShouldBeEmpty)
+			So(game.getTimeLimit(), ShouldEqual, time.Duration(cells+diff)*time.Second)
+		})
+
+		Convey("where the terminal is too small for the next level, the current maze should be kept", func() {
+			So(game.loadMaze(5, 2018, Dimensions{Length: 5, Width: 5}), ShouldNotBeNil)
+
+			So(game.Level, ShouldEqual, 4)
+			So(game.Scores(), ShouldEqual, 10000-hintPenalty)
+		})
+	})
+}
+
+// TestConcurrentGames tests that several games can be played independently in the same process.
+func TestConcurrentGames(t *testing.T) {
+	Convey("TestConcurrentGames: Given several games played concurrently", t, func() {
+		Convey("games with the same seed should have the same maze irrespective of the other games", func() {
+			var (
+				wg    sync.WaitGroup
+				games = make([]*Game, 8)
+				errs  = make([]error, 8)
+			)
+
+			for i := range games {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+
+					games[i], errs[i] = NewGame(i%2, int64(i%2), Dimensions{Length: 40, Width: 20})
+					if errs[i] != nil {
+						return
+					}
+
+					// move the player around as the other games are being created.
+					for _, direction := range []string{"LEFT", "UP", "RIGHT", "DOWN"} {
+						go games[i].Move(direction)
+						games[i].IsTargetFound()
+					}
+				}(i)
+			}
+
+			wg.Wait()
+
+			for i := range games {
+				So(errs[i], ShouldBeNil)
+				So(games[i].Maze, ShouldResemble, games[i%2].Maze)
+				So(games[i].Config.FinalPosition, ShouldResemble, games[i%2].Config.FinalPosition)
+			}
+		})
+	})
+}
+
+// TestShowHint tests the functionality of ShowHint
+func TestShowHint(t *testing.T) {
+	Convey("TestShowHint: Given a new game", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		Convey("the hint should highlight the next cells on the path to the target", func() {
+			So(game.ShowHint(), ShouldBeNil)
+
+			hint := game.Hint()
+
+			So(len(hint), ShouldBeBetweenOrEqual, 1, hintSteps)
+			So(game.Grid.IsPassable(game.Config.StartPosition, hint[0]), ShouldBeTrue)
+
+			Convey("and the hint penalty should be deducted from the scores", func() {
+				game.setScores(10000)
+
+				So(game.Scores(), ShouldEqual, 10000-hintPenalty)
+			})
+
+			Convey("and the hint should remain as long as the player follows it", func() {
+				for _, direction := range []string{"LEFT", "RIGHT", "UP", "DOWN"} {
+					neighbors := game.Config.getCellNeighbors(game.Config.StartPosition)
+					next := map[string]int{"LEFT": neighbors.Left, "RIGHT": neighbors.Right,
+						"UP": neighbors.Top, "DOWN": neighbors.Bottom}[direction]
+
+					if next == hint[0] {
+						game.Move(direction)
+						break
+					}
+				}
+
+				So(game.Config.StartPosition, ShouldEqual, hint[0])
+				So(game.Hint(), ShouldResemble, hint[1:])
+				So(game.moves, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+// TestIsTargetFound tests the functionality of IsTargetFound
+func TestIsTargetFound(t *testing.T) {
+	Convey("TestIsTargetFound: Given a new game", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		moves := getDirections(game.Config, solution.Path)
+
+		Convey("the target should remain located after the player moves onto it and back off it"+
+			" before the game screen is refreshed", func() {
+			for _, direction := range moves {
+				So(game.IsTargetFound(), ShouldBeFalse)
+				game.Move(direction)
+			}
+
+			opposite := map[string]string{"LEFT": "RIGHT", "RIGHT": "LEFT", "UP": "DOWN", "DOWN": "UP"}
+			game.Move(opposite[moves[len(moves)-1]])
+
+			So(game.Config.StartPosition, ShouldNotEqual, game.Config.FinalPosition)
+			So(game.IsTargetFound(), ShouldBeTrue)
+
+			Convey("and the next level should start with the target not located", func() {
+				So(game.loadMaze(2, 2018, Dimensions{Length: 40, Width: 20}), ShouldBeNil)
+
+				So(game.IsTargetFound(), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+// TestHandlePlayerMovement tests the functionality of handlePlayerMovement
+func TestHandlePlayerMovement(t *testing.T) {
+	Convey("TestHandlePlayerMovement: Given a paused game", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		game.setPaused(true)
+
+		var (
+			position = game.Position()
+			events   = len(game.levelLog)
+			keys     = []termbox.Event{{Key: termbox.KeyArrowLeft}, {Key: termbox.KeyArrowRight},
+				{Key: termbox.KeyArrowUp}, {Key: termbox.KeyArrowDown}}
+		)
+
+		Convey("the navigation keys pressed should neither move the player nor show a hint", func() {
+			for _, event := range append(keys, termbox.Event{Ch: 'h'}) {
+				game.handlePlayerMovement(event)
+			}
+
+			So(game.Position(), ShouldEqual, position)
+			So(game.moves, ShouldEqual, 0)
+			So(game.hintsUsed, ShouldEqual, 0)
+			So(game.levelLog, ShouldHaveLength, events)
+
+			Convey("and the player should move again once the game is resumed", func() {
+				game.setPaused(false)
+
+				for _, event := range keys {
+					game.handlePlayerMovement(event)
+				}
+
+				So(game.moves, ShouldBeGreaterThan, 0)
+				So(game.levelLog, ShouldHaveLength, events+game.moves)
+			})
+		})
+	})
+}
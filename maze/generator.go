@@ -0,0 +1,398 @@
+package maze
+
+// Generator defines a maze generation algorithm. Generate should carve paths on the
+// grid provided starting from the startCell, such that when it returns every cell
+// in the maze can be reached from any other cell through a single path.
+type Generator interface {
+	Generate(config *Dimensions, grid *Grid, startCell int)
+}
+
+type (
+	// RecursiveBacktracker walks randomly to non-visited neighbors and backtracks
+	// when it gets stuck. It produces mazes with long winding corridors and few dead ends.
+	RecursiveBacktracker struct{}
+
+	// Prim grows the maze from the starting cell by carving to a random frontier cell
+	// each time. It produces mazes with many short dead ends.
+	Prim struct{}
+
+	// Kruskal joins random pairs of adjacent cells that are not yet connected.
+	// It produces mazes with many short dead ends spread evenly across the maze.
+	Kruskal struct{}
+
+	// Wilson adds loop-erased random walks to the maze until all the cells are visited.
+	// It produces an unbiased maze i.e. all possible mazes are equally likely.
+	Wilson struct{}
+
+	// Eller creates the maze a single row at a time by joining cells belonging to
+	// different sets. It produces mazes with a horizontal bias.
+	Eller struct{}
+
+	// AldousBroder carves a path every time a random walk enters a non-visited cell.
+	// Just like Wilson's, it produces an unbiased maze.
+	AldousBroder struct{}
+
+	// BinaryTree carves either the top or the left wall of every cell. It produces
+	// mazes with long corridors along the top and the left edges of the maze.
+	BinaryTree struct{}
+
+	// RecursiveDivision begins with an open field and recursively adds walls with a
+	// single gap each. It produces mazes with long straight walls.
+	RecursiveDivision struct{}
+)
+
+// generators defines the maze generation algorithms in the order in which they
+// are assigned to the game levels.
+var generators = []Generator{
+	RecursiveBacktracker{},
+	Prim{},
+	Kruskal{},
+	Wilson{},
+	Eller{},
+	AldousBroder{},
+	BinaryTree{},
+	RecursiveDivision{},
+}
+
+// getGenerator returns the maze generation algorithm to be used in the provided level.
+func getGenerator(level int) Generator {
+	if level < 0 {
+		level = -level
+	}
+	return generators[level%len(generators)]
+}
+
+// Generate implements the Generator interface for RecursiveBacktracker.
+func (RecursiveBacktracker) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		neighbors []int
+
+		cellsPath, currentPos = []int{startCell}, startCell
+
+		// visitedCells holds the cells that already have a common path with other cells.
+		visitedCells = make([]bool, grid.Cells()+1)
+		totalVisited = 1
+	)
+
+	visitedCells[currentPos] = true
+
+	cellsPath = append(cellsPath, currentPos)
+
+	for totalVisited < grid.Cells() {
+		for {
+			neighbors = config.getPresentNeighbors(currentPos, visitedCells)
+
+			if len(neighbors) > 0 {
+				break
+			}
+
+			cellsPath, currentPos = cellsPath[:len(cellsPath)-1], cellsPath[len(cellsPath)-1]
+		}
+
+		newPos := neighbors[config.getRandomNo(len(neighbors))]
+
+		if !visitedCells[newPos] {
+			visitedCells[newPos] = true
+			totalVisited++
+
+			grid.Carve(currentPos, newPos)
+			cellsPath = append(cellsPath, newPos)
+
+			currentPos = newPos
+		}
+	}
+}
+
+// Generate implements the Generator interface for Prim.
+func (Prim) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		visited  = make([]bool, grid.Cells()+1)
+		frontier = make([]bool, grid.Cells()+1)
+		cells    []int
+	)
+
+	visited[startCell] = true
+
+	addFrontier := func(cellNo int) {
+		for _, neighbor := range grid.Neighbors(cellNo) {
+			if !visited[neighbor] && !frontier[neighbor] {
+				frontier[neighbor] = true
+				cells = append(cells, neighbor)
+			}
+		}
+	}
+
+	addFrontier(startCell)
+
+	for len(cells) > 0 {
+		index := config.getRandomNo(len(cells))
+		cellNo := cells[index]
+		cells = append(cells[:index], cells[index+1:]...)
+
+		var inMaze []int
+		for _, neighbor := range grid.Neighbors(cellNo) {
+			if visited[neighbor] {
+				inMaze = append(inMaze, neighbor)
+			}
+		}
+
+		grid.Carve(cellNo, inMaze[config.getRandomNo(len(inMaze))])
+
+		visited[cellNo], frontier[cellNo] = true, false
+
+		addFrontier(cellNo)
+	}
+}
+
+// Generate implements the Generator interface for Kruskal.
+func (Kruskal) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		edges [][2]int
+
+		sets = make([]int, grid.Cells()+1)
+	)
+
+	for cellNo := range sets {
+		sets[cellNo] = cellNo
+	}
+
+	// find returns the set that the provided cell belongs to.
+	var find func(cellNo int) int
+	find = func(cellNo int) int {
+		if sets[cellNo] != cellNo {
+			sets[cellNo] = find(sets[cellNo])
+		}
+		return sets[cellNo]
+	}
+
+	for cellNo := 1; cellNo <= grid.Cells(); cellNo++ {
+		if cellNo%grid.Length != 0 {
+			edges = append(edges, [2]int{cellNo, cellNo + 1})
+		}
+
+		if cellNo+grid.Length <= grid.Cells() {
+			edges = append(edges, [2]int{cellNo, cellNo + grid.Length})
+		}
+	}
+
+	for _, i := range config.getShuffledIndices(len(edges)) {
+		first, second := find(edges[i][0]), find(edges[i][1])
+
+		if first != second {
+			sets[first] = second
+			grid.Carve(edges[i][0], edges[i][1])
+		}
+	}
+}
+
+// Generate implements the Generator interface for Wilson.
+func (Wilson) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		totalCells = grid.Cells()
+		visited    = make([]bool, totalCells+1)
+	)
+
+	visited[startCell] = true
+
+	for _, i := range config.getShuffledIndices(totalCells) {
+		cellNo := i + 1
+		if visited[cellNo] {
+			continue
+		}
+
+		// next records the last exit taken out of every cell in the walk,
+		// this erases any loops created during the random walk.
+		next := map[int]int{}
+
+		for currentPos := cellNo; !visited[currentPos]; {
+			neighbors := grid.Neighbors(currentPos)
+			next[currentPos] = neighbors[config.getRandomNo(len(neighbors))]
+			currentPos = next[currentPos]
+		}
+
+		for currentPos := cellNo; !visited[currentPos]; currentPos = next[currentPos] {
+			visited[currentPos] = true
+			grid.Carve(currentPos, next[currentPos])
+		}
+	}
+}
+
+// Generate implements the Generator interface for Eller.
+func (Eller) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		nextSet = 1
+		sets    = make([]int, grid.Cells()+1)
+	)
+
+	for row := 0; row < grid.Width; row++ {
+		isLastRow := row == grid.Width-1
+		rowStart := (row * grid.Length) + 1
+
+		for cellNo := rowStart; cellNo < rowStart+grid.Length; cellNo++ {
+			if sets[cellNo] == 0 {
+				sets[cellNo], nextSet = nextSet, nextSet+1
+			}
+		}
+
+		// randomly join the adjacent cells that belong to different sets.
+		for cellNo := rowStart; cellNo < rowStart+grid.Length-1; cellNo++ {
+			if sets[cellNo] == sets[cellNo+1] || (!isLastRow && config.getRandomNo(2) == 0) {
+				continue
+			}
+
+			grid.Carve(cellNo, cellNo+1)
+
+			oldSet := sets[cellNo+1]
+			for otherCell := rowStart; otherCell < rowStart+grid.Length; otherCell++ {
+				if sets[otherCell] == oldSet {
+					sets[otherCell] = sets[cellNo]
+				}
+			}
+		}
+
+		if isLastRow {
+			break
+		}
+
+		// every set should have at least one path to the next row. The sets are
+		// visited in the order they appear in the row so that the seed can
+		// reproduce the same maze.
+		var order []int
+		members := map[int][]int{}
+		for cellNo := rowStart; cellNo < rowStart+grid.Length; cellNo++ {
+			if _, ok := members[sets[cellNo]]; !ok {
+				order = append(order, sets[cellNo])
+			}
+			members[sets[cellNo]] = append(members[sets[cellNo]], cellNo)
+		}
+
+		for _, set := range order {
+			cells := members[set]
+			for i, index := range config.getShuffledIndices(len(cells)) {
+				if i > 0 && config.getRandomNo(2) == 0 {
+					continue
+				}
+
+				grid.Carve(cells[index], cells[index]+grid.Length)
+				sets[cells[index]+grid.Length] = set
+			}
+		}
+	}
+}
+
+// Generate implements the Generator interface for AldousBroder.
+func (AldousBroder) Generate(config *Dimensions, grid *Grid, startCell int) {
+	var (
+		currentPos   = startCell
+		visited      = make([]bool, grid.Cells()+1)
+		totalVisited = 1
+	)
+
+	visited[startCell] = true
+
+	for totalVisited < grid.Cells() {
+		neighbors := grid.Neighbors(currentPos)
+		newPos := neighbors[config.getRandomNo(len(neighbors))]
+
+		if !visited[newPos] {
+			visited[newPos] = true
+			totalVisited++
+			grid.Carve(currentPos, newPos)
+		}
+
+		currentPos = newPos
+	}
+}
+
+// Generate implements the Generator interface for BinaryTree.
+func (BinaryTree) Generate(config *Dimensions, grid *Grid, startCell int) {
+	for cellNo := 1; cellNo <= grid.Cells(); cellNo++ {
+		var choices []int
+
+		if cellNo > grid.Length {
+			choices = append(choices, cellNo-grid.Length)
+		}
+
+		if (cellNo-1)%grid.Length != 0 {
+			choices = append(choices, cellNo-1)
+		}
+
+		if len(choices) > 0 {
+			grid.Carve(cellNo, choices[config.getRandomNo(len(choices))])
+		}
+	}
+}
+
+// Generate implements the Generator interface for RecursiveDivision.
+func (RecursiveDivision) Generate(config *Dimensions, grid *Grid, startCell int) {
+	// walls holds the pairs of adjacent cells that have a common wall between them.
+	walls := map[[2]int]bool{}
+
+	cellNo := func(row, col int) int {
+		return (row * grid.Length) + col + 1
+	}
+
+	// divide splits the region with the top left cell at (row, col) into two
+	// by adding a wall with a single gap along the shorter region edge.
+	var divide func(row, col, width, length int)
+	divide = func(row, col, width, length int) {
+		if width < 2 || length < 2 {
+			return
+		}
+
+		isHorizontal := width > length || (width == length && config.getRandomNo(2) == 0)
+
+		if isHorizontal {
+			wallRow, gap := row+config.getRandomNo(width-1), col+config.getRandomNo(length)
+
+			for c := col; c < col+length; c++ {
+				if c != gap {
+					walls[[2]int{cellNo(wallRow, c), cellNo(wallRow+1, c)}] = true
+				}
+			}
+
+			divide(row, col, wallRow-row+1, length)
+			divide(wallRow+1, col, row+width-wallRow-1, length)
+			return
+		}
+
+		wallCol, gap := col+config.getRandomNo(length-1), row+config.getRandomNo(width)
+
+		for r := row; r < row+width; r++ {
+			if r != gap {
+				walls[[2]int{cellNo(r, wallCol), cellNo(r, wallCol+1)}] = true
+			}
+		}
+
+		divide(row, col, width, wallCol-col+1)
+		divide(row, wallCol+1, width, col+length-wallCol-1)
+	}
+
+	divide(0, 0, grid.Width, grid.Length)
+
+	for cell := 1; cell <= grid.Cells(); cell++ {
+		if cell%grid.Length != 0 && !walls[[2]int{cell, cell + 1}] {
+			grid.Carve(cell, cell+1)
+		}
+
+		if cell+grid.Length <= grid.Cells() && !walls[[2]int{cell, cell + grid.Length}] {
+			grid.Carve(cell, cell+grid.Length)
+		}
+	}
+}
+
+// getShuffledIndices returns the indices of a slice with the provided length in a random order.
+func (config *Dimensions) getShuffledIndices(length int) []int {
+	indices := make([]int, length)
+
+	for i := range indices {
+		indices[i] = i
+	}
+
+	for i := length - 1; i > 0; i-- {
+		j := config.getRandomNo(i + 1)
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+
+	return indices
+}
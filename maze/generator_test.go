@@ -0,0 +1,87 @@
+package maze
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestGenerate tests the functionality of Generate on all the generators.
+func TestGenerate(t *testing.T) {
+	var compressedView []string
+
+	Convey("TestGenerate: Given a grid and the start cell", t, func() {
+		Convey("every generator should carve a maze where all the cells are connected"+
+			" by a single path", func() {
+			for _, generator := range generators {
+				val := &Dimensions{Length: 9, Width: 7}
+				grid := NewGrid(val.Length, val.Width)
+
+				generator.Generate(val, grid, 10)
+
+				paths := 0
+				for cell := 1; cell <= grid.Cells(); cell++ {
+					paths += len(grid.OpenNeighbors(cell))
+				}
+
+				distance := grid.getDistances(10)
+
+				So(paths/2, ShouldEqual, grid.Cells()-1)
+				for cell := 1; cell <= grid.Cells(); cell++ {
+					So(distance[cell], ShouldBeGreaterThanOrEqualTo, 0)
+				}
+
+				gridView, err := val.renderMaze(grid, 1)
+				So(err, ShouldBeNil)
+
+				for _, walls := range gridView {
+					compressedView = append(compressedView, strings.Join(walls, ""))
+				}
+
+				log.Println(reflect.TypeOf(generator).Name(), "\n", strings.Join(compressedView, ""))
+
+				compressedView = []string{}
+			}
+		})
+	})
+}
+
+// TestGetGenerator tests the functionality of getGenerator
+func TestGetGenerator(t *testing.T) {
+	Convey("TestGetGenerator: Given a game level", t, func() {
+		Convey("the generators should be assigned to consecutive levels in order", func() {
+			for level := range generators {
+				So(getGenerator(level), ShouldResemble, generators[level])
+			}
+		})
+
+		Convey("that is larger than the number of generators, the assignment should wrap around", func() {
+			So(getGenerator(len(generators)+2), ShouldResemble, generators[2])
+		})
+	})
+}
+
+// TestGetFarthestCell tests the functionality of getFarthestCell
+func TestGetFarthestCell(t *testing.T) {
+	// grid represents the maze below.
+	//   |---|---|---|
+	//   | 1   2   3 |
+	//   |---|---|   |
+	//   | 4   5   6 |
+	//   |---|---|---|
+	grid := NewGrid(3, 2)
+	for _, path := range [][2]int{{1, 2}, {2, 3}, {3, 6}, {6, 5}, {5, 4}} {
+		grid.Carve(path[0], path[1])
+	}
+
+	Convey("TestGetFarthestCell: Given a maze and the start cell", t, func() {
+		Convey("the cell with the longest path from the start cell should be returned", func() {
+			So(grid.getFarthestCell(1), ShouldEqual, 4)
+			So(grid.getFarthestCell(4), ShouldEqual, 1)
+			So(grid.getFarthestCell(3), ShouldEqual, 4)
+		})
+	})
+}
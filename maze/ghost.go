@@ -0,0 +1,218 @@
+package maze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+// The following ghost modes select whose previous attempt of every level is raced against.
+const (
+	// ghostBest races the player against the best attempt of the player.
+	ghostBest = "best"
+
+	// ghostLeader races the player against the best attempt of the level leader.
+	ghostLeader = "leader"
+)
+
+const ghostMsg = "  Press Space to Pause.    Level: %d    Scores: %d    Ghost %.12s: %-7s  "
+
+// ghost defines a previous attempt of the current level that is drawn moving through the
+// maze alongside the player. Steps hold the cells the ghost moved to in the order it moved
+// to them while visits hold the time the ghost first reached every cell it went through.
+// The times held are the time played, excluding the time the attempt was paused.
+type ghost struct {
+	owner  string
+	start  int
+	length int
+	width  int
+	steps  []ghostStep
+	visits map[int]time.Duration
+
+	// position holds the cell the ghost is currently at, cell holds the cell the player
+	// was last compared at while delta holds how far behind the ghost the player was then.
+	position int
+	cell     int
+	delta    time.Duration
+	compared bool
+}
+
+// ghostStep defines a single move of the ghost. At holds the time played when the ghost
+// moved to the cell.
+type ghostStep struct {
+	at   time.Duration
+	cell int
+}
+
+// newGhost replays the level attempt provided to find when its player moved to every cell.
+// Only the attempts that located the target can be raced against.
+func newGhost(a *db.Attempt) (*ghost, error) {
+	rp, _, err := getAttemptReplay(a)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newReplayPlayer(rp)
+	if err != nil {
+		return nil, err
+	}
+
+	start := p.game.Position()
+
+	gh := &ghost{owner: a.TapooID, start: start, length: p.game.Config.Length, width: p.game.Config.Width,
+		visits: map[int]time.Duration{start: 0}, position: start, cell: start}
+
+	last := start
+
+	for _, e := range rp.events[1:] {
+		if err = p.seek(e.getTime()); err != nil {
+			return nil, err
+		}
+
+		cell := p.game.Position()
+		if cell == last {
+			continue
+		}
+
+		last = cell
+		spent := p.getSpent()
+		gh.steps = append(gh.steps, ghostStep{at: spent, cell: cell})
+
+		if _, ok := gh.visits[cell]; !ok {
+			gh.visits[cell] = spent
+		}
+	}
+
+	if !p.game.IsTargetFound() {
+		return nil, errors.New("the ghost attempt did not locate the target")
+	}
+
+	return gh, nil
+}
+
+// fits checks if the maze of the ghost fits the terminal size provided.
+func (gh *ghost) fits(terminalSize Dimensions) bool {
+	return gh.length <= terminalSize.Length && gh.width <= terminalSize.Width
+}
+
+// getPosition returns the cell the ghost was at after the time played provided.
+func (gh *ghost) getPosition(spent time.Duration) int {
+	i := sort.Search(len(gh.steps), func(i int) bool { return gh.steps[i].at > spent })
+	if i == 0 {
+		return gh.start
+	}
+
+	return gh.steps[i-1].cell
+}
+
+// getDelta returns how far behind the ghost the player was when last compared, formatted
+// in seconds. A negative delta shows the player is ahead of the ghost.
+func (gh *ghost) getDelta() string {
+	if !gh.compared {
+		return "--"
+	}
+
+	return fmt.Sprintf("%+.1fs", gh.delta.Seconds())
+}
+
+// loadLevel loads the maze of the level provided generated from the seed provided. If a
+// ghost mode is set, the maze the best attempt of the level selected by the mode was played
+// in is loaded instead so that the player can race against it. The level is played without
+// a ghost if no such attempt exists or its maze does not fit the terminal size provided.
+// The attempt is fetched before the maze is loaded since the level time starts then.
+func (g *Game) loadLevel(level int, seed int64, terminalSize Dimensions) error {
+	if gh, a := g.getGhost(level, terminalSize); gh != nil {
+		// the maze is generated for the terminal size the attempt was played in.
+		err := g.loadMaze(level, a.Seed, Dimensions{Length: a.Replay.Length, Width: a.Replay.Width})
+		if err == nil {
+			g.setGhost(gh)
+			return nil
+		}
+	}
+
+	if err := g.loadMaze(level, seed, terminalSize); err != nil {
+		return err
+	}
+
+	g.setGhost(nil)
+
+	return nil
+}
+
+// setGhost updates the ghost raced against in the current level.
+func (g *Game) setGhost(gh *ghost) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.ghost = gh
+}
+
+// getGhost returns the ghost of the best attempt of the level provided selected by the
+// ghost mode together with the attempt. Nil is returned if no such attempt exists or its
+// maze does not fit the terminal size provided.
+func (g *Game) getGhost(level int, terminalSize Dimensions) (*ghost, *db.Attempt) {
+	g.mu.Lock()
+	store, user, mode := g.store, g.user, g.ghostMode
+	g.mu.Unlock()
+
+	if store == nil || user == nil || len(mode) == 0 {
+		return nil, nil
+	}
+
+	u := &db.UserInfor{Level: level}
+	if mode == ghostBest {
+		u.TapooID = user.TapooID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	a, err := store.GetBestAttempt(ctx, u)
+	if err != nil {
+		return nil, nil
+	}
+
+	gh, err := newGhost(a)
+	if err != nil || !gh.fits(terminalSize) {
+		return nil, nil
+	}
+
+	return gh, a
+}
+
+// moveGhost moves the ghost to where it was after the time played provided. The player
+// is compared to the ghost every time the player moves to a cell the ghost went through.
+func (g *Game) moveGhost(spent time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	gh := g.ghost
+	if gh == nil {
+		return
+	}
+
+	gh.position = gh.getPosition(spent)
+
+	if cell := g.Config.StartPosition; cell != gh.cell {
+		gh.cell = cell
+
+		if at, ok := gh.visits[cell]; ok {
+			gh.delta, gh.compared = spent-at, true
+		}
+	}
+}
+
+// checkGhostMode checks if the ghost mode provided is supported. An empty mode disables
+// the ghost.
+func checkGhostMode(mode string) error {
+	switch mode {
+	case "", ghostBest, ghostLeader:
+		return nil
+	}
+
+	return fmt.Errorf("invalid ghost mode found: %q, use %s or %s", mode, ghostBest, ghostLeader)
+}
@@ -0,0 +1,261 @@
+package maze
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	"github.com/dmigwi/tapoo/maze/solver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// getGhostAttempt returns a succeeded attempt of the level one maze generated from the
+// seed 2018 along the shortest path. The player moves every half a second and pauses for
+// ten seconds after the first move.
+func getGhostAttempt() (*db.Attempt, []int) {
+	game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+	So(err, ShouldBeNil)
+
+	solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+	So(err, ShouldBeNil)
+
+	var (
+		moves  = getDirections(game.Config, solution.Path)
+		replay = &db.Replay{Length: 40, Width: 20}
+		at     = int64(0)
+	)
+
+	for i, direction := range moves {
+		at += 500
+		replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayMove, Direction: direction})
+
+		if i == 0 {
+			replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayStatuses[pause]},
+				db.ReplayEvent{Time: at + 10000, Kind: replayStatuses[proceed]})
+			at += 10000
+		}
+	}
+
+	replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayStatuses[succeeded]})
+
+	return &db.Attempt{TapooID: "Vf2TqN5MB", Level: 1, Seed: 2018, Outcome: db.Succeeded, Moves: len(moves),
+		Replay: replay}, solution.Path
+}
+
+// TestNewGhost tests the functionality of newGhost
+func TestNewGhost(t *testing.T) {
+	Convey("TestNewGhost: Given a succeeded attempt paused after the first move", t, func() {
+		attempt, path := getGhostAttempt()
+
+		Convey("the ghost should move through the path excluding the pause from its times", func() {
+			gh, err := newGhost(attempt)
+
+			So(err, ShouldBeNil)
+			So(gh.owner, ShouldEqual, "Vf2TqN5MB")
+			So(gh.steps, ShouldHaveLength, len(path)-1)
+			So(gh.steps[0], ShouldResemble, ghostStep{at: 500 * time.Millisecond, cell: path[1]})
+			So(gh.steps[2], ShouldResemble, ghostStep{at: 1500 * time.Millisecond, cell: path[3]})
+			So(gh.visits[path[3]], ShouldEqual, 1500*time.Millisecond)
+
+			So(gh.getPosition(0), ShouldEqual, path[0])
+			So(gh.getPosition(1200*time.Millisecond), ShouldEqual, path[2])
+			So(gh.getPosition(time.Hour), ShouldEqual, path[len(path)-1])
+
+			So(gh.fits(Dimensions{Length: 40, Width: 20}), ShouldBeTrue)
+			So(gh.fits(Dimensions{Length: 5, Width: 5}), ShouldBeFalse)
+		})
+
+		Convey("an attempt that did not locate the target, an error should be returned", func() {
+			attempt.Replay.Events = attempt.Replay.Events[:4]
+
+			gh, err := newGhost(attempt)
+
+			So(gh, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "the ghost attempt did not locate the target")
+		})
+
+		Convey("an attempt without a replay, an error should be returned", func() {
+			attempt.Replay = nil
+
+			_, err := newGhost(attempt)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "the attempt replay was not found")
+		})
+	})
+}
+
+// TestMoveGhost tests the functionality of moveGhost
+func TestMoveGhost(t *testing.T) {
+	Convey("TestMoveGhost: Given a player racing against a ghost", t, func() {
+		attempt, path := getGhostAttempt()
+
+		gh, err := newGhost(attempt)
+		So(err, ShouldBeNil)
+
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		game.ghost = gh
+
+		Convey("the player should not be compared before moving", func() {
+			game.moveGhost(time.Second)
+
+			So(gh.position, ShouldEqual, path[2])
+			So(gh.getDelta(), ShouldEqual, "--")
+		})
+
+		Convey("a player behind the ghost should have a positive delta", func() {
+			for _, direction := range getDirections(game.Config, path[:3]) {
+				game.Move(direction)
+			}
+
+			game.moveGhost(3200 * time.Millisecond)
+
+			So(gh.position, ShouldEqual, path[6])
+			So(gh.getDelta(), ShouldEqual, "+2.2s")
+
+			Convey("and the delta should only change once the player moves", func() {
+				game.moveGhost(5 * time.Second)
+
+				So(gh.getDelta(), ShouldEqual, "+2.2s")
+			})
+		})
+
+		Convey("a player ahead of the ghost should have a negative delta", func() {
+			for _, direction := range getDirections(game.Config, path[:5]) {
+				game.Move(direction)
+			}
+
+			game.moveGhost(900 * time.Millisecond)
+
+			So(gh.position, ShouldEqual, path[1])
+			So(gh.getDelta(), ShouldEqual, "-1.1s")
+		})
+	})
+}
+
+// slowStore defines a store that takes the delay provided to fetch the best attempts.
+// Fetched holds when the last best attempt was fetched.
+type slowStore struct {
+	db.Store
+	delay   time.Duration
+	fetched time.Time
+}
+
+// GetBestAttempt fetches the best attempt after the store delay.
+func (s *slowStore) GetBestAttempt(ctx context.Context, u *db.UserInfor) (*db.Attempt, error) {
+	time.Sleep(s.delay)
+	defer func() { s.fetched = time.Now() }()
+
+	return s.Store.GetBestAttempt(ctx, u)
+}
+
+// TestLoadLevel tests the functionality of loadLevel
+func TestLoadLevel(t *testing.T) {
+	Convey("TestLoadLevel: Given a store holding the best attempt of a level", t, func() {
+		var (
+			ctx          = context.Background()
+			store        = db.NewMemoryStore()
+			terminalSize = Dimensions{Length: 40, Width: 20}
+			attempt, _   = getGhostAttempt()
+		)
+
+		defer store.Close()
+
+		for _, tapooID := range []string{"Vf2TqN5MB", "VZWeOq2p"} {
+			_, err := store.GetOrCreateUser(ctx, &db.UserInfor{TapooID: tapooID})
+			So(err, ShouldBeNil)
+		}
+
+		_, err := store.SaveAttempt(ctx, attempt)
+		So(err, ShouldBeNil)
+
+		game, err := NewGame(1, 99, terminalSize)
+		So(err, ShouldBeNil)
+
+		game.store, game.user = store, &db.UserInfor{TapooID: "VZWeOq2p"}
+
+		Convey("the leader ghost should be raced in the maze it was played in", func() {
+			game.ghostMode = ghostLeader
+
+			So(game.loadLevel(1, 99, terminalSize), ShouldBeNil)
+			So(game.ghost, ShouldNotBeNil)
+			So(game.ghost.owner, ShouldEqual, "Vf2TqN5MB")
+			So(game.Config.Seed, ShouldEqual, 2018)
+			So(game.Position(), ShouldEqual, game.ghost.start)
+
+			Convey("while the next levels should still be generated from the player's seed", func() {
+				So(game.seed, ShouldEqual, 99)
+
+				path := filepath.Join(os.TempDir(), "tapoo_ghost_save.json")
+				defer os.Remove(path)
+
+				So(game.saveGame(path), ShouldBeNil)
+
+				s, err := readSaveGame(path)
+				So(err, ShouldBeNil)
+
+				resumed, err := s.loadGame(terminalSize)
+				So(err, ShouldBeNil)
+				So(resumed.Config.Seed, ShouldEqual, 2018)
+				So(resumed.seed, ShouldEqual, 99)
+			})
+		})
+
+		Convey("a game created without a maze should only generate the maze of the ghost", func() {
+			game := newGame(99)
+			game.store, game.user, game.ghostMode = store, &db.UserInfor{TapooID: "VZWeOq2p"}, ghostLeader
+
+			So(game.Grid, ShouldBeNil)
+			So(game.loadLevel(1, game.seed, terminalSize), ShouldBeNil)
+			So(game.ghost, ShouldNotBeNil)
+			So(game.Config.Seed, ShouldEqual, 2018)
+			So(game.levelLog, ShouldHaveLength, 1)
+		})
+
+		Convey("no ghost should be raced if the player has no best attempt", func() {
+			game.ghostMode = ghostBest
+
+			So(game.loadLevel(1, 99, terminalSize), ShouldBeNil)
+			So(game.ghost, ShouldBeNil)
+			So(game.Config.Seed, ShouldEqual, 99)
+		})
+
+		Convey("no ghost should be raced if its maze does not fit the terminal", func() {
+			game.ghostMode = ghostLeader
+
+			So(game.loadLevel(1, 2018, Dimensions{Length: 5, Width: 5}), ShouldNotBeNil)
+			So(game.ghost, ShouldBeNil)
+			So(game.Config.Seed, ShouldEqual, 99)
+		})
+
+		Convey("the level time should only start once the best attempt is fetched", func() {
+			slow := &slowStore{Store: store, delay: 50 * time.Millisecond}
+			game.store, game.ghostMode = slow, ghostBest
+
+			So(game.loadLevel(1, 99, terminalSize), ShouldBeNil)
+			So(game.ghost, ShouldBeNil)
+			So(game.startedAt.Before(slow.fetched), ShouldBeFalse)
+			So(game.levelLog, ShouldHaveLength, 1)
+		})
+	})
+}
+
+// TestCheckGhostMode tests the functionality of checkGhostMode
+func TestCheckGhostMode(t *testing.T) {
+	Convey("TestCheckGhostMode: Given a ghost mode, only the modes supported should be valid", t, func() {
+		for _, mode := range []string{"", ghostBest, ghostLeader} {
+			So(checkGhostMode(mode), ShouldBeNil)
+		}
+
+		err := checkGhostMode("fastest")
+
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, `invalid ghost mode found: "fastest"`)
+	})
+}
@@ -0,0 +1,182 @@
+package maze
+
+// The following bits define the walls of a cell that are open i.e. the walls
+// that have been replaced by a common path with the neighboring cell.
+const (
+	openTop uint8 = 1 << iota
+	openRight
+	openBottom
+	openLeft
+)
+
+// Grid defines the topology of the maze. Every cell holds a bitset of its open walls,
+// thus the maze can be generated, navigated and solved without its terminal printable
+// view. Cells are numbered from one, row by row starting from the top left cell.
+type Grid struct {
+	Length int
+	Width  int
+
+	cells []uint8
+}
+
+// NewGrid creates a grid with the provided dimensions where all the walls are closed.
+func NewGrid(length, width int) *Grid {
+	return &Grid{Length: length, Width: width, cells: make([]uint8, length*width)}
+}
+
+// Cells returns the total number of cells in the grid.
+func (g *Grid) Cells() int {
+	return g.Length * g.Width
+}
+
+// Size returns the number of cells along the horizontal and the vertical edges.
+func (g *Grid) Size() (length, width int) {
+	return g.Length, g.Width
+}
+
+// Walls returns the bitset of the open walls of the provided cell.
+func (g *Grid) Walls(cellNo int) uint8 {
+	if !g.isValid(cellNo) {
+		return 0
+	}
+	return g.cells[cellNo-1]
+}
+
+// Carve creates a common path between the two neighboring cells provided.
+func (g *Grid) Carve(currentCellNo, newCellNo int) {
+	wall, opposite := g.getWall(currentCellNo, newCellNo)
+	if wall == 0 {
+		return
+	}
+
+	g.cells[currentCellNo-1] |= wall
+	g.cells[newCellNo-1] |= opposite
+}
+
+// IsPassable checks if a common path exists between the two neighboring cells provided.
+func (g *Grid) IsPassable(currentCellNo, newCellNo int) bool {
+	wall, _ := g.getWall(currentCellNo, newCellNo)
+	return wall != 0 && g.cells[currentCellNo-1]&wall != 0
+}
+
+// Neighbors returns the cells next to the provided cell irrespective of the walls between them.
+// The neighbors are returned in the order bottom, left, right and top.
+func (g *Grid) Neighbors(cellNo int) []int {
+	var cells []int
+
+	if !g.isValid(cellNo) {
+		return cells
+	}
+
+	col := (cellNo - 1) % g.Length
+
+	if cellNo+g.Length <= g.Cells() {
+		cells = append(cells, cellNo+g.Length)
+	}
+
+	if col > 0 {
+		cells = append(cells, cellNo-1)
+	}
+
+	if col < g.Length-1 {
+		cells = append(cells, cellNo+1)
+	}
+
+	if cellNo-g.Length > 0 {
+		cells = append(cells, cellNo-g.Length)
+	}
+
+	return cells
+}
+
+// OpenNeighbors returns the neighboring cells that have a common path with the provided cell.
+func (g *Grid) OpenNeighbors(cellNo int) []int {
+	var cells []int
+
+	for _, neighbor := range g.Neighbors(cellNo) {
+		if g.IsPassable(cellNo, neighbor) {
+			cells = append(cells, neighbor)
+		}
+	}
+
+	return cells
+}
+
+// isValid checks if the provided cell exists in the grid.
+func (g *Grid) isValid(cellNo int) bool {
+	return cellNo > 0 && cellNo <= g.Cells()
+}
+
+// getWall returns the wall of the current cell and the opposite wall of the new cell
+// that separate the two cells. If the cells are not neighbors zero values are returned.
+func (g *Grid) getWall(currentCellNo, newCellNo int) (uint8, uint8) {
+	if !g.isValid(currentCellNo) || !g.isValid(newCellNo) {
+		return 0, 0
+	}
+
+	sameRow := (currentCellNo-1)/g.Length == (newCellNo-1)/g.Length
+
+	switch {
+	case newCellNo == currentCellNo+g.Length:
+		return openBottom, openTop
+
+	case newCellNo == currentCellNo-g.Length:
+		return openTop, openBottom
+
+	case newCellNo == currentCellNo+1 && sameRow:
+		return openRight, openLeft
+
+	case newCellNo == currentCellNo-1 && sameRow:
+		return openLeft, openRight
+	}
+
+	return 0, 0
+}
+
+// getDistances returns the length of the path from the provided cell to every other cell
+// using the cell numbers as the indices. Unreachable cells have a distance of -1.
+func (g *Grid) getDistances(cellNo int) []int {
+	distance, _ := g.walk(cellNo)
+	return distance
+}
+
+// getFarthestCell returns the cell with the longest path from the provided cell.
+// It becomes the target of the maze since it is the hardest cell to reach.
+func (g *Grid) getFarthestCell(cellNo int) int {
+	distance, order := g.walk(cellNo)
+	farthest := cellNo
+
+	for _, cell := range order {
+		if distance[cell] > distance[farthest] {
+			farthest = cell
+		}
+	}
+
+	return farthest
+}
+
+// walk does a breadth first traversal of the grid from the provided cell. It returns the
+// distance of every cell from the provided cell and the order in which the cells were visited.
+func (g *Grid) walk(cellNo int) ([]int, []int) {
+	var (
+		order    = []int{cellNo}
+		distance = make([]int, g.Cells()+1)
+	)
+
+	for i := range distance {
+		distance[i] = -1
+	}
+
+	distance[cellNo] = 0
+
+	for i := 0; i < len(order); i++ {
+		for _, neighbor := range g.OpenNeighbors(order[i]) {
+			if distance[neighbor] < 0 {
+				distance[neighbor] = distance[order[i]] + 1
+				order = append(order, neighbor)
+			}
+		}
+	}
+
+	return distance, order
+}
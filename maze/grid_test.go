@@ -0,0 +1,94 @@
+package maze
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestCarve tests the functionality of Carve and IsPassable
+func TestCarve(t *testing.T) {
+	Convey("TestCarve: Given a grid of 6 by 5 cells with all the walls closed", t, func() {
+		grid := NewGrid(6, 5)
+
+		Convey("neighboring cells should have a common path between them after carving", func() {
+			for _, cell := range []int{8, 20, 13, 15} {
+				So(grid.IsPassable(14, cell), ShouldBeFalse)
+
+				grid.Carve(14, cell)
+
+				So(grid.IsPassable(14, cell), ShouldBeTrue)
+				So(grid.IsPassable(cell, 14), ShouldBeTrue)
+			}
+
+			So(grid.Walls(14), ShouldEqual, openTop|openRight|openBottom|openLeft)
+			So(grid.Walls(8), ShouldEqual, openBottom)
+			So(grid.OpenNeighbors(14), ShouldResemble, []int{20, 13, 15, 8})
+		})
+
+		Convey("cells that are not neighbors should never have a common path between them", func() {
+			for _, cells := range [][2]int{{6, 7}, {7, 6}, {1, 3}, {30, 31}, {0, 1}, {1, 1}} {
+				grid.Carve(cells[0], cells[1])
+
+				So(grid.IsPassable(cells[0], cells[1]), ShouldBeFalse)
+			}
+
+			for cell := 1; cell <= grid.Cells(); cell++ {
+				So(grid.Walls(cell), ShouldEqual, 0)
+			}
+		})
+	})
+}
+
+// TestNeighbors tests the functionality of Neighbors
+func TestNeighbors(t *testing.T) {
+	Convey("TestNeighbors: Given a grid of 6 by 5 cells and a cell number", t, func() {
+		grid := NewGrid(6, 5)
+
+		Convey("the neighbors should match the neighbors computed from the maze dimensions", func() {
+			val := &Dimensions{Length: 6, Width: 5}
+
+			for cell := 1; cell <= grid.Cells(); cell++ {
+				var expected []int
+
+				n := val.getCellNeighbors(cell)
+				for _, neighbor := range []int{n.Bottom, n.Left, n.Right, n.Top} {
+					if neighbor != 0 {
+						expected = append(expected, neighbor)
+					}
+				}
+
+				So(grid.Neighbors(cell), ShouldResemble, expected)
+			}
+		})
+
+		Convey("that does not exist, no neighbors should be returned", func() {
+			So(grid.Neighbors(0), ShouldBeEmpty)
+			So(grid.Neighbors(31), ShouldBeEmpty)
+		})
+	})
+}
+
+// TestLargeMaze tests that mazes larger than the maxLevel maze can be generated.
+func TestLargeMaze(t *testing.T) {
+	Convey("TestLargeMaze: Given the dimensions of a maze much larger than the maxLevel maze", t, func() {
+		Convey("the maze should be generated with all its cells connected", func() {
+			val := &Dimensions{Length: 1000, Width: 500}
+			grid := val.generateMaze()
+
+			So(int(generateMazeArea(maxLevel)), ShouldBeLessThan, grid.Cells())
+
+			distance := grid.getDistances(val.StartPosition)
+			So(distance[val.FinalPosition], ShouldBeGreaterThan, 0)
+
+			unreachable := 0
+			for cell := 1; cell <= grid.Cells(); cell++ {
+				if distance[cell] < 0 {
+					unreachable++
+				}
+			}
+
+			So(unreachable, ShouldEqual, 0)
+		})
+	})
+}
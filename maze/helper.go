@@ -2,7 +2,10 @@ package maze
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
+	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -136,11 +139,43 @@ func (config *Dimensions) getCellNeighbors(cellNo int) cellNeighbors {
 	return neighbors
 }
 
-// getRandomNo returns a random number generated from
-// the current timestamp and should be less the max value
-// provided and greater than or equal to zero. (0 <= X < max)
-func getRandomNo(max int) int {
-	return int(time.Now().UnixNano() % int64(max))
+// getRandomNo returns a random number generated from the maze seed
+// and should be less the max value provided and greater than or
+// equal to zero. (0 <= X < max)
+func (config *Dimensions) getRandomNo(max int) int {
+	if config.random == nil {
+		config.random = rand.New(rand.NewSource(config.Seed))
+	}
+
+	return config.random.Intn(max)
+}
+
+// NewSeed returns a new random seed that can be used to generate a maze.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// ParseSeed converts the seed string provided into the seed used to generate the maze.
+// Numeric seed strings are used as they are while any other string is hashed.
+func ParseSeed(seed string) int64 {
+	if val, err := strconv.ParseInt(seed, 10, 64); err == nil {
+		return val
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+
+	return int64(hash.Sum64())
+}
+
+// getMazeSeed returns the seed parsed from the seed string provided. If the
+// seed string is empty a new random seed is returned.
+func getMazeSeed(seed string) int64 {
+	if len(seed) > 0 {
+		return ParseSeed(seed)
+	}
+
+	return NewSeed()
 }
 
 // getCeiledDivisor calculates the ceiled divisor of the two values passed.
@@ -172,10 +172,33 @@ func TestGetCellNeighbors(t *testing.T) {
 // TestGetRandomNo tests the functionality of getRandomNo
 func TestGetRandomNo(t *testing.T) {
 	Convey("TestGetRandomNo: Given a value ", t, func() {
-		Convey("that is greater than zero, the random number generated should be greater than zero but less than or equal to the value provided", func() {
-			val := getRandomNo(12)
-			So(val, ShouldBeLessThanOrEqualTo, 12)
-			So(val, ShouldBeGreaterThan, 0)
+		Convey("that is greater than zero, the random number generated should be greater than or equal to zero but less than the value provided", func() {
+			val := (&Dimensions{Seed: NewSeed()}).getRandomNo(12)
+			So(val, ShouldBeLessThan, 12)
+			So(val, ShouldBeGreaterThanOrEqualTo, 0)
+		})
+
+		Convey("and the same seed, the same sequence of random numbers should be generated", func() {
+			first, second := &Dimensions{Seed: 2018}, &Dimensions{Seed: 2018}
+
+			for i := 0; i < 20; i++ {
+				So(first.getRandomNo(100), ShouldEqual, second.getRandomNo(100))
+			}
+		})
+	})
+}
+
+// TestParseSeed tests the functionality of ParseSeed
+func TestParseSeed(t *testing.T) {
+	Convey("TestParseSeed: Given a seed string", t, func() {
+		Convey("that is numeric, its numeric value should be returned", func() {
+			So(ParseSeed("1516274582"), ShouldEqual, 1516274582)
+			So(ParseSeed("-25"), ShouldEqual, -25)
+		})
+
+		Convey("that is not numeric, the same value should be returned every time", func() {
+			So(ParseSeed("tapoo"), ShouldEqual, ParseSeed("tapoo"))
+			So(ParseSeed("tapoo"), ShouldNotEqual, ParseSeed("tapoO"))
 		})
 	})
 }
@@ -0,0 +1,294 @@
+package maze
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+// errHidingAtStart is returned if the hider attempts to hide at the starting cell.
+var errHidingAtStart = errors.New("the hiding spot cannot be the starting cell")
+
+// Hotseat defines a hide and seek game played by two players taking turns on the same
+// terminal. In every round the hider navigates the maze to choose a hiding spot, then
+// the seeker navigates the same maze to locate the hider. The players swap their roles
+// after every round. Players are numbered from zero in the scores array.
+type Hotseat struct {
+	Game   *Game
+	Seed   int64
+	Round  int
+	Rounds int
+	Scores [2]int
+
+	terminalSize Dimensions
+}
+
+// NewHotseat creates a new hide and seek game with the number of rounds provided. The maze
+// of every round is generated from the seed provided and the round number.
+func NewHotseat(rounds int, seed int64, terminalSize Dimensions) (*Hotseat, error) {
+	if rounds < 1 {
+		return nil, errors.New("invalid number of rounds: at least one round should be played")
+	}
+
+	game, err := NewGame(1, seed, terminalSize)
+	if err != nil {
+		return nil, err
+	}
+
+	game.setRole(hider)
+
+	return &Hotseat{Game: game, Seed: seed, Rounds: rounds, terminalSize: terminalSize}, nil
+}
+
+// Hider returns the player hiding in the current round.
+func (h *Hotseat) Hider() int {
+	return h.Round % 2
+}
+
+// Seeker returns the player seeking in the current round.
+func (h *Hotseat) Seeker() int {
+	return (h.Round + 1) % 2
+}
+
+// IsOver checks if all the rounds have been played.
+func (h *Hotseat) IsOver() bool {
+	return h.Round >= h.Rounds
+}
+
+// Hide sets the current hider position as the hiding spot and moves the player back to
+// the starting cell so that the seeker can start seeking. The starting cell cannot be
+// used as the hiding spot since the seeker would find the hider without moving.
+func (h *Hotseat) Hide() error {
+	g := h.Game
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Config.StartPosition == g.start {
+		return errHidingAtStart
+	}
+
+	g.Config.FinalPosition = g.Config.StartPosition
+	g.Config.StartPosition = g.start
+	g.role, g.found = seeker, false
+
+	return nil
+}
+
+// EndRound scores both players using the time the seeker took to locate the hider out of
+// the time limit provided, both in seconds. The next round maze is then loaded if more
+// rounds are yet to be played.
+func (h *Hotseat) EndRound(timeLimit, elapsed int, found bool) error {
+	hiderScores, seekerScores := getHotseatScores(timeLimit, elapsed, found)
+
+	h.Scores[h.Hider()] += hiderScores
+	h.Scores[h.Seeker()] += seekerScores
+	h.Round++
+
+	if h.IsOver() {
+		return nil
+	}
+
+	if err := h.Game.loadMaze(1, h.Seed+int64(h.Round), h.terminalSize); err != nil {
+		return err
+	}
+
+	h.Game.setRole(hider)
+
+	return nil
+}
+
+// Winner returns the player with the highest scores. If both players have the
+// same scores -1 is returned.
+func (h *Hotseat) Winner() int {
+	switch {
+	case h.Scores[0] > h.Scores[1]:
+		return 0
+
+	case h.Scores[1] > h.Scores[0]:
+		return 1
+	}
+
+	return -1
+}
+
+// getHotseatScores returns the scores earned by the hider and the seeker. The seeker earns
+// the time left after locating the hider while the hider earns the time the seeker spent
+// seeking. If the hider is not located, the hider earns the whole time limit.
+func getHotseatScores(timeLimit, elapsed int, found bool) (int, int) {
+	if !found {
+		return timeLimit * 100, 0
+	}
+
+	if elapsed > timeLimit {
+		elapsed = timeLimit
+	}
+
+	return elapsed * 100, (timeLimit - elapsed) * 100
+}
+
+// getHidingTimeLimit returns the time in seconds that the hider has to choose a hiding
+// spot. It is half of the time the seeker has to locate the hider.
+func getHidingTimeLimit(totalCells int) int {
+	return getCeiledDivisor(totalCells, 2)
+}
+
+// playTurn runs the current player's turn until the time limit provided in seconds elapses,
+// the player quits or the turn is completed. The hider's turn is completed once the
+// hiding spot is confirmed. It returns the status that ended the turn and the time spent
+// in seconds.
+func (h *Hotseat) playTurn(timeLimit int) (int, int) {
+	var (
+		g        = h.Game
+		timer    = time.NewTicker(refreshInterval)
+		rejected bool
+	)
+
+	defer timer.Stop()
+
+	// the game clock times the turn thus it stops once the turn is over.
+	g.clock.reset(time.Duration(timeLimit)*time.Second, 0)
+	g.clock.resume()
+
+	defer g.clock.pause()
+
+	elapsed := func() int { return int(g.clock.getPlayed() / time.Second) }
+
+	for {
+		select {
+		case <-timer.C:
+			if g.clock.isTimedOut() {
+				return failed, timeLimit
+			}
+
+			timeLeft := timeLimit - elapsed()
+
+			g.mu.Lock()
+			switch {
+			case g.role == hider && rejected && g.Config.StartPosition == g.start:
+				g.message = fmt.Sprintf(hidingAtStartMsg, h.Hider()+1, timeLeft)
+
+			case g.role == hider:
+				g.message = fmt.Sprintf(hidingMsg, h.Hider()+1, timeLeft)
+
+			default:
+				g.message = fmt.Sprintf(seekingMsg, h.Seeker()+1, h.Hider()+1, timeLeft, g.Config.Seed)
+			}
+			g.mu.Unlock()
+
+			g.refreshUI()
+
+			if g.getRole() == seeker && g.IsTargetFound() {
+				return succeeded, elapsed()
+			}
+
+		case returnedStatus := <-g.status:
+			switch {
+			case returnedStatus == quit:
+				return quit, elapsed()
+
+			case returnedStatus == ready && g.getRole() == hider:
+				// the turn goes on if the hiding spot is rejected.
+				if rejected = h.Hide() != nil; !rejected {
+					return ready, elapsed()
+				}
+			}
+		}
+	}
+}
+
+// waitFor blocks until the players either quit the game or press Enter. It returns
+// true if the players would like to proceed. The game is paused while waiting so that
+// the next player cannot move before the turn starts.
+func (h *Hotseat) waitFor() bool {
+	h.Game.setPaused(true)
+	defer h.Game.setPaused(false)
+
+	for {
+		switch <-h.Game.status {
+		case ready, proceed:
+			return true
+
+		case quit:
+			return false
+		}
+	}
+}
+
+// play runs the hide and seek rounds until all the rounds are played or the players quit.
+func (h *Hotseat) play() error {
+	for !h.IsOver() {
+		g := h.Game
+		totalCells := g.Config.Length * g.Config.Width
+
+		returnedStatus, _ := h.playTurn(getHidingTimeLimit(totalCells))
+		if returnedStatus == quit {
+			return nil
+		}
+
+		// the hider that runs out of time at the starting cell is found at once.
+		found, elapsed := true, 0
+
+		if returnedStatus == ready || h.Hide() == nil {
+			g.blankUI(fmt.Sprintf(handoverMsg, h.Hider()+1, h.Seeker()+1), hotseatNavigation)
+			if !h.waitFor() {
+				return nil
+			}
+
+			returnedStatus, elapsed = h.playTurn(totalCells)
+			if returnedStatus == quit {
+				return nil
+			}
+
+			found = returnedStatus == succeeded
+		}
+
+		msg := fmt.Sprintf(hiderNotFound, h.Round+1, h.Rounds, h.Seeker()+1, h.Hider()+1)
+		if found {
+			msg = fmt.Sprintf(hiderFound, h.Round+1, h.Rounds, h.Seeker()+1, h.Hider()+1)
+		}
+
+		if err := h.EndRound(totalCells, elapsed, found); err != nil {
+			return err
+		}
+
+		g.blankUI(msg, fmt.Sprintf(hotseatScores, h.Scores[0], h.Scores[1]), hotseatNavigation)
+		if !h.waitFor() {
+			return nil
+		}
+	}
+
+	msg := hotseatDraw
+	if winner := h.Winner(); winner >= 0 {
+		msg = fmt.Sprintf(hotseatWinner, winner+1)
+	}
+
+	h.Game.blankUI(msg, fmt.Sprintf(hotseatScores, h.Scores[0], h.Scores[1]), gameOverNavigation)
+	h.waitFor()
+
+	return nil
+}
+
+// StartHotseat defines where the hide and seek game for two players sharing the same
+// terminal starts at. The seed string provided is used to reproduce the mazes of all
+// the rounds on a terminal of the same size, if it is empty a new random seed is used.
+// The error that ended the game is returned once the terminal is restored.
+func StartHotseat(seed string, rounds int) error {
+	if err := termbox.Init(); err != nil {
+		return err
+	}
+
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	hotseat, err := NewHotseat(rounds, getMazeSeed(seed), getTerminalSize(termbox.Size()))
+	if err != nil {
+		return err
+	}
+
+	go hotseat.Game.handleKeyboardMapping()
+
+	return hotseat.play()
+}
@@ -0,0 +1,106 @@
+package maze
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestNewHotseat tests the functionality of NewHotseat
+func TestNewHotseat(t *testing.T) {
+	Convey("TestNewHotseat: Given the number of rounds, the seed and the terminal size", t, func() {
+		Convey("where no rounds are to be played, an error should be returned", func() {
+			hotseat, err := NewHotseat(0, 1, Dimensions{Length: 40, Width: 20})
+
+			So(hotseat, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid number of rounds")
+		})
+
+		Convey("where the rounds are valid, the first player should hide first", func() {
+			hotseat, err := NewHotseat(2, 1, Dimensions{Length: 40, Width: 20})
+
+			So(err, ShouldBeNil)
+			So(hotseat.Hider(), ShouldEqual, 0)
+			So(hotseat.Seeker(), ShouldEqual, 1)
+			So(hotseat.Game.getRole(), ShouldEqual, hider)
+			So(hotseat.IsOver(), ShouldBeFalse)
+		})
+	})
+}
+
+// TestHotseatRounds tests the functionality of Hide and EndRound
+func TestHotseatRounds(t *testing.T) {
+	Convey("TestHotseatRounds: Given a hide and seek game of two rounds", t, func() {
+		hotseat, err := NewHotseat(2, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		g := hotseat.Game
+		start := g.Config.StartPosition
+
+		Convey("the hiding spot should be where the hider stopped and the seeker should start"+
+			" from the starting cell", func() {
+			for _, direction := range []string{"LEFT", "UP", "RIGHT", "DOWN", "LEFT", "UP"} {
+				g.Move(direction)
+			}
+
+			spot := g.Config.StartPosition
+			So(spot, ShouldNotEqual, start)
+
+			So(hotseat.Hide(), ShouldBeNil)
+
+			So(g.Config.FinalPosition, ShouldEqual, spot)
+			So(g.Config.StartPosition, ShouldEqual, start)
+			So(g.getRole(), ShouldEqual, seeker)
+			So(g.IsTargetFound(), ShouldBeFalse)
+
+			Convey("and both players should be scored before swapping their roles", func() {
+				firstMaze := g.Maze
+
+				So(hotseat.EndRound(100, 30, true), ShouldBeNil)
+
+				So(hotseat.Scores, ShouldResemble, [2]int{3000, 7000})
+				So(hotseat.Hider(), ShouldEqual, 1)
+				So(hotseat.Seeker(), ShouldEqual, 0)
+				So(g.getRole(), ShouldEqual, hider)
+				So(g.Maze, ShouldNotResemble, firstMaze)
+
+				So(hotseat.EndRound(100, 100, false), ShouldBeNil)
+
+				So(hotseat.Scores, ShouldResemble, [2]int{3000, 17000})
+				So(hotseat.IsOver(), ShouldBeTrue)
+				So(hotseat.Winner(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("the starting cell should be rejected as the hiding spot", func() {
+			So(hotseat.Hide(), ShouldEqual, errHidingAtStart)
+
+			So(g.Config.StartPosition, ShouldEqual, start)
+			So(g.getRole(), ShouldEqual, hider)
+		})
+	})
+}
+
+// TestGetHotseatScores tests the functionality of getHotseatScores
+func TestGetHotseatScores(t *testing.T) {
+	Convey("TestGetHotseatScores: Given the time limit, the time spent seeking and if the hider was found", t, func() {
+		Convey("the hider and the seeker scores should be returned", func() {
+			for _, val := range []struct {
+				timeLimit, elapsed int
+				found              bool
+				hider, seeker      int
+			}{
+				{100, 0, true, 0, 10000},
+				{100, 40, true, 4000, 6000},
+				{100, 120, true, 10000, 0},
+				{100, 100, false, 10000, 0},
+			} {
+				hiderScores, seekerScores := getHotseatScores(val.timeLimit, val.elapsed, val.found)
+
+				So(hiderScores, ShouldEqual, val.hider)
+				So(seekerScores, ShouldEqual, val.seeker)
+			}
+		})
+	})
+}
@@ -3,6 +3,9 @@ package maze
 import (
 	"errors"
 	"math"
+	"math/rand"
+
+	"github.com/dmigwi/tapoo/maze/solver"
 )
 
 // seed defines the size of the maze to be used in the training level (level 0).
@@ -17,6 +20,21 @@ const diff = 10
 // for users with smaller screen sizes.
 const maxLevel = 290
 
+// minDifficulty and maxDifficulty define the difficulty band of the training level (level 0).
+// The band is shifted upwards by difficultyStep as the levels increase up to the maxLevel.
+const (
+	minDifficulty  = 0.26
+	maxDifficulty  = 0.40
+	difficultyStep = 0.04
+)
+
+// intensityStep defines the number of levels played before the maze walls get thicker.
+const intensityStep = 5
+
+// maxAttempts defines the number of mazes that can be generated while looking for a
+// maze whose difficulty is within the level's difficulty band.
+const maxAttempts = 16
+
 // generateMazeArea generates the full maze size depending on the provided game level.
 func generateMazeArea(level int) float64 {
 	// Level larger than maxLevel should never be used
@@ -57,9 +75,38 @@ func factorizeMazeArea(mazeArea float64, c Dimensions) []Dimensions {
 	return size
 }
 
+// fitMazeArea returns the possible dimensions of the maze area closest to the provided
+// mazeArea that can be factorized to fit the terminal size provided. Areas with large prime
+// factors cannot be laid out in the terminal even when they are smaller than it thus the
+// areas on either side of the mazeArea are tried until one fits.
+func fitMazeArea(mazeArea float64, c Dimensions) []Dimensions {
+	terminalArea := float64(c.Length * c.Width)
+
+	for i := 0.0; i < mazeArea; i++ {
+		if size := factorizeMazeArea(mazeArea-i, c); len(size) > 0 {
+			return size
+		}
+
+		if mazeArea+i > terminalArea {
+			continue
+		}
+
+		if size := factorizeMazeArea(mazeArea+i, c); len(size) > 0 {
+			return size
+		}
+	}
+
+	return nil
+}
+
 // getMazeDimensions obtains the best length and width measurements for the
-// current level and terminal size provided.
-func getMazeDimensions(level int, terminalSize Dimensions) (*Dimensions, error) {
+// current level and terminal size provided. The maze generation algorithm
+// to be used in the level is also selected. If the level's maze area cannot be laid
+// out in the terminal, the closest area that can is used instead. The seed provided is
+// used to pick the dimensions and is then used to generate the maze. Since the dimensions
+// that fit depend on the terminal size, a seed only reproduces a maze on a terminal of the
+// same size.
+func getMazeDimensions(level int, seed int64, terminalSize Dimensions) (*Dimensions, error) {
 	area := generateMazeArea(level)
 	errMsg := "terminal size is too small for the current level"
 
@@ -67,11 +114,16 @@ func getMazeDimensions(level int, terminalSize Dimensions) (*Dimensions, error)
 		return &Dimensions{}, errors.New(errMsg)
 	}
 
-	dimensions := factorizeMazeArea(area, terminalSize)
+	dimensions := fitMazeArea(area, terminalSize)
 	totalCount := len(dimensions)
+	random := rand.New(rand.NewSource(seed))
 
 	for i := 0; i < totalCount; i++ {
-		return &dimensions[getRandomNo(totalCount)], nil
+		selected := &dimensions[random.Intn(totalCount)]
+		selected.Generator = getGenerator(level)
+		selected.Seed, selected.random = seed, random
+
+		return selected, nil
 	}
 
 	// If the terminal size hasn't been minimized, It should never get here
@@ -83,3 +135,75 @@ func getMazeDimensions(level int, terminalSize Dimensions) (*Dimensions, error)
 func getTerminalSize(h, w int) Dimensions {
 	return Dimensions{Length: (h - 5) / 4, Width: (w - 10) / 2}
 }
+
+// getLevelIntensity returns the intensity of the maze walls used in the provided level.
+// The walls get thicker every intensityStep levels up to the thickest walls supported.
+func getLevelIntensity(level int) int {
+	if intensity := 1 + (level / intensityStep); intensity < 3 {
+		return intensity
+	}
+
+	return 3
+}
+
+// getNextLevel returns the level to be played after the provided level ends with the status
+// provided. The player advances to the next level only after locating the target on time,
+// otherwise the same level is played again.
+func getNextLevel(level, status int) int {
+	if status == succeeded && level < maxLevel {
+		return level + 1
+	}
+
+	return level
+}
+
+// getDifficultyBand returns the lowest and the highest difficulty allowed for the provided level.
+func getDifficultyBand(level int) (float64, float64) {
+	if level >= maxLevel {
+		level = maxLevel
+	}
+
+	shift := difficultyStep * float64(level) / float64(maxLevel)
+
+	return minDifficulty + shift, maxDifficulty + shift
+}
+
+// generateLevelMaze generates the maze to be used in the provided level. If the maze generated
+// does not land in the level's difficulty band, another maze is generated with the next
+// generation algorithm until one does or the maximum attempts are exhausted, in which case
+// the maze closest to the difficulty band is used.
+func (config *Dimensions) generateLevelMaze(level int) (*Grid, *solver.Metrics) {
+	var (
+		best        *Grid
+		bestMetrics *solver.Metrics
+		bestConfig  Dimensions
+
+		bestGap   = math.Inf(1)
+		low, high = getDifficultyBand(level)
+	)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		config.Generator = getGenerator(level + attempt)
+		grid := config.generateMaze()
+
+		metrics, err := solver.Analyze(grid, config.StartPosition, config.FinalPosition)
+		if err != nil {
+			// A maze without a path to the target should never be generated.
+			continue
+		}
+
+		gap := math.Max(low-metrics.Difficulty(), metrics.Difficulty()-high)
+		if gap < bestGap {
+			best, bestMetrics, bestConfig, bestGap = grid, metrics, *config, gap
+		}
+
+		if gap <= 0 {
+			break
+		}
+	}
+
+	config.Generator = bestConfig.Generator
+	config.StartPosition, config.FinalPosition = bestConfig.StartPosition, bestConfig.FinalPosition
+
+	return best, bestMetrics
+}
@@ -75,10 +75,32 @@ func TestFactorizeMazeArea(t *testing.T) {
 	})
 }
 
+// TestFitMazeArea tests the functionality of fitMazeArea
+func TestFitMazeArea(t *testing.T) {
+	Convey("TestFitMazeArea: Given the mazeArea and the terminal size", t, func() {
+		Convey("that can be factorized to fit the terminal, its dimensions should be returned", func() {
+			So(fitMazeArea(100, Dimensions{Length: 30, Width: 20}), ShouldHaveLength, 4)
+		})
+
+		Convey("that cannot be factorized to fit the terminal, the closest area that fits should be used", func() {
+			size := fitMazeArea(610, Dimensions{Length: 60, Width: 60})
+
+			So(size, ShouldNotBeEmpty)
+			So(size[0].Length*size[0].Width, ShouldEqual, 609)
+			So(size[0].Length, ShouldBeLessThanOrEqualTo, 60)
+			So(size[0].Width, ShouldBeLessThanOrEqualTo, 60)
+		})
+
+		Convey("that no area close to it fits the terminal, no dimensions should be returned", func() {
+			So(fitMazeArea(100, Dimensions{Length: 100, Width: 1}), ShouldBeEmpty)
+		})
+	})
+}
+
 // TestGetMazeDimension tests the functionality of getMazeDimension
 func TestGetMazeDimension(t *testing.T) {
 	var testFunc = func(level int, size Dimensions, errMsg string) {
-		mazeSize, err := getMazeDimensions(level, size)
+		mazeSize, err := getMazeDimensions(level, NewSeed(), size)
 
 		if len(errMsg) == 0 {
 			So(err, ShouldBeNil)
@@ -104,10 +126,41 @@ func TestGetMazeDimension(t *testing.T) {
 		Convey("where the maze area is less than the terminal and can be factored, the first value returned should be the dimensions to use", func() {
 			testFunc(1, Dimensions{Length: 20, Width: 10}, "")
 		})
+
+		Convey("where the terminal is large enough for the maxLevel, every level should have dimensions that fit it", func() {
+			for _, size := range []Dimensions{{Length: 60, Width: 60}, {Length: 50, Width: 80}} {
+				for level := 0; level <= maxLevel; level++ {
+					mazeSize, err := getMazeDimensions(level, 2018, size)
+
+					So(err, ShouldBeNil)
+					So(mazeSize.Length, ShouldBeBetweenOrEqual, 5, size.Length)
+					So(mazeSize.Width, ShouldBeBetweenOrEqual, 5, size.Width)
+				}
+			}
+		})
 	})
 
 }
 
+// TestGetMazeDimensionSeed tests that getMazeDimensions is reproducible with the same seed.
+func TestGetMazeDimensionSeed(t *testing.T) {
+	Convey("TestGetMazeDimensionSeed: Given the level, the terminal size and the seed", t, func() {
+		Convey("the same dimensions should be returned every time the same seed is used", func() {
+			for _, seed := range []int64{1, 98, 2018} {
+				first, err := getMazeDimensions(12, seed, Dimensions{Length: 40, Width: 40})
+				So(err, ShouldBeNil)
+
+				second, err := getMazeDimensions(12, seed, Dimensions{Length: 40, Width: 40})
+				So(err, ShouldBeNil)
+
+				So(first.Length, ShouldEqual, second.Length)
+				So(first.Width, ShouldEqual, second.Width)
+				So(first.Seed, ShouldEqual, seed)
+			}
+		})
+	})
+}
+
 // TestGetTerminalSize tests the functionality of getTerminalSize
 func TestGetTerminalSize(t *testing.T) {
 	Convey("TestGetTerminalSize: Given the actual terminal size ", t, func() {
@@ -118,3 +171,90 @@ func TestGetTerminalSize(t *testing.T) {
 		})
 	})
 }
+
+// TestGetDifficultyBand tests the functionality of getDifficultyBand
+func TestGetDifficultyBand(t *testing.T) {
+	Convey("TestGetDifficultyBand: Given the level value", t, func() {
+		Convey("that is zero, the training level difficulty band should be returned", func() {
+			low, high := getDifficultyBand(0)
+
+			So(low, ShouldEqual, minDifficulty)
+			So(high, ShouldEqual, maxDifficulty)
+		})
+
+		Convey("that increases, the difficulty band should shift upwards", func() {
+			low, high := getDifficultyBand(100)
+			nextLow, nextHigh := getDifficultyBand(200)
+
+			So(nextLow, ShouldBeGreaterThan, low)
+			So(nextHigh, ShouldBeGreaterThan, high)
+		})
+
+		Convey("that is greater than the maxLevel, the maxLevel band should be returned", func() {
+			low, high := getDifficultyBand(maxLevel + 20)
+
+			So(low, ShouldAlmostEqual, minDifficulty+difficultyStep)
+			So(high, ShouldAlmostEqual, maxDifficulty+difficultyStep)
+		})
+	})
+}
+
+// TestGenerateLevelMaze tests the functionality of generateLevelMaze
+func TestGenerateLevelMaze(t *testing.T) {
+	Convey("TestGenerateLevelMaze: Given the level and the maze dimensions", t, func() {
+		Convey("the maze generated should land in the level's difficulty band", func() {
+			for _, level := range []int{0, 6, 50, maxLevel} {
+				val := &Dimensions{Length: 60, Width: 50, Seed: int64(level)}
+
+				grid, metrics := val.generateLevelMaze(level)
+				low, high := getDifficultyBand(level)
+
+				So(grid, ShouldNotBeNil)
+				So(metrics.Difficulty(), ShouldBeBetweenOrEqual, low, high)
+
+				So(grid.getFarthestCell(val.StartPosition), ShouldEqual, val.FinalPosition)
+			}
+		})
+
+		Convey("the same seed should always reproduce the same maze", func() {
+			first := &Dimensions{Length: 30, Width: 20, Seed: 2018}
+			second := &Dimensions{Length: 30, Width: 20, Seed: 2018}
+
+			firstGrid, _ := first.generateLevelMaze(6)
+			secondGrid, _ := second.generateLevelMaze(6)
+
+			So(firstGrid, ShouldResemble, secondGrid)
+			So(first.Generator, ShouldResemble, second.Generator)
+			So(first.FinalPosition, ShouldEqual, second.FinalPosition)
+		})
+	})
+}
+
+// TestGetLevelIntensity tests the functionality of getLevelIntensity
+func TestGetLevelIntensity(t *testing.T) {
+	Convey("TestGetLevelIntensity: Given the level value", t, func() {
+		Convey("the walls should get thicker as the levels increase", func() {
+			for level, intensity := range map[int]int{0: 1, 4: 1, 5: 2, 9: 2, 10: 3, maxLevel: 3} {
+				So(getLevelIntensity(level), ShouldEqual, intensity)
+
+				_, err := getWallCharacters(getLevelIntensity(level))
+				So(err, ShouldBeNil)
+			}
+		})
+	})
+}
+
+// TestGetNextLevel tests the functionality of getNextLevel
+func TestGetNextLevel(t *testing.T) {
+	Convey("TestGetNextLevel: Given the level played and how it ended", t, func() {
+		Convey("the player should only advance after locating the target on time", func() {
+			So(getNextLevel(1, succeeded), ShouldEqual, 2)
+			So(getNextLevel(1, failed), ShouldEqual, 1)
+			So(getNextLevel(0, succeeded), ShouldEqual, 1)
+		})
+
+		Convey("the player should never advance past the maxLevel", func() {
+			So(getNextLevel(maxLevel, succeeded), ShouldEqual, maxLevel)
+		})
+	})
+}
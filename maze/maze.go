@@ -1,69 +1,60 @@
 package maze
 
-// visitedCells represents the cells whose numbers are mapped to their respective addresses.
-// It is used in creating and navigating through the maze.
-var visitedCells = map[int]cellAddress{}
+import "math/rand"
 
 // Dimensions defines the actual number of cells that make up the maze along the vertical and
 // the horizontal edges. Length represents the number of the cells along the horizontal
 // edge while Width represents the number of the cells along the vertical edge.
+// StartPosition and FinalPosition are the cell numbers of the player and the target respectively.
+// Seed defines the value used to generate the random numbers used while creating the maze,
+// the same seed always reproduces the same maze, start and target positions.
 type Dimensions struct {
 	Length        int
 	Width         int
-	StartPosition []int
-	FinalPosition []int
+	StartPosition int
+	FinalPosition int
+	Generator     Generator
+	Seed          int64
+
+	random *rand.Rand
 }
 
-// generateMaze converts the created grid view playing field into a series on paths and walls.
+// generateMaze creates the maze topology as a grid of cells with a series of paths and walls.
 // The Maze is created such that only a single path can exists between the starting point and
-// and the goal.
-func (config *Dimensions) generateMaze(intensity int) ([][]string, error) {
-	var neighbors []int
-
-	startPos := config.getStartPosition()
-
-	finalPos, cellsPath, currentPos := []int{1, startPos}, []int{startPos}, startPos
+// and the goal. The paths are carved by the Generator set on the dimensions, if none is set
+// the recursive backtracker is used.
+func (config *Dimensions) generateMaze() *Grid {
+	grid := NewGrid(config.Length, config.Width)
 
-	maze, err := config.createPlayingField(intensity)
-	if err != nil {
-		return [][]string{}, err
+	if config.Generator == nil {
+		config.Generator = RecursiveBacktracker{}
 	}
 
-	config.StartPosition = config.getCellAddress(startPos).MiddleCenter
-
-	visitedCells[currentPos] = config.getCellAddress(currentPos)
+	config.StartPosition = config.getStartPosition()
 
-	cellsPath = append(cellsPath, currentPos)
+	config.Generator.Generate(config, grid, config.StartPosition)
 
-	for len(visitedCells) < (config.Length * config.Width) {
-		for {
-			neighbors = config.getPresentNeighbors(currentPos)
+	config.FinalPosition = grid.getFarthestCell(config.StartPosition)
 
-			if len(neighbors) > 0 {
-				break
-			}
-
-			cellsPath, currentPos = cellsPath[:len(cellsPath)-1], cellsPath[len(cellsPath)-1]
-		}
-
-		startPos = neighbors[getRandomNo(len(neighbors))]
-
-		if _, ok := visitedCells[startPos]; !ok {
-			visitedCells[startPos] = config.getCellAddress(startPos)
+	return grid
+}
 
-			config.createPath(maze[:], currentPos, startPos)
-			cellsPath = append(cellsPath, startPos)
+// renderMaze converts the grid provided into the grid view playing field that is printable
+// on the terminal. The walls characters used are defined by the intensity value provided.
+func (config *Dimensions) renderMaze(grid *Grid, intensity int) ([][]string, error) {
+	maze, err := config.createPlayingField(intensity)
+	if err != nil {
+		return [][]string{}, err
+	}
 
-			if len(cellsPath) > finalPos[0] {
-				finalPos[:][1], finalPos[:][0] = startPos, len(cellsPath)
+	for cell := 1; cell <= grid.Cells(); cell++ {
+		for _, neighbor := range []int{cell + 1, cell + grid.Length} {
+			if grid.IsPassable(cell, neighbor) {
+				config.createPath(maze[:], cell, neighbor)
 			}
-
-			currentPos = startPos
 		}
 	}
 
-	config.FinalPosition = config.getCellAddress(finalPos[1]).MiddleCenter
-
 	return maze[:], config.optimizeMaze(intensity, maze[:])
 }
 
@@ -91,16 +82,16 @@ func (config *Dimensions) createPath(maze [][]string, currentCellNo, newCellNo i
 
 // getPresentNeighbors returns a slice of the neigboring cells associated with the cell number provided.
 // Only neighboring cells with no common paths to others cells that are returned. i.e. Non-Visited Cells.
-func (config *Dimensions) getPresentNeighbors(cellNo int) []int {
+// visitedCells marks the cells that have been visited using the cell numbers as the indices.
+func (config *Dimensions) getPresentNeighbors(cellNo int, visitedCells []bool) []int {
 	var (
-		ok           bool
 		presentCells []int
 
 		neighbors = config.getCellNeighbors(cellNo)
 	)
 
 	for _, neighbor := range []int{neighbors.Bottom, neighbors.Left, neighbors.Right, neighbors.Top} {
-		if _, ok = visitedCells[neighbor]; !ok && neighbor != 0 {
+		if neighbor != 0 && (neighbor < 0 || neighbor >= len(visitedCells) || !visitedCells[neighbor]) {
 			presentCells = append(presentCells, neighbor)
 		}
 	}
@@ -118,9 +109,9 @@ func (config *Dimensions) getStartPosition() int {
 	)
 
 	for {
-		randCellNo = getRandomNo((config.Length * config.Width) + 1)
+		randCellNo = config.getRandomNo((config.Length * config.Width) + 1)
 
-		neighbors = config.getPresentNeighbors(randCellNo)
+		neighbors = config.getPresentNeighbors(randCellNo, nil)
 
 		if len(neighbors) < 4 && randCellNo != 0 {
 			return randCellNo
@@ -1,14 +1,22 @@
 package maze
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// update defines whether the golden files should be rewritten with the mazes generated.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
 // TestMain initializes the test environment
 func TestMain(m *testing.M) {
 	log.SetFlags(0)
@@ -18,6 +26,33 @@ func TestMain(m *testing.M) {
 
 // TestGenerateMaze tests the functionality of GenerateMaze
 func TestGenerateMaze(t *testing.T) {
+	var val = &Dimensions{
+		Length: 10,
+		Width:  10,
+	}
+
+	Convey("Given the maze dimensions", t, func() {
+		Convey("The maze should be generated with the start and the target positions", func() {
+			grid := val.generateMaze()
+
+			So(grid.Cells(), ShouldEqual, 100)
+			So(val.StartPosition, ShouldBeBetweenOrEqual, 1, 100)
+			So(val.FinalPosition, ShouldBeBetweenOrEqual, 1, 100)
+			So(val.FinalPosition, ShouldNotEqual, val.StartPosition)
+		})
+
+		Convey("A second maze generated should also have all its cells connected", func() {
+			for i := 0; i < 2; i++ {
+				grid := val.generateMaze()
+
+				So(grid.getFarthestCell(1), ShouldNotEqual, 1)
+			}
+		})
+	})
+}
+
+// TestRenderMaze tests the functionality of renderMaze
+func TestRenderMaze(t *testing.T) {
 	var (
 		compressedView []string
 
@@ -25,26 +60,23 @@ func TestGenerateMaze(t *testing.T) {
 			Length: 10,
 			Width:  10,
 		}
+		grid = val.generateMaze()
 	)
 
-	Convey("Given the correct intensity value", t, func() {
+	Convey("Given the maze grid and the intensity value", t, func() {
 		Convey("If an incorrect intensity value is used an error should be returned ", func() {
-			data, err := val.generateMaze(-1)
+			data, err := val.renderMaze(grid, -1)
 
 			So(data, ShouldBeEmpty)
-			So(val.StartPosition, ShouldBeEmpty)
-			So(val.FinalPosition, ShouldBeEmpty)
 			So(err, ShouldNotBeNil)
 			So(err.Error(), ShouldContainSubstring, "Invalid value of intensity found:")
 			So(err, ShouldImplement, (*error)(nil))
 		})
 
-		Convey("The maze should be generated without an error", func() {
-			data, err := val.generateMaze(1)
+		Convey("The maze should be rendered without an error", func() {
+			data, err := val.renderMaze(grid, 1)
 
 			So(data, ShouldNotBeEmpty)
-			So(val.StartPosition, ShouldNotBeEmpty)
-			So(val.FinalPosition, ShouldNotBeEmpty)
 			So(err, ShouldBeNil)
 
 			for _, walls := range data {
@@ -54,6 +86,49 @@ func TestGenerateMaze(t *testing.T) {
 			log.Println("Maze \n", strings.Join(compressedView, ""))
 		})
 
+		Convey("Every path in the grid should be a path in the maze rendered", func() {
+			data, err := val.renderMaze(grid, 1)
+			So(err, ShouldBeNil)
+
+			for cell := 1; cell <= grid.Cells(); cell++ {
+				addr := val.getCellAddress(cell)
+
+				So(isSpaceFound(data[addr.MiddleRight[0]][addr.MiddleRight[1]]), ShouldEqual, grid.IsPassable(cell, cell+1))
+				So(isSpaceFound(data[addr.BottomCenter[0]][addr.BottomCenter[1]]), ShouldEqual, grid.IsPassable(cell, cell+10))
+			}
+		})
+	})
+}
+
+// TestGenerateMazeGolden tests that generateMaze reproduces the same maze from the same seed.
+func TestGenerateMazeGolden(t *testing.T) {
+	Convey("TestGenerateMazeGolden: Given the maze dimensions, the generator and the seed", t, func() {
+		Convey("the maze, start and target positions generated should match the golden files", func() {
+			for _, generator := range generators {
+				val := &Dimensions{Length: 12, Width: 6, Seed: ParseSeed("tapoo"), Generator: generator}
+
+				data, err := val.renderMaze(val.generateMaze(), 1)
+				So(err, ShouldBeNil)
+
+				var compressedView []string
+				for _, walls := range data {
+					compressedView = append(compressedView, strings.Join(walls, ""))
+				}
+
+				output := fmt.Sprintf("start: %v\nfinal: %v\n%s", val.getCellAddress(val.StartPosition).MiddleCenter,
+					val.getCellAddress(val.FinalPosition).MiddleCenter, strings.Join(compressedView, ""))
+
+				golden := filepath.Join("testdata", reflect.TypeOf(generator).Name()+".golden")
+				if *update {
+					So(ioutil.WriteFile(golden, []byte(output), 0644), ShouldBeNil)
+				}
+
+				expected, err := ioutil.ReadFile(golden)
+
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, string(expected))
+			}
+		})
 	})
 }
 
@@ -116,13 +191,30 @@ func TestGetPresentNeighbors(t *testing.T) {
 	Convey("Given a cell number ", t, func() {
 		Convey("The return slice of neighbors should be same as the expected slice", func() {
 			for cell, otherCells := range testData {
-				neighbors = val.getPresentNeighbors(cell)
+				neighbors = val.getPresentNeighbors(cell, nil)
+
+				So(neighbors, ShouldHaveLength, len(otherCells))
 
 				for _, value := range neighbors {
 					So(otherCells, ShouldContain, value)
 				}
 			}
 		})
+
+		Convey("A cell that does not exist should not have all the four neighbors", func() {
+			So(len(val.getPresentNeighbors(0, nil)), ShouldBeLessThan, 4)
+		})
+
+		Convey("The visited cells should not be returned as neighbors", func() {
+			visitedCells := make([]bool, 36)
+			visitedCells[10], visitedCells[24] = true, true
+
+			neighbors = val.getPresentNeighbors(17, visitedCells)
+
+			So(neighbors, ShouldHaveLength, 2)
+			So(neighbors, ShouldContain, 16)
+			So(neighbors, ShouldContain, 18)
+		})
 	})
 }
 
@@ -136,7 +228,7 @@ func TestGetStartPosition(t *testing.T) {
 	Convey("The start position returned should have less than four neighbors ", t, func() {
 		var (
 			cellNo    = val.getStartPosition()
-			neighbors = val.getPresentNeighbors(cellNo)
+			neighbors = val.getPresentNeighbors(cellNo, nil)
 		)
 
 		So(len(neighbors), ShouldBeLessThan, 4)
@@ -0,0 +1,509 @@
+package maze
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The following message types make up the line-delimited JSON protocol spoken between
+// the hide and seek server and its players. Every message is a single JSON object on
+// its own line.
+const (
+	// msgJoin is sent by the player after connecting with the player's terminal size.
+	msgJoin = "join"
+
+	// msgMove is sent by the player with the direction the player would like to move to.
+	msgMove = "move"
+
+	// msgReady is sent by the hider to confirm the hiding spot.
+	msgReady = "ready"
+
+	// msgQuit is sent by the player leaving the game. The server forwards it to the other player.
+	msgQuit = "quit"
+
+	// msgWelcome is sent by the server with the player number once the player joins.
+	msgWelcome = "welcome"
+
+	// msgHide is sent by the server when a round starts. Only the hider receives the maze.
+	msgHide = "hide"
+
+	// msgSeek is sent by the server once the hider has hidden. Only the hider receives the hiding spot.
+	msgSeek = "seek"
+
+	// msgPosition is sent by the server every time the player navigating the maze moves.
+	msgPosition = "position"
+
+	// msgTime is sent by the server every second with the time left in the current turn.
+	msgTime = "time"
+
+	// msgResult is sent by the server at the end of every round with the scores.
+	msgResult = "result"
+
+	// msgOver is sent by the server once all the rounds have been played.
+	msgOver = "over"
+
+	// msgError is sent by the server when a message received cannot be honoured.
+	msgError = "error"
+)
+
+// roundBreak defines how long the round results are displayed before the next round starts.
+const roundBreak = 3 * time.Second
+
+// The following timeouts stop a player that does not respond from blocking the game.
+const (
+	// joinTimeout defines how long a player has to join the game after connecting.
+	joinTimeout = 10 * time.Second
+
+	// sendTimeout defines how long sending a message to a player can take.
+	sendTimeout = 5 * time.Second
+
+	// turnGrace defines how long after the turn time limit the player on turn can still
+	// be read from before the player is treated as disconnected.
+	turnGrace = 5 * time.Second
+)
+
+// Message defines a single message of the hide and seek protocol. Only the fields
+// relevant to the message type are set, thus every message after the first maze of
+// a turn carries the changes to the game state rather than the whole state.
+type Message struct {
+	Type      string   `json:"type"`
+	Player    int      `json:"player,omitempty"`
+	Direction string   `json:"direction,omitempty"`
+	Round     int      `json:"round,omitempty"`
+	Rounds    int      `json:"rounds,omitempty"`
+	Length    int      `json:"length,omitempty"`
+	Width     int      `json:"width,omitempty"`
+	Maze      []string `json:"maze,omitempty"`
+	Position  int      `json:"position,omitempty"`
+	Target    int      `json:"target,omitempty"`
+	TimeLeft  int      `json:"timeLeft,omitempty"`
+	Found     bool     `json:"found,omitempty"`
+	Scores    []int    `json:"scores,omitempty"`
+	Winner    int      `json:"winner,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// remotePlayer defines a player connected to the server.
+type remotePlayer struct {
+	conn         net.Conn
+	scanner      *bufio.Scanner
+	encoder      *json.Encoder
+	terminalSize Dimensions
+}
+
+// playerMessage defines a message received from the player provided.
+type playerMessage struct {
+	Message
+	player int
+}
+
+// Server hosts the authoritative hide and seek game played by two remote players. The
+// players only send the moves they would like to make, the server validates them against
+// the maze and sends back the resulting changes. Players are numbered from zero.
+type Server struct {
+	Rounds int
+	Seed   int64
+
+	// TimeScale shortens or lengthens the time limit of every turn, it defaults to one.
+	TimeScale float64
+
+	// JoinTimeout defines how long a player has to join the game after connecting.
+	JoinTimeout time.Duration
+
+	listener net.Listener
+	players  [2]*remotePlayer
+	hotseat  *Hotseat
+	moves    chan playerMessage
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+}
+
+// NewServer creates a hide and seek server listening on the address provided. The mazes
+// of all the rounds are generated from the seed provided.
+func NewServer(addr string, rounds int, seed int64) (*Server, error) {
+	if rounds < 1 {
+		return nil, errors.New("invalid number of rounds: at least one round should be played")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		Rounds:      rounds,
+		Seed:        seed,
+		TimeScale:   1,
+		JoinTimeout: joinTimeout,
+		listener:    listener,
+		moves:       make(chan playerMessage),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops the server and disconnects all the players.
+func (s *Server) Close() error {
+	var err error
+
+	s.once.Do(func() {
+		close(s.done)
+		err = s.listener.Close()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, p := range s.players {
+			if p != nil {
+				p.conn.Close()
+			}
+		}
+	})
+
+	return err
+}
+
+// Serve waits for the two players to join and then plays all the rounds. The server
+// is closed once the game is over or either of the players quits.
+func (s *Server) Serve() error {
+	defer s.Close()
+
+	for i := range s.players {
+		p, err := s.acceptPlayer(i)
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.players[i] = p
+		s.mu.Unlock()
+	}
+
+	terminalSize := s.players[0].terminalSize
+	if size := s.players[1].terminalSize; size.Length*size.Width < terminalSize.Length*terminalSize.Width {
+		terminalSize = size
+	}
+
+	hotseat, err := NewHotseat(s.Rounds, s.Seed, terminalSize)
+	if err != nil {
+		s.broadcast(Message{Type: msgError, Error: err.Error()})
+		return err
+	}
+
+	s.hotseat = hotseat
+
+	for i := range s.players {
+		go s.readMessages(i)
+	}
+
+	for !hotseat.IsOver() {
+		quitted, err := s.playRound()
+		if err != nil || quitted {
+			return err
+		}
+	}
+
+	s.broadcast(Message{Type: msgOver, Scores: hotseat.Scores[:], Winner: hotseat.Winner()})
+
+	return nil
+}
+
+// acceptPlayer waits for a player to connect and join the game. The connections that fail
+// to join the game are closed and the next connection is waited for.
+func (s *Server) acceptPlayer(player int) (*remotePlayer, error) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := s.join(conn, player)
+		if err == nil {
+			return p, nil
+		}
+
+		conn.Close()
+	}
+}
+
+// join reads the join message sent by the player connected and welcomes the player. The
+// player should join the game within the join timeout.
+func (s *Server) join(conn net.Conn, player int) (*remotePlayer, error) {
+	if err := conn.SetDeadline(time.Now().Add(s.JoinTimeout)); err != nil {
+		return nil, err
+	}
+
+	p := &remotePlayer{conn: conn, scanner: bufio.NewScanner(conn), encoder: json.NewEncoder(conn)}
+
+	var m Message
+	if !p.scanner.Scan() {
+		return nil, errors.New("player disconnected before joining the game")
+	}
+
+	if err := json.Unmarshal(p.scanner.Bytes(), &m); err != nil || m.Type != msgJoin || m.Length < 1 || m.Width < 1 {
+		p.encoder.Encode(Message{Type: msgError, Error: "invalid join message received"})
+		return nil, errors.New("invalid join message received")
+	}
+
+	p.terminalSize = Dimensions{Length: m.Length, Width: m.Width}
+
+	if err := p.encoder.Encode(Message{Type: msgWelcome, Player: player, Rounds: s.Rounds}); err != nil {
+		return nil, err
+	}
+
+	// the player waits for the other player to join without a deadline.
+	return p, conn.SetDeadline(time.Time{})
+}
+
+// readMessages forwards the messages received from the player to the game loop. If
+// the player disconnects, the player is treated as having quit the game.
+func (s *Server) readMessages(player int) {
+	p := s.players[player]
+
+	for p.scanner.Scan() {
+		var m Message
+		if err := json.Unmarshal(p.scanner.Bytes(), &m); err != nil {
+			m = Message{Type: msgError, Error: "invalid message received"}
+		}
+
+		select {
+		case s.moves <- playerMessage{Message: m, player: player}:
+		case <-s.done:
+			return
+		}
+	}
+
+	select {
+	case s.moves <- playerMessage{Message: Message{Type: msgQuit}, player: player}:
+	case <-s.done:
+	}
+}
+
+// send sends the message to the player provided within the send timeout. The connection
+// of a player that cannot be reached is closed so that readMessages detects the player
+// as disconnected.
+func (s *Server) send(player int, m Message) {
+	p := s.players[player]
+
+	p.conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+	if err := p.encoder.Encode(m); err != nil {
+		p.conn.Close()
+	}
+}
+
+// broadcast sends the message to both players.
+func (s *Server) broadcast(m Message) {
+	for i := range s.players {
+		s.send(i, m)
+	}
+}
+
+// playRound plays a single round of hide and seek. It returns true if either
+// of the players quit the game.
+func (s *Server) playRound() (bool, error) {
+	var (
+		h          = s.hotseat
+		g          = h.Game
+		totalCells = g.Config.Length * g.Config.Width
+		hider      = h.Hider()
+		seeker     = h.Seeker()
+	)
+
+	turn := Message{Round: h.Round + 1, Rounds: h.Rounds, Length: g.Config.Length,
+		Width: g.Config.Width, Maze: getMazeRows(g.Maze), Position: g.Position()}
+
+	turn.Type = msgHide
+	s.send(hider, turn)
+
+	// the seeker cannot see the maze as the hider is hiding.
+	s.send(seeker, Message{Type: msgHide, Round: turn.Round, Rounds: turn.Rounds})
+
+	returnedStatus, _ := s.playTurn(hider, getHidingTimeLimit(totalCells))
+	if returnedStatus == quit {
+		return true, nil
+	}
+
+	// the hider that runs out of time at the starting cell is found at once.
+	found, elapsed := true, 0
+
+	if returnedStatus == ready || h.Hide() == nil {
+		turn.Type, turn.Position = msgSeek, g.Position()
+		s.send(seeker, turn)
+
+		turn.Target = g.Config.FinalPosition
+		s.send(hider, turn)
+
+		returnedStatus, elapsed = s.playTurn(seeker, totalCells)
+		if returnedStatus == quit {
+			return true, nil
+		}
+
+		found = returnedStatus == succeeded
+	}
+	if err := h.EndRound(totalCells, elapsed, found); err != nil {
+		s.broadcast(Message{Type: msgError, Error: err.Error()})
+		return false, err
+	}
+
+	s.broadcast(Message{Type: msgResult, Round: turn.Round, Rounds: turn.Rounds, Found: found,
+		Scores: h.Scores[:]})
+
+	if !h.IsOver() {
+		return s.waitRoundBreak(), nil
+	}
+
+	return false, nil
+}
+
+// waitRoundBreak waits as the round results are displayed. The game is paused during
+// the break thus the moves received are rejected rather than played in the next round.
+// It returns true if either of the players quits the game.
+func (s *Server) waitRoundBreak() bool {
+	var (
+		g       = s.hotseat.Game
+		timeout = time.After(s.scale(roundBreak))
+	)
+
+	g.setPaused(true)
+	defer g.setPaused(false)
+
+	for {
+		select {
+		case <-timeout:
+			return false
+
+		case <-s.done:
+			return false
+
+		case m := <-s.moves:
+			switch m.Type {
+			case msgQuit:
+				s.send((m.player+1)%2, Message{Type: msgQuit, Player: m.player})
+				return true
+
+			case msgError:
+				s.send(m.player, m.Message)
+
+			default:
+				s.send(m.player, Message{Type: msgError, Error: "the game is paused until the next round starts"})
+			}
+		}
+	}
+}
+
+// playTurn runs the turn of the player provided until the time limit in seconds elapses,
+// either player quits or the turn is completed. It returns the status that ended the turn
+// and the time spent in seconds. Moves are validated using the same player movement logic
+// used when playing on a single terminal.
+func (s *Server) playTurn(player, timeLimit int) (int, int) {
+	var (
+		g           = s.hotseat.Game
+		ticker      = time.NewTicker(s.scale(time.Second))
+		timeout     = time.NewTimer(s.scale(time.Duration(timeLimit) * time.Second))
+		currentTime = time.Now()
+	)
+
+	defer ticker.Stop()
+	defer timeout.Stop()
+
+	// the player on turn is treated as disconnected if the turn is not over shortly after
+	// its time limit.
+	conn := s.players[player].conn
+	conn.SetReadDeadline(currentTime.Add(s.scale(time.Duration(timeLimit)*time.Second) + turnGrace))
+	defer conn.SetReadDeadline(time.Time{})
+
+	elapsed := func() int {
+		return int(float64(time.Since(currentTime)) / float64(s.scale(time.Second)))
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			s.broadcast(Message{Type: msgTime, TimeLeft: timeLimit - elapsed()})
+
+		case <-timeout.C:
+			return failed, timeLimit
+
+		case <-s.done:
+			return quit, elapsed()
+
+		case m := <-s.moves:
+			switch {
+			case m.Type == msgQuit:
+				s.send((m.player+1)%2, Message{Type: msgQuit, Player: m.player})
+				return quit, elapsed()
+
+			case m.Type == msgError:
+				s.send(m.player, m.Message)
+
+			case m.player != player:
+				s.send(m.player, Message{Type: msgError, Error: "it is not your turn to move"})
+
+			case m.Type == msgReady && g.getRole() == hider:
+				// the turn goes on if the hiding spot is rejected.
+				if err := s.hotseat.Hide(); err != nil {
+					s.send(player, Message{Type: msgError, Error: err.Error()})
+					continue
+				}
+
+				return ready, elapsed()
+
+			case m.Type == msgMove && isValidDirection(m.Direction):
+				position := g.Position()
+				g.Move(m.Direction)
+
+				if newPosition := g.Position(); newPosition != position {
+					// the hider watches the seeker but the seeker never sees the hider move.
+					if g.getRole() == seeker {
+						s.broadcast(Message{Type: msgPosition, Position: newPosition})
+					} else {
+						s.send(player, Message{Type: msgPosition, Position: newPosition})
+					}
+				}
+
+				if g.getRole() == seeker && g.IsTargetFound() {
+					return succeeded, elapsed()
+				}
+
+			default:
+				s.send(m.player, Message{Type: msgError, Error: "invalid message received"})
+			}
+		}
+	}
+}
+
+// scale returns the duration provided scaled by the server time scale.
+func (s *Server) scale(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * s.TimeScale)
+}
+
+// isValidDirection checks if the direction provided is supported by the player movement.
+func isValidDirection(direction string) bool {
+	switch direction {
+	case "LEFT", "RIGHT", "UP", "DOWN":
+		return true
+	}
+
+	return false
+}
+
+// getMazeRows joins the maze characters into the rows printed on the terminal.
+func getMazeRows(data [][]string) []string {
+	rows := make([]string, 0, len(data))
+
+	for _, d := range data {
+		rows = append(rows, strings.TrimSuffix(strings.Join(d, ""), "\n"))
+	}
+
+	return rows
+}
@@ -0,0 +1,290 @@
+package maze
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/solver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testPlayer defines a player connected to the test server.
+type testPlayer struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	encoder *json.Encoder
+}
+
+// joinTestServer connects a player with the terminal size provided to the server.
+func joinTestServer(addr string, terminalSize Dimensions) (*testPlayer, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &testPlayer{conn: conn, scanner: bufio.NewScanner(conn), encoder: json.NewEncoder(conn)}
+	p.scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	return p, p.encoder.Encode(Message{Type: msgJoin, Length: terminalSize.Length, Width: terminalSize.Width})
+}
+
+// receive returns the next message that is not a time update.
+func (p *testPlayer) receive() Message {
+	for p.scanner.Scan() {
+		var m Message
+		if err := json.Unmarshal(p.scanner.Bytes(), &m); err == nil && m.Type != msgTime {
+			return m
+		}
+	}
+
+	return Message{}
+}
+
+// walk moves the player along the path provided and returns the last message received.
+func (p *testPlayer) walk(length int, path []int) Message {
+	var m Message
+
+	for i := 1; i < len(path); i++ {
+		direction := map[int]string{-1: "LEFT", 1: "RIGHT", -length: "UP", length: "DOWN"}[path[i]-path[i-1]]
+
+		p.encoder.Encode(Message{Type: msgMove, Direction: direction})
+		m = p.receive()
+	}
+
+	return m
+}
+
+// TestServer tests the functionality of Server
+func TestServer(t *testing.T) {
+	Convey("TestServer: Given a hide and seek server of a single round and two players", t, func() {
+		terminalSize := Dimensions{Length: 40, Width: 20}
+
+		server, err := NewServer("127.0.0.1:0", 1, 2018)
+		So(err, ShouldBeNil)
+
+		served := make(chan error, 1)
+		go func() { served <- server.Serve() }()
+
+		// expected holds the same game as the one hosted by the server since the
+		// mazes are generated from the same seed.
+		expected, err := NewHotseat(1, 2018, terminalSize)
+		So(err, ShouldBeNil)
+
+		var players [2]*testPlayer
+		for i := range players {
+			players[i], err = joinTestServer(server.Addr().String(), terminalSize)
+			So(err, ShouldBeNil)
+
+			m := players[i].receive()
+			So(m.Type, ShouldEqual, msgWelcome)
+			So(m.Player, ShouldEqual, i)
+		}
+
+		hiderMsg, seekerMsg := players[0].receive(), players[1].receive()
+
+		Convey("only the hider should receive the maze as the hider is hiding", func() {
+			So(hiderMsg.Type, ShouldEqual, msgHide)
+			So(hiderMsg.Maze, ShouldResemble, getMazeRows(expected.Game.Maze))
+			So(hiderMsg.Position, ShouldEqual, expected.Game.Position())
+
+			So(seekerMsg.Type, ShouldEqual, msgHide)
+			So(seekerMsg.Maze, ShouldBeEmpty)
+
+			Convey("and moves by the wrong player or in invalid directions should be rejected", func() {
+				players[1].encoder.Encode(Message{Type: msgMove, Direction: "LEFT"})
+				So(players[1].receive().Error, ShouldContainSubstring, "not your turn")
+
+				players[0].encoder.Encode(Message{Type: msgMove, Direction: "NORTH"})
+				So(players[0].receive().Error, ShouldContainSubstring, "invalid message")
+
+				// the hider cannot hide at the starting cell.
+				players[0].encoder.Encode(Message{Type: msgReady})
+				So(players[0].receive().Error, ShouldContainSubstring, "cannot be the starting cell")
+
+				// a move into a wall is validated by the player movement and ignored.
+				g := expected.Game
+				for _, direction := range []string{"LEFT", "RIGHT", "UP", "DOWN"} {
+					neighbors := g.Config.getCellNeighbors(g.Position())
+					next := map[string]int{"LEFT": neighbors.Left, "RIGHT": neighbors.Right,
+						"UP": neighbors.Top, "DOWN": neighbors.Bottom}[direction]
+
+					if !g.Grid.IsPassable(g.Position(), next) {
+						players[0].encoder.Encode(Message{Type: msgMove, Direction: direction})
+						break
+					}
+				}
+
+				Convey("and the seeker should locate the hider along the moves validated by the server", func() {
+					hidingPath, err := solver.BFS(g.Grid, g.Position(), g.Config.FinalPosition)
+					So(err, ShouldBeNil)
+
+					spot := hidingPath.Path[len(hidingPath.Path)/2]
+					m := players[0].walk(g.Config.Length, hidingPath.Path[:len(hidingPath.Path)/2+1])
+
+					So(m.Type, ShouldEqual, msgPosition)
+					So(m.Position, ShouldEqual, spot)
+
+					players[0].encoder.Encode(Message{Type: msgReady})
+
+					hiderMsg, seekerMsg = players[0].receive(), players[1].receive()
+
+					So(hiderMsg.Type, ShouldEqual, msgSeek)
+					So(hiderMsg.Target, ShouldEqual, spot)
+					So(seekerMsg.Type, ShouldEqual, msgSeek)
+					So(seekerMsg.Target, ShouldEqual, 0)
+					So(seekerMsg.Position, ShouldEqual, g.Position())
+
+					seekingPath, err := solver.BFS(g.Grid, g.Position(), spot)
+					So(err, ShouldBeNil)
+
+					players[1].walk(g.Config.Length, seekingPath.Path)
+
+					// the hider watches every move made by the seeker.
+					for range seekingPath.Path[1:] {
+						So(players[0].receive().Type, ShouldEqual, msgPosition)
+					}
+
+					for _, p := range players {
+						m = p.receive()
+						So(m.Type, ShouldEqual, msgResult)
+						So(m.Found, ShouldBeTrue)
+						So(m.Scores[0]+m.Scores[1], ShouldEqual, g.Grid.Cells()*100)
+
+						m = p.receive()
+						So(m.Type, ShouldEqual, msgOver)
+					}
+
+					So(<-served, ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("if a player disconnects the other player should be notified and the game stopped", func() {
+			players[0].conn.Close()
+
+			m := players[1].receive()
+			So(m.Type, ShouldEqual, msgQuit)
+			So(m.Player, ShouldEqual, 0)
+
+			So(<-served, ShouldBeNil)
+		})
+
+		Reset(func() {
+			for _, p := range players {
+				if p != nil {
+					p.conn.Close()
+				}
+			}
+
+			server.Close()
+		})
+	})
+}
+
+// TestServerJoin tests the functionality of Server.acceptPlayer
+func TestServerJoin(t *testing.T) {
+	Convey("TestServerJoin: Given a hide and seek server waiting for the players to join", t, func() {
+		terminalSize := Dimensions{Length: 40, Width: 20}
+
+		server, err := NewServer("127.0.0.1:0", 1, 2018)
+		So(err, ShouldBeNil)
+
+		server.JoinTimeout = 100 * time.Millisecond
+
+		served := make(chan error, 1)
+		go func() { served <- server.Serve() }()
+
+		Convey("the connections that fail to join should be closed without stopping the server", func() {
+			stray, err := joinTestServer(server.Addr().String(), Dimensions{})
+			So(err, ShouldBeNil)
+
+			defer stray.conn.Close()
+
+			So(stray.receive().Error, ShouldContainSubstring, "invalid join message")
+			So(stray.scanner.Scan(), ShouldBeFalse)
+
+			idle, err := net.Dial("tcp", server.Addr().String())
+			So(err, ShouldBeNil)
+
+			defer idle.Close()
+
+			_, err = bufio.NewReader(idle).ReadByte()
+			So(err, ShouldEqual, io.EOF)
+
+			var players [2]*testPlayer
+			for i := range players {
+				players[i], err = joinTestServer(server.Addr().String(), terminalSize)
+				So(err, ShouldBeNil)
+
+				defer players[i].conn.Close()
+
+				m := players[i].receive()
+				So(m.Type, ShouldEqual, msgWelcome)
+				So(m.Player, ShouldEqual, i)
+			}
+
+			So(players[0].receive().Type, ShouldEqual, msgHide)
+		})
+
+		Reset(func() {
+			server.Close()
+			<-served
+		})
+	})
+}
+
+// TestRemoteView tests the functionality of remoteView.apply
+func TestRemoteView(t *testing.T) {
+	Convey("TestRemoteView: Given the messages received from the server", t, func() {
+		view := &remoteView{}
+		view.apply(Message{Type: msgWelcome, Player: 1, Rounds: 2})
+
+		Convey("the player should be the hider only when the maze is received while hiding", func() {
+			view.apply(Message{Type: msgHide, Round: 1, Rounds: 2})
+
+			So(view.role, ShouldEqual, seeker)
+			So(view.maze, ShouldBeNil)
+
+			view.apply(Message{Type: msgSeek, Maze: []string{"|---|"}, Length: 1, Width: 1, Position: 1})
+
+			So(view.role, ShouldEqual, seeker)
+			So(view.target, ShouldEqual, 0)
+
+			view.apply(Message{Type: msgHide, Maze: []string{"|---|"}, Length: 1, Width: 1, Position: 1})
+
+			So(view.role, ShouldEqual, hider)
+
+			view.apply(Message{Type: msgSeek, Maze: []string{"|---|"}, Length: 1, Width: 1, Target: 1})
+
+			So(view.role, ShouldEqual, hider)
+			So(view.target, ShouldEqual, 1)
+		})
+
+		Convey("the position changes should be applied to the current view", func() {
+			view.apply(Message{Type: msgHide, Maze: []string{"|---|"}, Length: 1, Width: 1, Position: 1})
+			view.apply(Message{Type: msgPosition, Position: 5})
+
+			So(view.position, ShouldEqual, 5)
+			So(view.maze, ShouldNotBeNil)
+
+			view.apply(Message{Type: msgOver, Scores: []int{100, 200}, Winner: 1})
+
+			So(view.over, ShouldBeTrue)
+			So(view.maze, ShouldBeNil)
+			So(view.getStatusMsg(false), ShouldContainSubstring, "Player 2 wins")
+		})
+
+		Convey("a round result without the scores of both players should be reported as an error", func() {
+			view.apply(Message{Type: msgHide, Maze: []string{"|---|"}, Length: 1, Width: 1, Position: 1})
+			view.apply(Message{Type: msgResult, Scores: []int{100}})
+
+			So(view.err, ShouldContainSubstring, "invalid round result received")
+			So(view.maze, ShouldNotBeNil)
+			So(view.scores, ShouldBeNil)
+		})
+	})
+}
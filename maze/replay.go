@@ -0,0 +1,517 @@
+package maze
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// replayVersion defines the version of the replay file format written.
+const replayVersion = 1
+
+// The following event kinds make up the line-delimited JSON replay file. The first line of
+// the file is the replay header and every event after it is a JSON object on its own line.
+const (
+	// replayLevel is recorded every time a maze is loaded with the level, the seed and the
+	// terminal size the maze was generated for.
+	replayLevel = "level"
+
+	// replayMove is recorded every time the player moves with the direction moved to.
+	replayMove = "move"
+
+	// replayHint is recorded every time a hint is shown.
+	replayHint = "hint"
+)
+
+// replayStatuses maps the game statuses recorded to their replay event kinds.
+var replayStatuses = map[int]string{
+	succeeded: "succeeded",
+	proceed:   "proceed",
+	failed:    "failed",
+	pause:     "pause",
+	quit:      "quit",
+}
+
+// replayTick defines how often the replay is redrawn during the playback.
+const replayTick = 50 * time.Millisecond
+
+// seekStep defines how far the playback moves back or forth every time the replay is seeked.
+const seekStep = 5 * time.Second
+
+const (
+	replayMsg        = "  Replay: %-6s  %s / %s    Game: %-9s  Level: %d    Scores: %d   "
+	replayNavigation = "  Space: Pause/Play   Arrow Keys: Seek   1, 2, 4: Speed   ESC: Quit  "
+)
+
+// replayHeader defines the first line of the replay file. Seed holds the seed that
+// generated the mazes of all the levels recorded.
+type replayHeader struct {
+	Version   int       `json:"version"`
+	Seed      int64     `json:"seed"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// replayEvent defines a single event of a recorded game. Time holds the milliseconds elapsed
+// since the recording started. Only the fields relevant to the event kind are set. The
+// mazes whose seed is not recorded are generated from the seed of the replay header.
+type replayEvent struct {
+	Time      int64  `json:"t"`
+	Kind      string `json:"k"`
+	Direction string `json:"d,omitempty"`
+	Level     int    `json:"l,omitempty"`
+	Seed      int64  `json:"s,omitempty"`
+	Length    int    `json:"x,omitempty"`
+	Width     int    `json:"y,omitempty"`
+}
+
+// replay defines a recorded game read from a replay file.
+type replay struct {
+	seed   int64
+	events []replayEvent
+}
+
+// recorder writes the events of a game to a replay file as they happen so that the
+// game recorded so far can be played back even if the game is not quit gracefully.
+type recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	started time.Time
+	err     error
+}
+
+// newRecorder writes the header of the replay of a game whose mazes are generated from
+// the seed provided.
+func newRecorder(w io.Writer, seed int64) (*recorder, error) {
+	r := &recorder{encoder: json.NewEncoder(w), started: time.Now()}
+
+	header := replayHeader{Version: replayVersion, Seed: seed, CreatedAt: r.started.UTC()}
+	if err := r.encoder.Encode(header); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// record writes the event provided timestamped with the time elapsed since the recording
+// started. Nothing is recorded if the game is not being recorded or after writing fails,
+// since the game should go on regardless.
+func (r *recorder) record(e replayEvent) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		e.Time = time.Since(r.started).Milliseconds()
+		r.err = r.encoder.Encode(e)
+	}
+}
+
+// startRecording records the game to the replay file provided starting with the events of
+// the current level logged so far, so that a resumed level is played back from its start.
+func (g *Game) startRecording(w io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, err := newRecorder(w, g.Config.Seed)
+	if err != nil {
+		return err
+	}
+
+	g.recorder = r
+
+	for _, e := range g.levelLog {
+		g.recorder.record(e)
+	}
+
+	return nil
+}
+
+// record logs the event provided as a part of the current level and writes it to the
+// replay file if the game is being recorded. It should be called with the game locked.
+func (g *Game) record(e replayEvent) {
+	e.Time = time.Since(g.startedAt).Milliseconds()
+	g.levelLog = append(g.levelLog, e)
+
+	g.recorder.record(e)
+}
+
+// recordStatus records the game status provided.
+func (g *Game) recordStatus(status int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.record(replayEvent{Kind: replayStatuses[status]})
+}
+
+// getReplay returns how the current level has been played so far. It should be called
+// with the game locked.
+func (g *Game) getReplay() *db.Replay {
+	r := &db.Replay{Events: make([]db.ReplayEvent, 0, len(g.levelLog))}
+
+	for _, e := range g.levelLog {
+		if e.Kind == replayLevel {
+			r.Length, r.Width = e.Length, e.Width
+			continue
+		}
+
+		r.Events = append(r.Events, db.ReplayEvent{Time: e.Time, Kind: e.Kind, Direction: e.Direction})
+	}
+
+	return r
+}
+
+// readReplay reads the replay file provided. The replay should start with a maze being loaded.
+func readReplay(r io.Reader) (*replay, error) {
+	var (
+		header  replayHeader
+		scanner = bufio.NewScanner(r)
+	)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New("the replay header was not found")
+	}
+
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("invalid replay header found: %v", err)
+	}
+
+	if header.Version != replayVersion {
+		return nil, fmt.Errorf("unsupported replay version found: %d", header.Version)
+	}
+
+	rp := &replay{seed: header.Seed}
+
+	for line := 2; scanner.Scan(); line++ {
+		var e replayEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid replay event found on line %d: %v", line, err)
+		}
+
+		rp.events = append(rp.events, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rp.events) == 0 || rp.events[0].Kind != replayLevel {
+		return nil, errors.New("the replay has no maze recorded")
+	}
+
+	return rp, nil
+}
+
+// getTime returns the time the event was recorded at.
+func (e replayEvent) getTime() time.Duration {
+	return time.Duration(e.Time) * time.Millisecond
+}
+
+// getReplayStatus returns the game status recorded by the event kind provided. False is
+// returned if the event kind is not a game status.
+func getReplayStatus(kind string) (int, bool) {
+	for status, val := range replayStatuses {
+		if val == kind {
+			return status, true
+		}
+	}
+
+	return 0, false
+}
+
+// replayPlayer plays back a recorded game. Position holds the playback position, next
+// holds the index of the next event to be applied while speed holds how many times
+// faster than the recorded game the replay is played. Status holds the last game status
+// recorded. The clock of the game played back reads the playback position as its time.
+type replayPlayer struct {
+	replay   *replay
+	game     *Game
+	next     int
+	position time.Duration
+	speed    int
+	paused   bool
+	status   int
+}
+
+// newReplayPlayer returns a player of the replay provided positioned at its start.
+func newReplayPlayer(r *replay) (*replayPlayer, error) {
+	p := &replayPlayer{replay: r, speed: 1}
+
+	if err := p.seek(0); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// end returns the time the last event of the replay was recorded at.
+func (p *replayPlayer) end() time.Duration {
+	return p.replay.events[len(p.replay.events)-1].getTime()
+}
+
+// seek moves the playback to the position provided. Seeking back replays the game from
+// its start since the moves cannot be undone.
+func (p *replayPlayer) seek(position time.Duration) error {
+	switch {
+	case position < 0:
+		position = 0
+
+	case position > p.end():
+		position = p.end()
+	}
+
+	if position < p.position || p.game == nil {
+		p.game, p.next = nil, 0
+	}
+
+	for ; p.next < len(p.replay.events); p.next++ {
+		e := p.replay.events[p.next]
+		if e.getTime() > position {
+			break
+		}
+
+		// the game clock reads the time the event was recorded at while it is applied.
+		p.position = e.getTime()
+
+		if err := p.apply(e); err != nil {
+			return err
+		}
+	}
+
+	p.position = position
+	p.game.setScores(p.game.clock.getScores())
+
+	return nil
+}
+
+// getSpent returns the time the current level was played until the playback position.
+func (p *replayPlayer) getSpent() time.Duration {
+	return p.game.clock.getPlayed()
+}
+
+// now returns the playback position as the time read by the clock of the game played back.
+func (p *replayPlayer) now() time.Time {
+	return time.Time{}.Add(p.position)
+}
+
+// apply updates the game with the event provided.
+func (p *replayPlayer) apply(e replayEvent) error {
+	if e.Kind == replayLevel {
+		terminalSize := Dimensions{Length: e.Length, Width: e.Width}
+
+		seed := p.replay.seed
+		if e.Seed != 0 {
+			seed = e.Seed
+		}
+
+		if p.game == nil {
+			g, err := NewGame(e.Level, seed, terminalSize)
+			if err != nil {
+				return err
+			}
+
+			p.game = g
+			p.game.clock.now = p.now
+		} else if err := p.game.loadMaze(e.Level, seed, terminalSize); err != nil {
+			return err
+		}
+
+		p.status = proceed
+		p.game.clock.resume()
+
+		return nil
+	}
+
+	switch e.Kind {
+	case replayMove:
+		p.game.Move(e.Direction)
+		return nil
+
+	case replayHint:
+		p.game.requestHint()
+		return nil
+	}
+
+	status, ok := getReplayStatus(e.Kind)
+	switch {
+	case !ok:
+		return fmt.Errorf("invalid replay event found: %q", e.Kind)
+
+	case status == proceed:
+		p.game.clock.resume()
+
+	default:
+		p.game.clock.pause()
+	}
+
+	p.status = status
+
+	return nil
+}
+
+// handleKey updates the playback for the key event provided. False is returned if the
+// viewer would like to stop the playback.
+func (p *replayPlayer) handleKey(event termbox.Event) (bool, error) {
+	switch event.Ch {
+	case '1', '2', '4':
+		p.speed = int(event.Ch - '0')
+		return true, nil
+	}
+
+	switch event.Key {
+	case termbox.KeyEsc, termbox.KeyCtrlC:
+		return false, nil
+
+	case termbox.KeySpace:
+		// playing a replay that is over starts it afresh.
+		if p.paused && p.position == p.end() {
+			return true, p.seek(0)
+		}
+
+		p.paused = !p.paused
+
+	case termbox.KeyArrowLeft, termbox.KeyArrowDown:
+		return true, p.seek(p.position - seekStep)
+
+	case termbox.KeyArrowRight, termbox.KeyArrowUp:
+		return true, p.seek(p.position + seekStep)
+	}
+
+	return true, nil
+}
+
+// advance moves the playback forward by the time provided at the playback speed.
+// The playback is paused once the end of the replay is reached.
+func (p *replayPlayer) advance(d time.Duration) error {
+	if p.paused {
+		return nil
+	}
+
+	if err := p.seek(p.position + d*time.Duration(p.speed)); err != nil {
+		return err
+	}
+
+	p.paused = p.position == p.end()
+
+	return nil
+}
+
+// getStatusMsg returns the message displayed under the maze.
+func (p *replayPlayer) getStatusMsg() string {
+	playback := fmt.Sprintf("%dx", p.speed)
+	if p.paused {
+		playback = "paused"
+	}
+
+	state := map[int]string{succeeded: "won", proceed: "playing", failed: "failed",
+		pause: "paused", quit: "quit"}[p.status]
+
+	return fmt.Sprintf(replayMsg, playback, formatClock(p.position), formatClock(p.end()),
+		state, p.game.Level, p.game.Scores())
+}
+
+// draw displays the game at the current playback position.
+func (p *replayPlayer) draw() {
+	g, msg := p.game, p.getStatusMsg()
+
+	g.mu.Lock()
+	g.message = msg
+	g.mu.Unlock()
+
+	g.refreshUI()
+
+	fill(len(g.Maze[1])/3, len(g.Maze)+10, replayNavigation, coldef)
+	termbox.Flush()
+}
+
+// formatClock returns the duration provided as minutes and seconds.
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", d/time.Minute, (d%time.Minute)/time.Second)
+}
+
+// PlayReplay plays back the game recorded to the replay file provided on the terminal.
+// The playback can be paused, seeked and sped up to two or four times the recorded speed.
+// The error that ended the playback is returned once the terminal is restored.
+func PlayReplay(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+
+	r, err := readReplay(f)
+	f.Close()
+
+	if err != nil {
+		return err
+	}
+
+	player, err := newReplayPlayer(r)
+	if err != nil {
+		return err
+	}
+
+	if err = termbox.Init(); err != nil {
+		return err
+	}
+
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	var (
+		timer  = time.NewTicker(replayTick)
+		events = make(chan termbox.Event)
+	)
+
+	defer timer.Stop()
+
+	go func() {
+		for {
+			events <- termbox.PollEvent()
+		}
+	}()
+
+	player.draw()
+
+	for {
+		select {
+		case <-timer.C:
+			if err = player.advance(replayTick); err != nil {
+				return err
+			}
+
+		case ev := <-events:
+			if ev.Type == termbox.EventError {
+				panic(ev.Err)
+			}
+
+			if ev.Type != termbox.EventKey {
+				continue
+			}
+
+			ok, err := player.handleKey(ev)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+		}
+
+		player.draw()
+	}
+}
@@ -0,0 +1,228 @@
+package maze
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/solver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// getDirections returns the directions the player moves to while walking along the path provided.
+func getDirections(config *Dimensions, path []int) []string {
+	var directions []string
+
+	for i := 1; i < len(path); i++ {
+		neighbors := config.getCellNeighbors(path[i-1])
+		directions = append(directions, map[int]string{neighbors.Left: "LEFT", neighbors.Right: "RIGHT",
+			neighbors.Top: "UP", neighbors.Bottom: "DOWN"}[path[i]])
+	}
+
+	return directions
+}
+
+// TestRecorder tests the functionality of the recorder
+func TestRecorder(t *testing.T) {
+	Convey("TestRecorder: Given a game being recorded", t, func() {
+		var (
+			buf          bytes.Buffer
+			terminalSize = Dimensions{Length: 40, Width: 20}
+		)
+
+		game, err := NewGame(1, 2018, terminalSize)
+		So(err, ShouldBeNil)
+		So(game.startRecording(&buf), ShouldBeNil)
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		directions := getDirections(game.Config, solution.Path)
+		for _, direction := range directions[:2] {
+			game.Move(direction)
+		}
+
+		So(game.ShowHint(), ShouldBeNil)
+		So(game.loadMaze(2, 2018, terminalSize), ShouldBeNil)
+
+		Convey("the replay read should hold the seed, the levels and the moves in order", func() {
+			r, err := readReplay(&buf)
+
+			So(err, ShouldBeNil)
+			So(r.seed, ShouldEqual, 2018)
+			So(r.events, ShouldHaveLength, 5)
+			So(r.events[0], ShouldResemble, replayEvent{Time: r.events[0].Time, Kind: replayLevel,
+				Level: 1, Seed: 2018, Length: 40, Width: 20})
+			So(r.events[1].Direction, ShouldEqual, directions[0])
+			So(r.events[2].Direction, ShouldEqual, directions[1])
+			So(r.events[3].Kind, ShouldEqual, replayHint)
+			So(r.events[4].Level, ShouldEqual, 2)
+
+			for i := 1; i < len(r.events); i++ {
+				So(r.events[i].Time, ShouldBeGreaterThanOrEqualTo, r.events[i-1].Time)
+			}
+		})
+
+		Convey("moves blocked by the walls should not be recorded", func() {
+			neighbors := game.Config.getCellNeighbors(game.Config.StartPosition)
+
+			for direction, cell := range map[string]int{"LEFT": neighbors.Left, "RIGHT": neighbors.Right,
+				"UP": neighbors.Top, "DOWN": neighbors.Bottom} {
+				if !game.Grid.IsPassable(game.Config.StartPosition, cell) {
+					game.Move(direction)
+				}
+			}
+
+			r, err := readReplay(&buf)
+
+			So(err, ShouldBeNil)
+			So(r.events, ShouldHaveLength, 5)
+		})
+	})
+}
+
+// TestReadReplay tests the functionality of readReplay
+func TestReadReplay(t *testing.T) {
+	Convey("TestReadReplay: Given an invalid replay file, an error should be returned", t, func() {
+		for _, item := range []struct {
+			data string
+			msg  string
+		}{
+			{"", "the replay header was not found"},
+			{"tapoo", "invalid replay header found"},
+			{`{"version":2,"seed":1}`, "unsupported replay version found: 2"},
+			{`{"version":1,"seed":1}`, "the replay has no maze recorded"},
+			{`{"version":1,"seed":1}` + "\n" + `{"t":5,"k":"move","d":"LEFT"}`, "the replay has no maze recorded"},
+			{`{"version":1,"seed":1}` + "\n" + `{"t":0,"k":"level","l":1}` + "\n" + `{"t":`, "invalid replay event found on line 3"},
+		} {
+			r, err := readReplay(strings.NewReader(item.data))
+
+			So(r, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, item.msg)
+		}
+	})
+}
+
+// TestReplayPlayer tests the functionality of the replayPlayer
+func TestReplayPlayer(t *testing.T) {
+	Convey("TestReplayPlayer: Given a replay of a level won after a pause", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		// the player moves every second and pauses for ten seconds after the first move.
+		var (
+			path   = solution.Path
+			moves  = getDirections(game.Config, path)
+			events = []replayEvent{{Time: 0, Kind: replayLevel, Level: 1, Length: 40, Width: 20}}
+			at     = int64(0)
+		)
+
+		for i, direction := range moves {
+			at += 1000
+			events = append(events, replayEvent{Time: at, Kind: replayMove, Direction: direction})
+
+			if i == 0 {
+				events = append(events, replayEvent{Time: at, Kind: replayStatuses[pause]},
+					replayEvent{Time: at + 10000, Kind: replayStatuses[proceed]})
+				at += 10000
+			}
+		}
+
+		events = append(events, replayEvent{Time: at, Kind: replayStatuses[succeeded]},
+			replayEvent{Time: at + 2000, Kind: replayStatuses[quit]})
+
+		player, err := newReplayPlayer(&replay{seed: 2018, events: events})
+		So(err, ShouldBeNil)
+
+		timeLimit := game.getTimeLimit()
+
+		Convey("the game should start at the recorded maze", func() {
+			So(player.game.Maze, ShouldResemble, game.Maze)
+			So(player.game.Position(), ShouldEqual, path[0])
+			So(player.end(), ShouldEqual, time.Duration(at+2000)*time.Millisecond)
+			So(player.game.Scores(), ShouldEqual, int(timeLimit/time.Second)*100)
+		})
+
+		Convey("seeking forth should replay the moves and exclude the pause from the time played", func() {
+			So(player.seek(13500*time.Millisecond), ShouldBeNil)
+
+			So(player.game.Position(), ShouldEqual, path[3])
+			So(player.status, ShouldEqual, proceed)
+			So(player.game.Scores(), ShouldEqual, int((timeLimit-3500*time.Millisecond)/time.Second)*100)
+
+			Convey("and seeking back should replay the game from its start", func() {
+				So(player.seek(5*time.Second), ShouldBeNil)
+
+				So(player.game.Position(), ShouldEqual, path[1])
+				So(player.status, ShouldEqual, pause)
+				So(player.game.Scores(), ShouldEqual, int((timeLimit-time.Second)/time.Second)*100)
+			})
+		})
+
+		Convey("seeking past the end should stop at the target found", func() {
+			So(player.seek(player.end()+time.Hour), ShouldBeNil)
+
+			played := time.Duration(len(moves)) * time.Second
+
+			So(player.position, ShouldEqual, player.end())
+			So(player.game.IsTargetFound(), ShouldBeTrue)
+			So(player.status, ShouldEqual, quit)
+			So(player.game.Scores(), ShouldEqual, int((timeLimit-played)/time.Second)*100)
+		})
+
+		Convey("advancing the playback should move at the speed set and pause at the end", func() {
+			player.speed = 4
+
+			So(player.advance(time.Second), ShouldBeNil)
+			So(player.position, ShouldEqual, 4*time.Second)
+
+			player.paused = true
+
+			So(player.advance(time.Second), ShouldBeNil)
+			So(player.position, ShouldEqual, 4*time.Second)
+
+			player.paused = false
+
+			So(player.advance(time.Hour), ShouldBeNil)
+			So(player.position, ShouldEqual, player.end())
+			So(player.paused, ShouldBeTrue)
+		})
+
+		Convey("a maze recorded with its own seed should be generated from it", func() {
+			player.replay.events = append(events, replayEvent{Time: at + 3000, Kind: replayLevel, Level: 1,
+				Seed: 2019, Length: 40, Width: 20})
+
+			So(player.seek(player.end()), ShouldBeNil)
+
+			other, err := NewGame(1, 2019, Dimensions{Length: 40, Width: 20})
+			So(err, ShouldBeNil)
+
+			So(player.game.Config.Seed, ShouldEqual, 2019)
+			So(player.game.Maze, ShouldResemble, other.Maze)
+		})
+
+		Convey("an unknown event, an error should be returned", func() {
+			player.replay.events = append(events, replayEvent{Time: at + 3000, Kind: "jump"})
+
+			err := player.seek(time.Duration(at+3000) * time.Millisecond)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `invalid replay event found: "jump"`)
+		})
+	})
+}
+
+// TestFormatClock tests the functionality of formatClock
+func TestFormatClock(t *testing.T) {
+	Convey("TestFormatClock: Given a duration, it should be formatted as minutes and seconds", t, func() {
+		for d, output := range map[time.Duration]string{0: "00:00", 1500 * time.Millisecond: "00:01",
+			125 * time.Second: "02:05", 61 * time.Minute: "61:00"} {
+			So(formatClock(d), ShouldEqual, output)
+		}
+	})
+}
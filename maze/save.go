@@ -0,0 +1,211 @@
+package maze
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/solver"
+)
+
+// saveVersion defines the version of the save game file format written.
+const saveVersion = 1
+
+// defaultSavePath defines the file holding the game saved when the player quits a paused
+// game if TAPOO_SAVE_PATH is not set.
+const defaultSavePath = "tapoo_save.json"
+
+const resumePrompt = "A saved game of level %d was found, resume it? [Y/n]: "
+
+// savedGame defines the level being played when the player quit a paused game. Length,
+// Width and Cells hold the maze grid while Start holds the cell the level started from.
+// Remaining holds the time left to locate the target while Log holds the events of the
+// level so far so that the level attempt can still be verified once it is over. Seed holds
+// the seed the maze was generated from while PlayerSeed holds the seed the next levels are
+// generated from, they only differ if the level was raced against a ghost.
+type savedGame struct {
+	Version    int           `json:"version"`
+	SavedAt    time.Time     `json:"saved_at"`
+	TapooID    string        `json:"user_id,omitempty"`
+	Level      int           `json:"level"`
+	Seed       int64         `json:"seed"`
+	PlayerSeed int64         `json:"player_seed,omitempty"`
+	Length     int           `json:"length"`
+	Width      int           `json:"width"`
+	Cells      []uint8       `json:"cells"`
+	Start      int           `json:"start"`
+	Position   int           `json:"position"`
+	Target     int           `json:"target"`
+	Remaining  time.Duration `json:"remaining"`
+	Scores     int           `json:"scores"`
+	HintsUsed  int           `json:"hints_used"`
+	Moves      int           `json:"moves"`
+	Log        []replayEvent `json:"log"`
+}
+
+// getSavePath returns the save game file set by TAPOO_SAVE_PATH or the default one.
+func getSavePath() string {
+	if path := os.Getenv("TAPOO_SAVE_PATH"); len(path) > 0 {
+		return path
+	}
+
+	return defaultSavePath
+}
+
+// saveGame writes the current level to the save game file provided with the time left on
+// the game clock. The file is replaced if it exists.
+func (g *Game) saveGame(path string) error {
+	remaining := g.clock.getLimit() - g.clock.getPlayed()
+
+	g.mu.Lock()
+	s := &savedGame{Version: saveVersion, SavedAt: time.Now().UTC(), Level: g.Level, Seed: g.Config.Seed,
+		PlayerSeed: g.seed, Length: g.Grid.Length, Width: g.Grid.Width, Cells: append([]uint8{}, g.Grid.cells...),
+		Start: g.start, Position: g.Config.StartPosition, Target: g.Config.FinalPosition, Remaining: remaining,
+		Scores: g.scores, HintsUsed: g.hintsUsed, Moves: g.moves, Log: append([]replayEvent{}, g.levelLog...)}
+
+	if g.user != nil {
+		s.TapooID = g.user.TapooID
+	}
+	g.mu.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// the game is written to a temporary file first so that a crash cannot leave a
+	// partially written save game file.
+	tmpPath := path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// readSaveGame reads the save game file provided.
+func readSaveGame(path string) (*savedGame, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("no saved game was found")
+		}
+
+		return nil, err
+	}
+
+	var s savedGame
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid save game found: %v", err)
+	}
+
+	if s.Version != saveVersion {
+		return nil, fmt.Errorf("unsupported save game version found: %d", s.Version)
+	}
+
+	if err = s.check(); err != nil {
+		return nil, fmt.Errorf("invalid save game found: %v", err)
+	}
+
+	return &s, nil
+}
+
+// check checks if the saved level can be resumed.
+func (s *savedGame) check() error {
+	cells := s.Length * s.Width
+
+	switch {
+	case s.Level < 0 || s.Level > maxLevel:
+		return fmt.Errorf("invalid level %d", s.Level)
+
+	case s.Length < 1 || s.Width < 1 || len(s.Cells) != cells:
+		return fmt.Errorf("invalid maze of %d cells", len(s.Cells))
+
+	case s.Start < 1 || s.Start > cells || s.Position < 1 || s.Position > cells || s.Target < 1 || s.Target > cells:
+		return errors.New("invalid positions")
+
+	case s.Remaining <= 0 || s.Remaining > time.Duration(cells)*time.Second:
+		return fmt.Errorf("invalid remaining time %v", s.Remaining)
+
+	case len(s.Log) == 0 || s.Log[0].Kind != replayLevel:
+		return errors.New("the level log has no maze recorded")
+	}
+
+	return nil
+}
+
+// loadGame recreates the saved level as it was when the game was saved. The level
+// resumes with the time left once the game is played.
+func (s *savedGame) loadGame(terminalSize Dimensions) (*Game, error) {
+	if s.Length > terminalSize.Length || s.Width > terminalSize.Width {
+		return nil, errors.New("the terminal is too small for the saved game, resize it and resume")
+	}
+
+	grid := NewGrid(s.Length, s.Width)
+	copy(grid.cells, s.Cells)
+
+	metrics, err := solver.Analyze(grid, s.Start, s.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid save game found: %v", err)
+	}
+
+	var (
+		config = &Dimensions{Length: s.Length, Width: s.Width, StartPosition: s.Position,
+			FinalPosition: s.Target, Seed: s.Seed}
+		intensity = getLevelIntensity(s.Level)
+	)
+
+	data, err := config.renderMaze(grid, intensity)
+	if err != nil {
+		return nil, err
+	}
+
+	// the games saved before the player's seed was saved continue with the maze seed.
+	playerSeed := s.PlayerSeed
+	if playerSeed == 0 {
+		playerSeed = s.Seed
+	}
+
+	g := &Game{seed: playerSeed, Config: config, Grid: grid, Maze: data, Level: s.Level, Intensity: intensity, Metrics: metrics,
+		status: make(chan int), scores: s.Scores, hintsUsed: s.HintsUsed, moves: s.Moves, start: s.Start,
+		levelLog: s.Log, clock: newGameClock(time.Now)}
+
+	timeLimit := config.getTimeLimit()
+	g.clock.reset(timeLimit, timeLimit-s.Remaining)
+
+	// the level log resumes from the last event logged.
+	g.startedAt = time.Now().Add(-s.Log[len(s.Log)-1].getTime())
+	g.record(replayEvent{Kind: replayStatuses[proceed]})
+
+	return g, nil
+}
+
+// readConfirmation prompts the player with the question provided until a yes or a no is
+// read from the scanner provided. No answer is considered a yes.
+func readConfirmation(scanner *bufio.Scanner, out io.Writer, question string) (bool, error) {
+	for {
+		fmt.Fprint(out, question)
+
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return false, err
+			}
+
+			return false, io.ErrUnexpectedEOF
+		}
+
+		switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+		case "", "y", "yes":
+			return true, nil
+
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
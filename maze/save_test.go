@@ -0,0 +1,180 @@
+package maze
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	"github.com/dmigwi/tapoo/maze/solver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestSaveGame tests the functionality of saveGame, readSaveGame and loadGame
+func TestSaveGame(t *testing.T) {
+	Convey("TestSaveGame: Given a game paused after a few moves and a hint", t, func() {
+		dir, err := ioutil.TempDir("", "tapoo_save")
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		var (
+			path         = filepath.Join(dir, "save.json")
+			terminalSize = Dimensions{Length: 40, Width: 20}
+		)
+
+		game, err := NewGame(1, 2018, terminalSize)
+		So(err, ShouldBeNil)
+
+		game.user = &db.UserInfor{TapooID: "Vf2TqN5MB"}
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		directions := getDirections(game.Config, solution.Path)
+		for _, direction := range directions[:3] {
+			// the level is started earlier so that the moves are logged far enough apart.
+			game.startedAt = game.startedAt.Add(-minMoveInterval)
+			game.Move(direction)
+		}
+
+		So(game.ShowHint(), ShouldBeNil)
+
+		timeLimit := game.getTimeLimit()
+		game.clock.reset(timeLimit, 3*time.Second)
+		game.setScores(game.clock.getScores())
+		game.recordStatus(pause)
+
+		So(game.saveGame(path), ShouldBeNil)
+
+		Convey("the saved game should hold the level as it was paused", func() {
+			s, err := readSaveGame(path)
+
+			So(err, ShouldBeNil)
+			So(s.TapooID, ShouldEqual, "Vf2TqN5MB")
+			So(s.Level, ShouldEqual, 1)
+			So(s.Seed, ShouldEqual, 2018)
+			So(s.PlayerSeed, ShouldEqual, 2018)
+			So(s.Position, ShouldEqual, solution.Path[3])
+			So(s.Remaining, ShouldEqual, timeLimit-3*time.Second)
+			So(s.Log, ShouldHaveLength, 6)
+
+			Convey("and it should be resumed exactly where it was left off", func() {
+				resumed, err := s.loadGame(terminalSize)
+
+				So(err, ShouldBeNil)
+				So(resumed.Maze, ShouldResemble, game.Maze)
+				So(resumed.Grid, ShouldResemble, game.Grid)
+				So(resumed.Metrics, ShouldResemble, game.Metrics)
+				So(resumed.Position(), ShouldEqual, solution.Path[3])
+				So(resumed.Scores(), ShouldEqual, game.Scores())
+				So(resumed.clock.getPlayed(), ShouldEqual, 3*time.Second)
+				So(resumed.clock.getLimit(), ShouldEqual, timeLimit)
+				So(resumed.hintsUsed, ShouldEqual, 1)
+				So(resumed.moves, ShouldEqual, 3)
+				So(resumed.seed, ShouldEqual, 2018)
+				So(resumed.levelLog, ShouldHaveLength, 7)
+				So(resumed.levelLog[6].Time, ShouldBeGreaterThanOrEqualTo, resumed.levelLog[5].Time)
+
+				Convey("and its attempt should still be verified once it is over", func() {
+					for _, direction := range directions[3:] {
+						resumed.startedAt = resumed.startedAt.Add(-minMoveInterval)
+						resumed.Move(direction)
+					}
+
+					resumed.recordStatus(succeeded)
+
+					resumed.mu.Lock()
+					replay := resumed.getReplay()
+					resumed.mu.Unlock()
+
+					So(VerifyAttempt(&db.Attempt{Level: 1, Seed: 2018, Outcome: db.Succeeded, Moves: len(directions),
+						Scores: getLevelScores(timeLimit, 0) - hintPenalty, Replay: replay}), ShouldBeNil)
+				})
+			})
+
+			Convey("but not if the terminal is too small for it", func() {
+				resumed, err := s.loadGame(Dimensions{Length: 5, Width: 5})
+
+				So(resumed, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "the terminal is too small for the saved game")
+			})
+		})
+
+		Convey("an invalid save game file, an error should be returned", func() {
+			for _, item := range []struct {
+				data string
+				msg  string
+			}{
+				{"", "invalid save game found"},
+				{`{"version":2}`, "unsupported save game version found: 2"},
+				{`{"version":1,"level":1,"length":5,"width":5,"cells":"AAAA"}`, "invalid maze of 3 cells"},
+			} {
+				So(ioutil.WriteFile(path, []byte(item.data), 0600), ShouldBeNil)
+
+				s, err := readSaveGame(path)
+
+				So(s, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, item.msg)
+			}
+
+			So(os.Remove(path), ShouldBeNil)
+
+			_, err := readSaveGame(path)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "no saved game was found")
+		})
+	})
+}
+
+// TestReadConfirmation tests the functionality of readConfirmation
+func TestReadConfirmation(t *testing.T) {
+	Convey("TestReadConfirmation: Given the player input", t, func() {
+		var out bytes.Buffer
+
+		Convey("no answer should be considered a yes", func() {
+			resume, err := readConfirmation(bufio.NewScanner(strings.NewReader("\n")), &out, resumePrompt)
+
+			So(err, ShouldBeNil)
+			So(resume, ShouldBeTrue)
+		})
+
+		Convey("the player should be prompted until a yes or a no is entered", func() {
+			resume, err := readConfirmation(bufio.NewScanner(strings.NewReader("maybe\n No \n")), &out, resumePrompt)
+
+			So(err, ShouldBeNil)
+			So(resume, ShouldBeFalse)
+			So(strings.Count(out.String(), resumePrompt), ShouldEqual, 2)
+		})
+
+		Convey("where nothing is entered, an error should be returned", func() {
+			resume, err := readConfirmation(bufio.NewScanner(strings.NewReader("")), &out, resumePrompt)
+
+			So(err, ShouldEqual, io.ErrUnexpectedEOF)
+			So(resume, ShouldBeFalse)
+		})
+
+		Convey("the input entered after the answer should be left for the next prompt", func() {
+			in := bufio.NewScanner(strings.NewReader("n\nVf2TqN5MB\n"))
+
+			resume, err := readConfirmation(in, &out, resumePrompt)
+
+			So(err, ShouldBeNil)
+			So(resume, ShouldBeFalse)
+
+			tapooID, err := readTapooID(in, &out)
+
+			So(err, ShouldBeNil)
+			So(tapooID, ShouldEqual, "Vf2TqN5MB")
+		})
+	})
+}
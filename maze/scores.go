@@ -0,0 +1,199 @@
+package maze
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/api"
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+const (
+	tapooIDPrompt  = "Enter your Tapoo ID: "
+	offlineMsg     = "High scores will not be saved: %v\n"
+	queuedMsg      = "High scores will be sent once the server is reachable: %v\n"
+	topScoresTitle = "                  Level %d Top Five High Scores                   "
+	topScoresRow   = "            %d. %-40s %8d            "
+	userRankRow    = "           #%d %-40s %8d            "
+	topScoresError = "            High scores are unavailable: %v"
+)
+
+// defaultQueuePath defines the file holding the high scores waiting to be sent to the
+// tapoo server if TAPOO_API_QUEUE_PATH is not set.
+const defaultQueuePath = "tapoo_queue.json"
+
+// dbTimeout defines the time allowed to connect to the database before the game is
+// played without saving the high scores. It also limits every request made to the store.
+const dbTimeout = 5 * time.Second
+
+// openStore opens the store configured by the environment variables. The tapoo server at
+// TAPOO_API_URL is used if set, otherwise the database configured by the TAPOO_DB_*
+// environment variables is used.
+func openStore() (db.Store, error) {
+	if baseURL := os.Getenv("TAPOO_API_URL"); len(baseURL) > 0 {
+		queuePath := os.Getenv("TAPOO_API_QUEUE_PATH")
+		if len(queuePath) == 0 {
+			queuePath = defaultQueuePath
+		}
+
+		return api.NewClient(baseURL, queuePath)
+	}
+
+	c, err := db.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	return db.Open(ctx, c)
+}
+
+// readTapooID prompts the player for the Tapoo ID until a non-empty value is read from
+// the scanner provided.
+func readTapooID(scanner *bufio.Scanner, out io.Writer) (string, error) {
+	for {
+		fmt.Fprint(out, tapooIDPrompt)
+
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+
+			return "", io.ErrUnexpectedEOF
+		}
+
+		if tapooID := strings.TrimSpace(scanner.Text()); len(tapooID) > 0 {
+			return tapooID, nil
+		}
+	}
+}
+
+// loadUser fetches or creates the user with the Tapoo ID provided from the store and
+// returns the level the user should resume from. If the tapoo server is unavailable the
+// user is returned with the error so that the high scores can be queued.
+func loadUser(store db.Store, tapooID string) (*db.UserInfor, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	user := &db.UserInfor{TapooID: tapooID}
+
+	if _, err := store.GetOrCreateUser(ctx, user); err != nil {
+		if errors.Is(err, api.ErrUnavailable) {
+			return user, getStartLevel(0), err
+		}
+
+		return nil, 0, err
+	}
+
+	cleared, err := store.GetHighestClearedLevel(ctx, user)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return user, getStartLevel(cleared), nil
+}
+
+// getStartLevel returns the level played after the highest cleared level provided.
+// Players who have not cleared any level start from level one.
+func getStartLevel(cleared int) int {
+	switch {
+	case cleared < 1:
+		return 1
+
+	case cleared >= maxLevel:
+		return maxLevel
+	}
+
+	return cleared + 1
+}
+
+// saveAttempt records the current level attempt with the outcome and the time played
+// provided. The scores of a succeeded attempt are saved if they beat the stored high scores.
+// The attempt is sent with how the level was played so that the tapoo server can verify it.
+func (g *Game) saveAttempt(outcome string, duration time.Duration) error {
+	g.mu.Lock()
+	store, user, level, scores := g.store, g.user, g.Level, g.scores
+	seed, moves, replay := g.Config.Seed, g.moves, g.getReplay()
+	g.mu.Unlock()
+
+	if store == nil || user == nil {
+		return nil
+	}
+
+	// hint penalties may push the scores below zero.
+	if scores < 0 {
+		scores = 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := store.SaveAttempt(ctx, &db.Attempt{TapooID: user.TapooID, Level: level, Seed: seed,
+		Outcome: outcome, Duration: duration, Moves: moves, Scores: scores, Replay: replay})
+	return err
+}
+
+// loadTopScores fetches the top five high scores of the current level that are displayed
+// on the game over screen. Errors found are displayed in place of the high scores.
+func (g *Game) loadTopScores() {
+	g.mu.Lock()
+	store, user, level := g.store, g.user, g.Level
+	g.mu.Unlock()
+
+	if store == nil || user == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	scores, err := store.GetTopScores(ctx, &db.UserInfor{Level: level}, 5)
+	msgs := getTopScoresMsgs(level, scores, err)
+
+	// the player rank is displayed below the top five if the player is not among them.
+	if err == nil && !hasTapooID(scores, user.TapooID) {
+		entries, err := store.GetUserRank(ctx, user, &db.LeaderboardQuery{Level: level}, 0)
+		if err == nil && len(entries) == 1 {
+			msgs = append(msgs, fmt.Sprintf(userRankRow, entries[0].Rank, entries[0].TapooID, entries[0].HighScores))
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.topScores = msgs
+}
+
+// hasTapooID checks if the high scores provided include the Tapoo ID provided.
+func hasTapooID(scores []*db.LevelScoreResponse, tapooID string) bool {
+	for _, s := range scores {
+		if s.TapooID == tapooID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTopScoresMsgs formats the top five high scores provided into the lines displayed.
+func getTopScoresMsgs(level int, scores []*db.LevelScoreResponse, err error) []string {
+	if err != nil {
+		return []string{fmt.Sprintf(topScoresError, err)}
+	}
+
+	msgs := []string{fmt.Sprintf(topScoresTitle, level)}
+
+	for i, s := range scores {
+		msgs = append(msgs, fmt.Sprintf(topScoresRow, i+1, s.TapooID, s.HighScores))
+	}
+
+	return msgs
+}
@@ -0,0 +1,213 @@
+package maze
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/api"
+	"github.com/dmigwi/tapoo/maze/db"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestReadTapooID tests the functionality of readTapooID
+func TestReadTapooID(t *testing.T) {
+	Convey("TestReadTapooID: Given the player input", t, func() {
+		var out bytes.Buffer
+
+		Convey("the first non-empty Tapoo ID entered should be returned", func() {
+			tapooID, err := readTapooID(bufio.NewScanner(strings.NewReader("\n   \n  Vf2TqN5MB \n")), &out)
+
+			So(err, ShouldBeNil)
+			So(tapooID, ShouldEqual, "Vf2TqN5MB")
+			So(strings.Count(out.String(), tapooIDPrompt), ShouldEqual, 3)
+		})
+
+		Convey("where no Tapoo ID is entered, an error should be returned", func() {
+			tapooID, err := readTapooID(bufio.NewScanner(strings.NewReader("\n")), &out)
+
+			So(err, ShouldEqual, io.ErrUnexpectedEOF)
+			So(tapooID, ShouldBeEmpty)
+		})
+	})
+}
+
+// TestGetStartLevel tests the functionality of getStartLevel
+func TestGetStartLevel(t *testing.T) {
+	Convey("TestGetStartLevel: Given the highest level cleared", t, func() {
+		Convey("the level after it should be played up to the maxLevel", func() {
+			for cleared, level := range map[int]int{-1: 1, 0: 1, 1: 2, 19: 20, maxLevel - 1: maxLevel,
+				maxLevel: maxLevel} {
+				So(getStartLevel(cleared), ShouldEqual, level)
+			}
+		})
+	})
+}
+
+// TestGetTopScoresMsgs tests the functionality of getTopScoresMsgs
+func TestGetTopScoresMsgs(t *testing.T) {
+	Convey("TestGetTopScoresMsgs: Given the top five high scores of a level", t, func() {
+		Convey("a title followed by a row for every high score should be returned", func() {
+			msgs := getTopScoresMsgs(3, []*db.LevelScoreResponse{
+				{TapooID: "06PE0LPzyCL", HighScores: 1203},
+				{TapooID: "GzlWAL0mP", HighScores: 1576},
+			}, nil)
+
+			So(msgs, ShouldHaveLength, 3)
+			So(msgs[0], ShouldContainSubstring, "Level 3 Top Five High Scores")
+			So(msgs[1], ShouldContainSubstring, "1. 06PE0LPzyCL")
+			So(msgs[1], ShouldContainSubstring, "1203")
+			So(msgs[2], ShouldContainSubstring, "2. GzlWAL0mP")
+		})
+
+		Convey("where fetching the high scores failed, the error should be returned instead", func() {
+			msgs := getTopScoresMsgs(3, nil, errors.New("sql: database is closed"))
+
+			So(msgs, ShouldHaveLength, 1)
+			So(msgs[0], ShouldContainSubstring, "sql: database is closed")
+		})
+	})
+}
+
+// TestSaveScoresOffline tests that the game can be played without the database.
+func TestSaveScoresOffline(t *testing.T) {
+	Convey("TestSaveScoresOffline: Given a game played without a Tapoo ID", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		Convey("the scores should not be saved and no high scores should be displayed", func() {
+			game.setScores(10000)
+
+			So(game.saveAttempt(db.Succeeded, time.Minute), ShouldBeNil)
+
+			game.loadTopScores()
+			So(game.topScores, ShouldBeEmpty)
+		})
+	})
+}
+
+// TestSaveScores tests the functionality of saveAttempt
+func TestSaveScores(t *testing.T) {
+	Convey("TestSaveScores: Given a game played with a Tapoo ID", t, func() {
+		store := db.NewMemoryStore()
+		defer store.Close()
+
+		user, level, err := loadUser(store, "Vf2TqN5MB")
+		So(err, ShouldBeNil)
+		So(level, ShouldEqual, 1)
+
+		game, err := NewGame(level, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		game.store, game.user = store, user
+
+		Convey("the scores should be saved only if they beat the stored high scores", func() {
+			game.setScores(1200)
+			So(game.saveAttempt(db.Succeeded, time.Minute), ShouldBeNil)
+
+			game.setScores(800)
+			So(game.saveAttempt(db.Succeeded, time.Minute), ShouldBeNil)
+
+			scores, err := store.GetOrCreateLevelScore(context.Background(), &db.UserInfor{TapooID: "Vf2TqN5MB", Level: 1})
+
+			So(err, ShouldBeNil)
+			So(scores.HighScores, ShouldEqual, 1200)
+
+			Convey("and the cleared level should be resumed from the next level", func() {
+				_, level, err = loadUser(store, "Vf2TqN5MB")
+
+				So(err, ShouldBeNil)
+				So(level, ShouldEqual, 2)
+			})
+
+			Convey("and the saved high scores should be displayed", func() {
+				game.loadTopScores()
+
+				So(game.topScores, ShouldHaveLength, 2)
+				So(game.topScores[1], ShouldContainSubstring, "1. Vf2TqN5MB")
+				So(game.topScores[1], ShouldContainSubstring, "1200")
+			})
+
+			Convey("and the failed attempts should be recorded without replacing the high scores", func() {
+				game.Move("LEFT")
+				game.Move("RIGHT")
+				game.setScores(5000)
+
+				So(game.saveAttempt(db.Failed, 2*time.Minute), ShouldBeNil)
+
+				scores, err := store.GetOrCreateLevelScore(context.Background(), &db.UserInfor{TapooID: "Vf2TqN5MB", Level: 1})
+
+				So(err, ShouldBeNil)
+				So(scores.HighScores, ShouldEqual, 1200)
+
+				attempts, err := store.GetAttempts(context.Background(), &db.AttemptsQuery{TapooID: "Vf2TqN5MB",
+					Level: 1, Limit: 5})
+
+				So(err, ShouldBeNil)
+				So(attempts, ShouldHaveLength, 3)
+				So(attempts[0].Outcome, ShouldEqual, db.Failed)
+				So(attempts[0].Seed, ShouldEqual, 2018)
+				So(attempts[0].Duration, ShouldEqual, 2*time.Minute)
+				So(attempts[0].Moves, ShouldEqual, game.moves)
+			})
+
+			Convey("and the player rank should be displayed if the player is not in the top five", func() {
+				for i, id := range []string{"GzlWAL0mP", "FbnnuznkFAN", "06PE0LPzyCL", "VZWeOq2p", "Fbn56nuznk"} {
+					_, err := store.GetOrCreateUser(context.Background(), &db.UserInfor{TapooID: id})
+					So(err, ShouldBeNil)
+
+					_, err = store.SaveHighScores(context.Background(), &db.UserInfor{TapooID: id, Level: 1}, 1300+i)
+					So(err, ShouldBeNil)
+				}
+
+				game.loadTopScores()
+
+				So(game.topScores, ShouldHaveLength, 7)
+				So(game.topScores[6], ShouldContainSubstring, "#6 Vf2TqN5MB")
+				So(game.topScores[6], ShouldContainSubstring, "1200")
+			})
+		})
+	})
+}
+
+// TestSaveScoresQueued tests the functionality of saveAttempt while the tapoo server is unavailable
+func TestSaveScoresQueued(t *testing.T) {
+	Convey("TestSaveScoresQueued: Given a game played while the tapoo server is unavailable", t, func() {
+		dir, err := ioutil.TempDir("", "tapoo_scores")
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		// nothing listens on port 1 thus every request fails.
+		store, err := api.NewClient("http://127.0.0.1:1", filepath.Join(dir, "queue.json"))
+		So(err, ShouldBeNil)
+
+		defer store.Close()
+
+		user, level, err := loadUser(store, "Vf2TqN5MB")
+
+		So(errors.Is(err, api.ErrUnavailable), ShouldBeTrue)
+		So(user, ShouldResemble, &db.UserInfor{TapooID: "Vf2TqN5MB"})
+		So(level, ShouldEqual, 1)
+
+		game, err := NewGame(level, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		game.store, game.user = store, user
+
+		Convey("the scores should be queued to be sent later", func() {
+			game.setScores(1200)
+
+			So(errors.Is(game.saveAttempt(db.Succeeded, time.Minute), api.ErrUnavailable), ShouldBeTrue)
+			So(store.Queued(), ShouldEqual, 1)
+		})
+	})
+}
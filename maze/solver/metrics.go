@@ -0,0 +1,97 @@
+package solver
+
+// Metrics defines the measurements used to rate how difficult a maze is to solve.
+//
+// SolutionLength is the number of moves on the shortest path from the start to the goal.
+// DeadEnds is the number of cells with only a single path leading out of them.
+// BranchingFactor is the average number of onward paths the player can choose from at
+// every cell on the shortest path. Turns is the number of times the direction changes
+// along the shortest path. River measures how much the maze flows: mazes with a few
+// long dead end branches have a river factor close to one while mazes with many short
+// dead ends have a river factor close to zero.
+type Metrics struct {
+	Cells           int
+	SolutionLength  int
+	DeadEnds        int
+	BranchingFactor float64
+	Turns           int
+	River           float64
+}
+
+// Analyze computes the metrics of the maze using the shortest path between the start and the goal.
+func Analyze(m Maze, start, goal int) (*Metrics, error) {
+	solution, err := BFS(m, start, goal)
+	if err != nil {
+		return nil, err
+	}
+
+	length, width := m.Size()
+
+	metrics := &Metrics{Cells: length * width, SolutionLength: solution.Length}
+
+	for cellNo := 1; cellNo <= metrics.Cells; cellNo++ {
+		if len(getOpenNeighbors(m, cellNo)) == 1 {
+			metrics.DeadEnds++
+		}
+	}
+
+	choices := 0
+	for i, cellNo := range solution.Path[:len(solution.Path)-1] {
+		exits := len(getOpenNeighbors(m, cellNo))
+
+		// the path leading back to the previous cell is not a choice.
+		if i > 0 {
+			exits--
+		}
+
+		choices += exits
+	}
+
+	if solution.Length > 0 {
+		metrics.BranchingFactor = float64(choices) / float64(solution.Length)
+	}
+
+	for i := 2; i < len(solution.Path); i++ {
+		previous := solution.Path[i-1] - solution.Path[i-2]
+		current := solution.Path[i] - solution.Path[i-1]
+
+		if previous != current {
+			metrics.Turns++
+		}
+	}
+
+	// branchCells are the cells that are off the shortest path.
+	branchCells := metrics.Cells - len(solution.Path)
+	if branchCells > 0 {
+		metrics.River = 1 - (float64(metrics.DeadEnds) / float64(branchCells))
+	}
+
+	if metrics.River < 0 {
+		metrics.River = 0
+	}
+
+	return metrics, nil
+}
+
+// Difficulty rates the maze difficulty with a value between zero and one where a higher value
+// means a harder maze. Longer solutions with more turns and more choices to make along the
+// way make the maze harder, while a maze that flows does not.
+func (m *Metrics) Difficulty() float64 {
+	if m.Cells == 0 || m.SolutionLength == 0 {
+		return 0
+	}
+
+	var (
+		pathRatio = float64(m.SolutionLength) / float64(m.Cells)
+		turnRatio = float64(m.Turns) / float64(m.SolutionLength)
+
+		// at most three onward paths exist from any cell.
+		choiceRatio = (m.BranchingFactor - 1) / 2
+	)
+
+	if choiceRatio < 0 {
+		choiceRatio = 0
+	}
+
+	return (0.4 * pathRatio) + (0.2 * turnRatio) + (0.3 * choiceRatio) + (0.1 * (1 - m.River))
+}
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestAnalyze tests the functionality of Analyze
+func TestAnalyze(t *testing.T) {
+	Convey("TestAnalyze: Given a maze, the start and the goal", t, func() {
+		Convey("the metrics computed should match the maze", func() {
+			metrics, err := Analyze(perfectMaze, 6, 4)
+
+			So(err, ShouldBeNil)
+			So(metrics.Cells, ShouldEqual, 12)
+			So(metrics.SolutionLength, ShouldEqual, 9)
+
+			// cells 6 and 12 are the only dead ends.
+			So(metrics.DeadEnds, ShouldEqual, 2)
+
+			// the path is a single corridor thus no cell offers a choice.
+			So(metrics.BranchingFactor, ShouldAlmostEqual, 1.0)
+
+			// the path turns at cells 7, 11, 9 and 1.
+			So(metrics.Turns, ShouldEqual, 4)
+
+			// cells 8 and 12 are off the path with a single dead end.
+			So(metrics.River, ShouldAlmostEqual, 0.0)
+
+			So(metrics.Difficulty(), ShouldBeBetween, 0, 1)
+		})
+
+		Convey("with more than one path, the choices at the start should raise the branching factor", func() {
+			metrics, err := Analyze(loopMaze, 1, 6)
+
+			So(err, ShouldBeNil)
+			So(metrics.SolutionLength, ShouldEqual, 3)
+			So(metrics.DeadEnds, ShouldEqual, 0)
+			So(metrics.BranchingFactor, ShouldAlmostEqual, 4.0/3.0)
+		})
+
+		Convey("where the goal cannot be reached, ErrNoPath should be returned", func() {
+			metrics, err := Analyze(newTestMaze(2, 2, [2]int{1, 2}), 1, 4)
+
+			So(metrics, ShouldBeNil)
+			So(err, ShouldEqual, ErrNoPath)
+		})
+	})
+}
+
+// TestDifficulty tests the functionality of Difficulty
+func TestDifficulty(t *testing.T) {
+	Convey("TestDifficulty: Given the maze metrics", t, func() {
+		Convey("with no solution, the difficulty should be zero", func() {
+			So((&Metrics{}).Difficulty(), ShouldEqual, 0)
+		})
+
+		Convey("with a longer solution and more turns, the difficulty should be higher", func() {
+			easy := &Metrics{Cells: 100, SolutionLength: 20, Turns: 2, BranchingFactor: 1.2, River: 0.5}
+			hard := &Metrics{Cells: 100, SolutionLength: 60, Turns: 40, BranchingFactor: 1.2, River: 0.5}
+
+			So(hard.Difficulty(), ShouldBeGreaterThan, easy.Difficulty())
+		})
+	})
+}
@@ -0,0 +1,252 @@
+// Package solver computes the paths between two cells of a maze.
+package solver
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// Maze defines the maze topology that the solvers navigate through. Cells are numbered
+// from one, row by row starting from the top left cell. Length represents the number of
+// the cells along the horizontal edge while width represents the number of the cells
+// along the vertical edge.
+type Maze interface {
+	Size() (length, width int)
+	IsPassable(currentCellNo, newCellNo int) bool
+}
+
+// Solution defines the cells making up the path from the start to the goal, both included.
+// Length is the number of moves needed to get from the start to the goal while Visited is
+// the number of cells the solver explored before locating the goal.
+type Solution struct {
+	Path    []int
+	Length  int
+	Visited int
+}
+
+// ErrNoPath is returned when the goal cannot be reached from the start.
+var ErrNoPath = errors.New("solver: no path exists between the start and the goal")
+
+// errInvalidCell is returned when either the start or the goal does not exist in the maze.
+var errInvalidCell = errors.New("solver: invalid start or goal cell found")
+
+// BFS finds the shortest path between the start and the goal using the breadth first search.
+func BFS(m Maze, start, goal int) (*Solution, error) {
+	if !isValid(m, start) || !isValid(m, goal) {
+		return nil, errInvalidCell
+	}
+
+	var (
+		queue    = []int{start}
+		previous = map[int]int{start: 0}
+	)
+
+	for len(queue) > 0 {
+		currentPos := queue[0]
+		queue = queue[1:]
+
+		if currentPos == goal {
+			return newSolution(previous, goal, len(previous)), nil
+		}
+
+		for _, neighbor := range getOpenNeighbors(m, currentPos) {
+			if _, ok := previous[neighbor]; !ok {
+				previous[neighbor] = currentPos
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+// AStar finds the shortest path between the start and the goal using the A* search
+// with the manhattan distance to the goal as the heuristic.
+func AStar(m Maze, start, goal int) (*Solution, error) {
+	if !isValid(m, start) || !isValid(m, goal) {
+		return nil, errInvalidCell
+	}
+
+	var (
+		visited  = 0
+		cost     = map[int]int{start: 0}
+		previous = map[int]int{start: 0}
+		closed   = map[int]bool{}
+		open     = &priorityQueue{{cellNo: start, priority: getManhattanDistance(m, start, goal)}}
+	)
+
+	for open.Len() > 0 {
+		currentPos := heap.Pop(open).(*queueItem).cellNo
+		if closed[currentPos] {
+			continue
+		}
+
+		closed[currentPos] = true
+		visited++
+
+		if currentPos == goal {
+			return newSolution(previous, goal, visited), nil
+		}
+
+		for _, neighbor := range getOpenNeighbors(m, currentPos) {
+			newCost := cost[currentPos] + 1
+
+			if oldCost, ok := cost[neighbor]; !ok || newCost < oldCost {
+				cost[neighbor], previous[neighbor] = newCost, currentPos
+
+				heap.Push(open, &queueItem{cellNo: neighbor,
+					priority: newCost + getManhattanDistance(m, neighbor, goal)})
+			}
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+// WallFollower walks from the start to the goal while always keeping its right hand on
+// the wall. The path returned is the actual route walked thus it may contain dead ends
+// and is rarely the shortest path. It only guarantees to locate the goal in mazes
+// without loops.
+func WallFollower(m Maze, start, goal int) (*Solution, error) {
+	if !isValid(m, start) || !isValid(m, goal) {
+		return nil, errInvalidCell
+	}
+
+	var (
+		length, width = m.Size()
+		currentPos    = start
+		path          = []int{start}
+		visited       = map[int]bool{start: true}
+
+		// facing holds the current direction, the directions are ordered
+		// clockwise i.e. up, right, down and left.
+		facing = 0
+	)
+
+	// In a maze without loops, every path is walked at most twice (once in
+	// each direction) thus a longer walk means the goal cannot be reached.
+	for steps := 0; currentPos != goal; steps++ {
+		if steps > 4*length*width {
+			return nil, ErrNoPath
+		}
+
+		// try turning right first, then straight ahead, then left and finally backwards.
+		for _, turn := range []int{1, 0, 3, 2} {
+			direction := (facing + turn) % 4
+			newPos := getNeighbor(m, currentPos, direction)
+
+			if newPos != 0 && m.IsPassable(currentPos, newPos) {
+				facing, currentPos = direction, newPos
+				break
+			}
+		}
+
+		if len(path) > 0 && path[len(path)-1] == currentPos {
+			// the start cell has no paths to any other cell.
+			return nil, ErrNoPath
+		}
+
+		path = append(path, currentPos)
+		visited[currentPos] = true
+	}
+
+	return &Solution{Path: path, Length: len(path) - 1, Visited: len(visited)}, nil
+}
+
+// newSolution creates the solution by tracing back the path from the goal to
+// the start using the previous cell recorded for every cell reached.
+func newSolution(previous map[int]int, goal, visited int) *Solution {
+	var path []int
+
+	for cellNo := goal; cellNo != 0; cellNo = previous[cellNo] {
+		path = append([]int{cellNo}, path...)
+	}
+
+	return &Solution{Path: path, Length: len(path) - 1, Visited: visited}
+}
+
+// isValid checks if the provided cell exists in the maze.
+func isValid(m Maze, cellNo int) bool {
+	length, width := m.Size()
+	return cellNo > 0 && cellNo <= length*width
+}
+
+// getNeighbor returns the cell next to the provided cell in the direction provided.
+// Directions are numbered clockwise from zero i.e. up, right, down and left.
+// Zero is returned if the neighbor does not exist.
+func getNeighbor(m Maze, cellNo, direction int) int {
+	length, width := m.Size()
+	col := (cellNo - 1) % length
+
+	switch {
+	case direction == 0 && cellNo > length:
+		return cellNo - length
+
+	case direction == 1 && col < length-1:
+		return cellNo + 1
+
+	case direction == 2 && cellNo+length <= length*width:
+		return cellNo + length
+
+	case direction == 3 && col > 0:
+		return cellNo - 1
+	}
+
+	return 0
+}
+
+// getOpenNeighbors returns the neighboring cells that have a common path with the provided cell.
+func getOpenNeighbors(m Maze, cellNo int) []int {
+	var cells []int
+
+	for direction := 0; direction < 4; direction++ {
+		newPos := getNeighbor(m, cellNo, direction)
+
+		if newPos != 0 && m.IsPassable(cellNo, newPos) {
+			cells = append(cells, newPos)
+		}
+	}
+
+	return cells
+}
+
+// getManhattanDistance returns the least number of moves between the two cells
+// provided if no walls existed between them.
+func getManhattanDistance(m Maze, currentCellNo, newCellNo int) int {
+	length, _ := m.Size()
+
+	abs := func(val int) int {
+		if val < 0 {
+			return -val
+		}
+		return val
+	}
+
+	return abs((currentCellNo-1)/length-(newCellNo-1)/length) +
+		abs((currentCellNo-1)%length-(newCellNo-1)%length)
+}
+
+// queueItem defines a cell in the A* open set and its priority.
+type queueItem struct {
+	cellNo   int
+	priority int
+}
+
+// priorityQueue implements heap.Interface with the lowest priority cell at the top.
+type priorityQueue []*queueItem
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x interface{}) { *q = append(*q, x.(*queueItem)) }
+
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return item
+}
@@ -0,0 +1,152 @@
+package solver
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testMaze implements the Maze interface using a set of the common paths between cells.
+type testMaze struct {
+	length, width int
+	paths         map[[2]int]bool
+}
+
+func (m *testMaze) Size() (int, int) { return m.length, m.width }
+
+func (m *testMaze) IsPassable(currentCellNo, newCellNo int) bool {
+	return m.paths[[2]int{currentCellNo, newCellNo}] || m.paths[[2]int{newCellNo, currentCellNo}]
+}
+
+// newTestMaze creates a test maze with the provided paths carved.
+func newTestMaze(length, width int, paths ...[2]int) *testMaze {
+	m := &testMaze{length: length, width: width, paths: map[[2]int]bool{}}
+
+	for _, path := range paths {
+		m.paths[path] = true
+	}
+
+	return m
+}
+
+// perfectMaze represents the maze below with a single path between any two cells.
+//
+//	|---|---|---|---|
+//	| 1   2   3   4 |
+//	|   |---|---|   |
+//	| 5 | 6   7 | 8 |
+//	|   |---|   |   |
+//	| 9  10  11 |12 |
+//	|---|---|---|---|
+var perfectMaze = newTestMaze(4, 3, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 4}, [2]int{1, 5},
+	[2]int{4, 8}, [2]int{5, 9}, [2]int{6, 7}, [2]int{7, 11}, [2]int{8, 12}, [2]int{9, 10},
+	[2]int{10, 11})
+
+// loopMaze represents the maze below where two paths exist between cells 1 and 6.
+//
+//	|---|---|---|
+//	| 1   2   3 |
+//	|   |---|   |
+//	| 4   5   6 |
+//	|---|---|---|
+var loopMaze = newTestMaze(3, 2, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 6}, [2]int{1, 4},
+	[2]int{4, 5}, [2]int{5, 6})
+
+// TestBFS tests the functionality of BFS
+func TestBFS(t *testing.T) {
+	Convey("TestBFS: Given a maze, the start and the goal", t, func() {
+		Convey("the shortest path between the start and the goal should be returned", func() {
+			solution, err := BFS(perfectMaze, 6, 4)
+
+			So(err, ShouldBeNil)
+			So(solution.Path, ShouldResemble, []int{6, 7, 11, 10, 9, 5, 1, 2, 3, 4})
+			So(solution.Length, ShouldEqual, 9)
+
+			solution, err = BFS(loopMaze, 1, 6)
+
+			So(err, ShouldBeNil)
+			So(solution.Length, ShouldEqual, 3)
+		})
+
+		Convey("where the start is the goal, a path with no moves should be returned", func() {
+			solution, err := BFS(perfectMaze, 7, 7)
+
+			So(err, ShouldBeNil)
+			So(solution.Path, ShouldResemble, []int{7})
+			So(solution.Length, ShouldEqual, 0)
+		})
+
+		Convey("where the goal cannot be reached, ErrNoPath should be returned", func() {
+			solution, err := BFS(newTestMaze(2, 2, [2]int{1, 2}), 1, 4)
+
+			So(solution, ShouldBeNil)
+			So(err, ShouldEqual, ErrNoPath)
+		})
+
+		Convey("where the start or the goal does not exist, an error should be returned", func() {
+			solution, err := BFS(perfectMaze, 0, 13)
+
+			So(solution, ShouldBeNil)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid start or goal cell found")
+		})
+	})
+}
+
+// TestAStar tests the functionality of AStar
+func TestAStar(t *testing.T) {
+	Convey("TestAStar: Given a maze, the start and the goal", t, func() {
+		Convey("a path as short as the BFS path should be returned", func() {
+			for _, cells := range [][2]int{{6, 4}, {1, 12}, {12, 6}, {3, 9}} {
+				expected, err := BFS(perfectMaze, cells[0], cells[1])
+				So(err, ShouldBeNil)
+
+				solution, err := AStar(perfectMaze, cells[0], cells[1])
+
+				So(err, ShouldBeNil)
+				So(solution.Path, ShouldResemble, expected.Path)
+				So(solution.Length, ShouldEqual, expected.Length)
+			}
+
+			solution, err := AStar(loopMaze, 1, 6)
+
+			So(err, ShouldBeNil)
+			So(solution.Length, ShouldEqual, 3)
+		})
+
+		Convey("where the goal cannot be reached, ErrNoPath should be returned", func() {
+			solution, err := AStar(newTestMaze(2, 2, [2]int{1, 2}), 1, 4)
+
+			So(solution, ShouldBeNil)
+			So(err, ShouldEqual, ErrNoPath)
+		})
+	})
+}
+
+// TestWallFollower tests the functionality of WallFollower
+func TestWallFollower(t *testing.T) {
+	Convey("TestWallFollower: Given a maze, the start and the goal", t, func() {
+		Convey("a path that ends at the goal and only moves through the maze paths should be returned", func() {
+			solution, err := WallFollower(perfectMaze, 6, 4)
+
+			So(err, ShouldBeNil)
+			So(solution.Path[0], ShouldEqual, 6)
+			So(solution.Path[len(solution.Path)-1], ShouldEqual, 4)
+			So(solution.Length, ShouldEqual, len(solution.Path)-1)
+			So(solution.Length, ShouldBeGreaterThanOrEqualTo, 9)
+
+			for i := 1; i < len(solution.Path); i++ {
+				So(perfectMaze.IsPassable(solution.Path[i-1], solution.Path[i]), ShouldBeTrue)
+			}
+		})
+
+		Convey("where the goal cannot be reached, ErrNoPath should be returned", func() {
+			for _, m := range []*testMaze{newTestMaze(2, 2, [2]int{1, 2}), newTestMaze(2, 2)} {
+				solution, err := WallFollower(m, 1, 4)
+
+				So(solution, ShouldBeNil)
+				So(err, ShouldEqual, ErrNoPath)
+			}
+		})
+	})
+}
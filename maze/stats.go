@@ -0,0 +1,74 @@
+package maze
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+const (
+	statsSummary = "Tapoo ID: %s\nAttempts: %d   Win rate: %.1f%%   Average time: %s\n\n"
+	statsHeader  = "Level  Attempts  Won  Failed  Quit  Win rate  Average time  Best scores  Personal bests\n"
+	statsRow     = "%5d  %8d  %3d  %6d  %4d  %7.1f%%  %12s  %11d  %s\n"
+)
+
+// PrintStats writes the summary of the levels played by the player with the Tapoo ID
+// provided. The tapoo server or the database configured by the environment variables is used.
+func PrintStats(w io.Writer, tapooID string) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	stats, err := store.GetStats(ctx, &db.UserInfor{TapooID: tapooID})
+	if err != nil {
+		return err
+	}
+
+	writeStats(w, stats)
+
+	return nil
+}
+
+// writeStats writes the stats provided as a table holding a row for every level played.
+// The personal bests trend lists the scores that beat the previous best scores in order.
+func writeStats(w io.Writer, stats *db.Stats) {
+	fmt.Fprintf(w, statsSummary, stats.TapooID, stats.Attempts, stats.WinRate*100,
+		formatDuration(stats.AverageDuration))
+
+	if len(stats.Levels) == 0 {
+		return
+	}
+
+	fmt.Fprint(w, statsHeader)
+
+	for _, l := range stats.Levels {
+		bests := make([]string, 0, len(l.PersonalBests))
+		for _, a := range l.PersonalBests {
+			bests = append(bests, strconv.Itoa(a.Scores))
+		}
+
+		fmt.Fprintf(w, statsRow, l.Level, l.Attempts, l.Succeeded, l.Failed, l.Quit, l.WinRate*100,
+			formatDuration(l.AverageDuration), l.BestScores, strings.Join(bests, " -> "))
+	}
+}
+
+// formatDuration returns the duration provided rounded to the second, or a dash if no
+// duration was recorded.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,45 @@
+package maze
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestWriteStats tests the functionality of writeStats
+func TestWriteStats(t *testing.T) {
+	Convey("TestWriteStats: Given the stats of a player", t, func() {
+		var out bytes.Buffer
+
+		Convey("a row should be written for every level played", func() {
+			writeStats(&out, &db.Stats{TapooID: "Vf2TqN5MB", Attempts: 4, Succeeded: 3, WinRate: 0.75,
+				AverageDuration: 95400 * time.Millisecond, Levels: []*db.LevelStats{
+					{Level: 1, Attempts: 1, Succeeded: 1, WinRate: 1, BestScores: 1400,
+						PersonalBests: []*db.Attempt{{Scores: 1400}}},
+					{Level: 2, Attempts: 3, Succeeded: 2, Failed: 1, WinRate: 2.0 / 3.0,
+						AverageDuration: 95400 * time.Millisecond, BestScores: 1200,
+						PersonalBests: []*db.Attempt{{Scores: 600}, {Scores: 1200}}},
+				}})
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+			So(lines, ShouldHaveLength, 6)
+			So(lines[0], ShouldEqual, "Tapoo ID: Vf2TqN5MB")
+			So(lines[1], ShouldEqual, "Attempts: 4   Win rate: 75.0%   Average time: 1m35s")
+			So(lines[3], ShouldStartWith, "Level  Attempts")
+			So(lines[4], ShouldEqual, "    1         1    1       0     0    100.0%             -         1400  1400")
+			So(lines[5], ShouldEqual, "    2         3    2       1     0     66.7%         1m35s         1200  600 -> 1200")
+		})
+
+		Convey("no levels should be listed if the player has not played", func() {
+			writeStats(&out, &db.Stats{TapooID: "Vf2TqN5MB", Levels: []*db.LevelStats{}})
+
+			So(out.String(), ShouldNotContainSubstring, "Level")
+			So(out.String(), ShouldContainSubstring, "Average time: -")
+		})
+	})
+}
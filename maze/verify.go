@@ -0,0 +1,159 @@
+package maze
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+// verifyTolerance defines the difference allowed between the time played submitted and
+// the time played replayed since the game updates the scores on every tick rather than
+// when the events happen.
+const verifyTolerance = time.Second
+
+// minMoveInterval defines the shortest time allowed between two moves or two hints replayed.
+// It matches the fastest key repeat rate offered by the keyboard settings of the common
+// desktops, about 33 keys per second, since a player cannot press a key faster than by
+// holding it down.
+const minMoveInterval = 30 * time.Millisecond
+
+// VerifyAttempt regenerates the maze of the level attempt provided from its seed, replays
+// its moves using the player movement rules and recomputes the time played and the scores
+// from the time the events happened. An error describing the mismatch is returned if the
+// attempt does not match its replay.
+//
+// The verifier only checks that the attempt is consistent with its replay and that the
+// replay obeys the game rules. It cannot prove that the replay was played by a human since
+// a program can generate a replay that a player could have made.
+func VerifyAttempt(a *db.Attempt) error {
+	rp, moves, err := getAttemptReplay(a)
+	if err != nil {
+		return err
+	}
+
+	p, err := newReplayPlayer(rp)
+	if err != nil {
+		return err
+	}
+
+	if err = p.seek(p.end()); err != nil {
+		return err
+	}
+
+	var (
+		g      = p.game
+		spent  = p.getSpent()
+		scores = g.Scores()
+	)
+
+	// hint penalties may push the scores below zero.
+	if scores < 0 {
+		scores = 0
+	}
+
+	switch {
+	case g.moves != moves:
+		return fmt.Errorf("%d of the %d moves replayed go through the maze walls", moves-g.moves, moves)
+
+	case g.moves != a.Moves:
+		return fmt.Errorf("invalid moves found: %d submitted, %d replayed", a.Moves, g.moves)
+
+	case a.Outcome == db.Succeeded && !g.IsTargetFound():
+		return errors.New("the target was not located")
+
+	case a.Outcome == db.Failed && g.IsTargetFound():
+		return errors.New("the target was located")
+
+	case a.Outcome == db.Failed && spent < g.getTimeLimit()-verifyTolerance:
+		return errors.New("the level failed before the time ran out")
+
+	case a.Duration > spent+verifyTolerance || a.Duration < spent-verifyTolerance:
+		return fmt.Errorf("invalid duration found: %v submitted, %v replayed", a.Duration, spent)
+
+	case a.Scores > scores+getLevelScores(verifyTolerance, 0) || a.Scores < scores-getLevelScores(verifyTolerance, 0):
+		return fmt.Errorf("invalid scores found: %d submitted, %d replayed", a.Scores, scores)
+	}
+
+	return nil
+}
+
+// getAttemptReplay returns the replay of the level attempt provided starting with its maze
+// being loaded together with the number of moves it holds. Replays holding moves or hints
+// made while the game was paused or moves or hints made faster than a player can make them
+// are rejected.
+func getAttemptReplay(a *db.Attempt) (*replay, int, error) {
+	switch {
+	case a.Replay == nil:
+		return nil, 0, errors.New("the attempt replay was not found")
+
+	// larger levels cannot be played and would take too long to generate.
+	case a.Level < 0 || a.Level > maxLevel:
+		return nil, 0, fmt.Errorf("invalid level found: %d", a.Level)
+
+	}
+
+	// the time limit bounds the work done to verify the attempt since every hint replayed
+	// searches the maze for the shortest path to the target.
+	config, err := getMazeDimensions(a.Level, a.Seed, Dimensions{Length: a.Replay.Length, Width: a.Replay.Width})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if maxEvents := getMaxReplayEvents(config.getTimeLimit()); len(a.Replay.Events) > maxEvents {
+		return nil, 0, fmt.Errorf("the replay holds more than %d events", maxEvents)
+	}
+
+	var (
+		moves, hints       int
+		paused             bool
+		lastMove, lastHint int64
+		rp                 = &replay{seed: a.Seed, events: []replayEvent{{Kind: replayLevel, Level: a.Level,
+			Length: a.Replay.Length, Width: a.Replay.Width}}}
+	)
+
+	for i, e := range a.Replay.Events {
+		switch {
+		case e.Time < 0 || (i > 0 && e.Time < a.Replay.Events[i-1].Time):
+			return nil, 0, errors.New("the replay events are not in order")
+
+		case e.Kind == replayLevel:
+			return nil, 0, errors.New("the replay holds more than one level")
+
+		case (e.Kind == replayMove || e.Kind == replayHint) && paused:
+			return nil, 0, errors.New("the replay holds events made while the game was paused")
+
+		case e.Kind == replayMove && moves > 0 && e.Time-lastMove < minMoveInterval.Milliseconds():
+			return nil, 0, errors.New("the replay moves were made too fast")
+
+		case e.Kind == replayMove:
+			moves++
+			lastMove = e.Time
+
+		case e.Kind == replayHint && hints > 0 && e.Time-lastHint < minMoveInterval.Milliseconds():
+			return nil, 0, errors.New("the replay hints were made too fast")
+
+		case e.Kind == replayHint:
+			hints++
+			lastHint = e.Time
+
+		// the player can only move once the game proceeds after any other status.
+		default:
+			if status, ok := getReplayStatus(e.Kind); ok {
+				paused = status != proceed
+			}
+		}
+
+		rp.events = append(rp.events, replayEvent{Time: e.Time, Kind: e.Kind, Direction: e.Direction})
+	}
+
+	return rp, moves, nil
+}
+
+// getMaxReplayEvents returns the maximum number of events a replay of a level with the
+// time limit provided can hold. The moves and the hints can be made at most once every
+// minMoveInterval until the time runs out, the game statuses are allowed as many events.
+func getMaxReplayEvents(timeLimit time.Duration) int {
+	return 3 * (int(timeLimit/minMoveInterval) + 1)
+}
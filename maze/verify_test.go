@@ -0,0 +1,202 @@
+package maze
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze/db"
+	"github.com/dmigwi/tapoo/maze/solver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// TestVerifyAttempt tests the functionality of VerifyAttempt
+func TestVerifyAttempt(t *testing.T) {
+	Convey("TestVerifyAttempt: Given a level attempt and its replay", t, func() {
+		game, err := NewGame(1, 2018, Dimensions{Length: 40, Width: 20})
+		So(err, ShouldBeNil)
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		// the player moves along the shortest path every half a second.
+		var (
+			moves  = getDirections(game.Config, solution.Path)
+			spent  = time.Duration(len(moves)) * 500 * time.Millisecond
+			replay = &db.Replay{Length: 40, Width: 20}
+		)
+
+		for i, direction := range moves {
+			replay.Events = append(replay.Events, db.ReplayEvent{Time: int64(i+1) * 500, Kind: replayMove,
+				Direction: direction})
+		}
+
+		replay.Events = append(replay.Events, db.ReplayEvent{Time: spent.Milliseconds(), Kind: replayStatuses[succeeded]})
+
+		timeLimit := game.getTimeLimit()
+
+		attempt := &db.Attempt{TapooID: "Vf2TqN5MB", Level: 1, Seed: 2018, Outcome: db.Succeeded, Duration: spent,
+			Moves: len(moves), Scores: getLevelScores(timeLimit, spent), Replay: replay}
+
+		Convey("an attempt matching its replay should be verified", func() {
+			So(VerifyAttempt(attempt), ShouldBeNil)
+
+			Convey("even if the scores were updated a tick later", func() {
+				attempt.Scores -= 100
+				attempt.Duration += 20 * time.Millisecond
+
+				So(VerifyAttempt(attempt), ShouldBeNil)
+			})
+		})
+
+		Convey("an attempt logged by the game should be verified", func() {
+			for _, direction := range moves {
+				// the level is started earlier so that the moves are logged far enough apart.
+				game.startedAt = game.startedAt.Add(-minMoveInterval)
+				game.Move(direction)
+			}
+
+			game.recordStatus(succeeded)
+
+			game.mu.Lock()
+			replay := game.getReplay()
+			game.mu.Unlock()
+
+			So(replay.Length, ShouldEqual, 40)
+			So(replay.Events, ShouldHaveLength, len(moves)+1)
+
+			So(VerifyAttempt(&db.Attempt{Level: 1, Seed: 2018, Outcome: db.Succeeded, Moves: game.moves,
+				Scores: getLevelScores(game.getTimeLimit(), 0), Replay: replay}), ShouldBeNil)
+		})
+
+		Convey("an attempt that does not match its replay should be rejected", func() {
+			for _, item := range []struct {
+				update func(a *db.Attempt)
+				msg    string
+			}{
+				{func(a *db.Attempt) { a.Replay = nil }, "the attempt replay was not found"},
+				{func(a *db.Attempt) { a.Level = maxLevel + 1 }, "invalid level found: 291"},
+				{func(a *db.Attempt) { a.Replay.Events[1].Time = 0 }, "the replay events are not in order"},
+				{func(a *db.Attempt) { a.Replay.Events[1].Kind = replayLevel }, "the replay holds more than one level"},
+				{func(a *db.Attempt) { a.Replay.Events[1].Kind = "jump" }, "invalid replay event found"},
+				{func(a *db.Attempt) { a.Replay.Events[1].Time = a.Replay.Events[0].Time }, "the replay moves were made too fast"},
+				{func(a *db.Attempt) {
+					a.Replay.Events = append([]db.ReplayEvent{{Time: 100, Kind: replayStatuses[pause]}}, a.Replay.Events...)
+					a.Replay.Events = append(a.Replay.Events, db.ReplayEvent{Time: spent.Milliseconds(),
+						Kind: replayStatuses[proceed]})
+				}, "the replay holds events made while the game was paused"},
+				{func(a *db.Attempt) {
+					a.Replay.Events = append([]db.ReplayEvent{{Time: 100, Kind: replayHint},
+						{Time: 105, Kind: replayHint}}, a.Replay.Events...)
+				}, "the replay hints were made too fast"},
+				{func(a *db.Attempt) { a.Replay.Events = make([]db.ReplayEvent, getMaxReplayEvents(timeLimit)+1) },
+					"the replay holds more than 11001 events"},
+				{func(a *db.Attempt) { a.Seed = 2019 }, "go through the maze walls"},
+				{func(a *db.Attempt) { a.Moves++ }, "invalid moves found"},
+				{func(a *db.Attempt) { a.Replay.Events = a.Replay.Events[:len(moves)-1] }, "invalid moves found"},
+				{func(a *db.Attempt) { a.Outcome = db.Failed }, "the target was located"},
+				{func(a *db.Attempt) { a.Duration = time.Second }, "invalid duration found"},
+				{func(a *db.Attempt) { a.Scores += 500 }, "invalid scores found"},
+			} {
+				a := *attempt
+				a.Replay = &db.Replay{Length: 40, Width: 20, Events: append([]db.ReplayEvent{}, replay.Events...)}
+
+				item.update(&a)
+
+				err := VerifyAttempt(&a)
+
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, item.msg)
+			}
+		})
+
+		Convey("a move through the maze walls should be rejected", func() {
+			neighbors := game.Config.getCellNeighbors(game.Config.StartPosition)
+
+			for direction, cell := range map[string]int{"LEFT": neighbors.Left, "RIGHT": neighbors.Right,
+				"UP": neighbors.Top, "DOWN": neighbors.Bottom} {
+				if cell != 0 && !game.Grid.IsPassable(game.Config.StartPosition, cell) {
+					replay.Events[0].Direction = direction
+					break
+				}
+			}
+
+			err := VerifyAttempt(attempt)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "go through the maze walls")
+		})
+
+		Convey("a failed attempt should be rejected if the time had not run out", func() {
+			replay.Events = replay.Events[:1]
+			replay.Events = append(replay.Events, db.ReplayEvent{Time: 800, Kind: replayStatuses[failed]})
+
+			attempt.Outcome, attempt.Moves, attempt.Duration = db.Failed, 1, 800*time.Millisecond
+			attempt.Scores = getLevelScores(timeLimit, 800*time.Millisecond)
+
+			err := VerifyAttempt(attempt)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "the level failed before the time ran out")
+
+			replay.Events[1].Time = timeLimit.Milliseconds()
+			attempt.Duration, attempt.Scores = timeLimit, 0
+
+			So(VerifyAttempt(attempt), ShouldBeNil)
+		})
+	})
+}
+
+// TestVerifyMaxLevelAttempt tests that a long attempt of the maxLevel using many hints is verified.
+func TestVerifyMaxLevelAttempt(t *testing.T) {
+	Convey("TestVerifyMaxLevelAttempt: Given an attempt of the maxLevel with many moves and hints", t, func() {
+		terminalSize := Dimensions{Length: 60, Width: 60}
+
+		game, err := NewGame(maxLevel, 2018, terminalSize)
+		So(err, ShouldBeNil)
+
+		solution, err := solver.AStar(game.Grid, game.Config.StartPosition, game.Config.FinalPosition)
+		So(err, ShouldBeNil)
+
+		var (
+			path   = getDirections(game.Config, solution.Path)
+			back   = getDirections(game.Config, []int{solution.Path[1], solution.Path[0]})
+			moves  []string
+			replay = &db.Replay{Length: terminalSize.Length, Width: terminalSize.Width}
+			at     int64
+		)
+
+		// the player moves back and forth at the start a while before walking to the target.
+		for i := 0; i < 12000; i++ {
+			moves = append(moves, path[0], back[0])
+		}
+
+		moves = append(moves, path...)
+
+		for i, direction := range moves {
+			at += minMoveInterval.Milliseconds()
+
+			// a hint is shown before the first hundred moves.
+			if i < 100 {
+				replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayHint})
+				at += minMoveInterval.Milliseconds()
+			}
+
+			replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayMove, Direction: direction})
+		}
+
+		replay.Events = append(replay.Events, db.ReplayEvent{Time: at, Kind: replayStatuses[succeeded]})
+
+		var (
+			timeLimit = game.getTimeLimit()
+			spent     = time.Duration(at) * time.Millisecond
+		)
+
+		So(timeLimit, ShouldBeGreaterThanOrEqualTo, time.Duration(generateMazeArea(maxLevel))*time.Second)
+		So(len(replay.Events), ShouldBeGreaterThan, 20000)
+
+		Convey("the attempt should be verified", func() {
+			So(VerifyAttempt(&db.Attempt{Level: maxLevel, Seed: 2018, Outcome: db.Succeeded, Duration: spent,
+				Moves: len(moves), Scores: getLevelScores(timeLimit, spent) - 100*hintPenalty, Replay: replay}), ShouldBeNil)
+		})
+	})
+}
@@ -1,8 +1,332 @@
 package main
 
-import "github.com/dmigwi/tapoo/maze"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dmigwi/tapoo/maze"
+	"github.com/dmigwi/tapoo/maze/api"
+	"github.com/dmigwi/tapoo/maze/db"
+)
+
+var (
+	// seed defines the value used to reproduce a specific maze on a terminal of the same size.
+	seed = flag.String("seed", "", "seed used to reproduce a specific maze, the terminal must be of the same size")
+
+	// tapooID defines the player whose high scores are saved, the player is prompted for it if empty.
+	tapooID = flag.String("id", "", "Tapoo ID used to save the high scores and resume from the last cleared level")
+
+	// hotseat defines if the hide and seek game for two players sharing the terminal should be played.
+	hotseat = flag.Bool("hotseat", false, "play hide and seek with two players taking turns on the same terminal")
+
+	// rounds defines the number of hide and seek rounds to be played in the hotseat mode.
+	rounds = flag.Int("rounds", 2, "number of hide and seek rounds, the players swap roles after every round")
+
+	// record defines the replay file the game is recorded to, the game is not recorded if empty.
+	record = flag.String("record", "", "replay file the game is recorded to, play it back with tapoo replay")
+
+	// ghost defines whose previous attempt of every level is raced against, no ghost is raced if empty.
+	ghost = flag.String("ghost", "", "race against the ghost of your best attempt (best) or the level leader's (leader)")
+)
+
+// usage describes the commands supported in addition to the flags.
+const usage = `Usage:
+  tapoo [flags]                  play the maze runner game, quitting a paused game saves
+                                 it to TAPOO_SAVE_PATH (tapoo_save.json by default)
+  tapoo [flags] resume           continue the saved game where it was left off
+  tapoo [flags] serve [address]  host a hide and seek game for two remote players
+  tapoo join host:port           join a hide and seek game hosted by tapoo serve
+  tapoo replay file              play back a game recorded with the -record flag, use
+                                 Space to pause, the arrow keys to seek and 1, 2 or 4
+                                 to change the speed
+  tapoo stats id                 print the win rate, the average time and the personal
+                                 bests of every level played by the player
+  tapoo db migrate up|down|status [-steps n] [-dry-run]
+                                 apply, revert or list the database schema migrations
+  tapoo api [address]            serve the users and leaderboards REST API, the requests
+                                 are not authenticated so only serve it to trusted players
+  tapoo season create name start end | list
+                                 create a leaderboard season running from the start date
+                                 until the end date (YYYY-MM-DD, UTC) or list the seasons
+
+Flags:
+`
+
+// defaultAddress defines the address the hide and seek server listens on if none is provided.
+const defaultAddress = ":4040"
+
+// defaultAPIAddress defines the address the REST API listens on if none is provided.
+const defaultAPIAddress = ":8080"
+
+// seasonDateFormat defines the format of the season start and end dates.
+const seasonDateFormat = "2006-01-02"
 
 // Main defines where the program executions starts
 func main() {
-	maze.Start()
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "serve":
+		serve(flag.Arg(1))
+
+	case "join":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := maze.Join(flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+
+	case "resume":
+		if err := maze.Resume(*record, *ghost); err != nil {
+			log.Fatal(err)
+		}
+
+	case "replay":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := maze.PlayReplay(flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+
+	case "stats":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := maze.PrintStats(os.Stdout, flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+
+	case "db":
+		if flag.NArg() < 3 || flag.Arg(1) != "migrate" {
+			flag.Usage()
+			os.Exit(2)
+		}
+
+		if err := migrate(flag.Arg(2), flag.Args()[3:]); err != nil {
+			log.Fatal(err)
+		}
+
+	case "api":
+		if err := serveAPI(flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+
+	case "season":
+		if err := season(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+
+	case "":
+		var err error
+
+		if *hotseat {
+			err = maze.StartHotseat(*seed, *rounds)
+		} else {
+			err = maze.Start(*seed, *tapooID, *record, *ghost)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// serve hosts the hide and seek game on the address provided until the game is over.
+func serve(addr string) {
+	if addr == "" {
+		addr = defaultAddress
+	}
+
+	mazeSeed := maze.NewSeed()
+	if len(*seed) > 0 {
+		mazeSeed = maze.ParseSeed(*seed)
+	}
+
+	server, err := maze.NewServer(addr, *rounds, mazeSeed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("hosting hide and seek on %s with seed %d, waiting for two players", server.Addr(), mazeSeed)
+
+	if err = server.Serve(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("hide and seek game over")
+}
+
+// serveAPI serves the REST API on the address provided using the database configured by
+// the TAPOO_DB_* environment variables. The level attempts submitted are verified against
+// their replays.
+func serveAPI(addr string) error {
+	if addr == "" {
+		addr = defaultAPIAddress
+	}
+
+	c, err := db.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	store, err := db.Open(context.Background(), c)
+	if err != nil {
+		return err
+	}
+
+	defer store.Close()
+
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      api.NewServer(store, maze.VerifyAttempt),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  time.Minute,
+	}
+
+	log.Printf("serving the tapoo REST API on %s using the %s store", addr, c.Driver)
+
+	return server.ListenAndServe()
+}
+
+// season creates or lists the leaderboard seasons of the database configured by the
+// TAPOO_DB_* environment variables.
+func season(args []string) error {
+	if len(args) == 0 || (args[0] == "create" && len(args) != 4) || (args[0] != "create" && args[0] != "list") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := db.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	store, err := db.Open(context.Background(), c)
+	if err != nil {
+		return err
+	}
+
+	defer store.Close()
+
+	if args[0] == "create" {
+		d := &db.Season{Name: args[1]}
+
+		if d.StartsAt, err = time.Parse(seasonDateFormat, args[2]); err != nil {
+			return fmt.Errorf("invalid season start date: %v", err)
+		}
+
+		if d.EndsAt, err = time.Parse(seasonDateFormat, args[3]); err != nil {
+			return fmt.Errorf("invalid season end date: %v", err)
+		}
+
+		return store.CreateSeason(context.Background(), d)
+	}
+
+	seasons, err := store.GetSeasons(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, item := range seasons {
+		fmt.Printf("%-40s %s  %s\n", item.Name, item.StartsAt.UTC().Format(seasonDateFormat),
+			item.EndsAt.UTC().Format(seasonDateFormat))
+	}
+
+	return nil
+}
+
+// migrate applies, reverts or lists the schema migrations of the database configured by
+// the TAPOO_DB_* environment variables.
+func migrate(action string, args []string) error {
+	if action != "up" && action != "down" && action != "status" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		flags  = flag.NewFlagSet("migrate", flag.ExitOnError)
+		steps  = flags.Int("steps", 0, "number of migrations to apply or revert, all if zero when applying and one when reverting")
+		dryRun = flags.Bool("dry-run", false, "print the statements without running them")
+	)
+
+	flags.Parse(args)
+
+	c, err := db.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	c.SkipMigrations = true
+
+	store, err := db.Open(context.Background(), c)
+	if err != nil {
+		return err
+	}
+
+	defer store.Close()
+
+	m, ok := store.(db.Migrator)
+	if !ok {
+		return fmt.Errorf("the %s store has no schema to migrate", c.Driver)
+	}
+
+	var stmts []string
+
+	switch action {
+	case "up":
+		stmts, err = m.MigrateUp(context.Background(), *steps, *dryRun)
+
+	case "down":
+		if *steps == 0 {
+			*steps = 1
+		}
+
+		stmts, err = m.MigrateDown(context.Background(), *steps, *dryRun)
+
+	case "status":
+		var status []db.Migration
+		if status, err = m.MigrationStatus(context.Background()); err != nil {
+			return err
+		}
+
+		for _, item := range status {
+			state := "pending"
+			if item.Applied {
+				state = "applied " + item.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			fmt.Printf("%4d  %-40s %s\n", item.Version, item.Name, state)
+		}
+
+		return nil
+	}
+
+	for _, stmt := range stmts {
+		fmt.Println(stmt)
+	}
+
+	return err
 }